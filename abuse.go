@@ -0,0 +1,52 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AbuseContact returns the abuse contact for a domain or an IP address.
+// IP addresses and CIDR prefixes are looked up as IP networks, anything else
+// as a domain; the abuse entity is searched including nested entities.
+func (c *Client) AbuseContact(domainOrIP string) (*Contact, error) {
+	query := strings.TrimSpace(domainOrIP)
+
+	var contact *Contact
+	var found bool
+	if _, err := parseIPQuery(query); err == nil {
+		network, err := c.IPNetwork(query)
+		if err != nil {
+			return nil, err
+		}
+		contact, found = network.AbuseContact()
+	} else {
+		domain, err := c.Domain(query)
+		if err != nil {
+			return nil, err
+		}
+		contact, found = domain.AbuseContact()
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no abuse contact found for %s", query)
+	}
+	return contact, nil
+}
@@ -0,0 +1,83 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAbuseContactDomain(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"roles": ["registrar"],
+				"entities": [
+					{
+						"objectClassName": "entity",
+						"roles": ["abuse"],
+						"vcardArray": ["vcard", [
+							["version", {}, "text", "4.0"],
+							["tel", {"type": "voice"}, "uri", "tel:+1.5555551234"],
+							["email", {}, "text", "abuse@registrar.example"]
+						]]
+					}
+				]
+			}
+		]
+	}`))
+
+	contact, err := client.AbuseContact("example.com")
+	if err != nil {
+		t.Fatalf("AbuseContact failed: %v", err)
+	}
+	if contact.Emails[0] != "abuse@registrar.example" || contact.Phones[0].Number != "+1.5555551234" {
+		t.Errorf("Unexpected abuse contact: %+v", contact)
+	}
+
+	client = newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	if _, err := client.AbuseContact("example.com"); err == nil {
+		t.Error("Expected error when the response has no abuse contact")
+	}
+}
+
+func TestAbuseContactIP(t *testing.T) {
+	mockServer := httptest.NewServer(serveJSON(`{
+		"objectClassName": "ip network",
+		"handle": "NET-192-0-2-0-1",
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"roles": ["abuse"],
+				"vcardArray": ["vcard", [["email", {}, "text", "abuse@rir.example"]]]
+			}
+		]
+	}`))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"192.0.0.0/8"},
+					{mockServer.URL + "/"},
+				},
+			},
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetIPv4BootstrapURL(bootstrapServer.URL)
+
+	contact, err := client.AbuseContact("192.0.2.1")
+	if err != nil {
+		t.Fatalf("AbuseContact failed: %v", err)
+	}
+	if contact.Emails[0] != "abuse@rir.example" {
+		t.Errorf("Unexpected abuse contact: %+v", contact)
+	}
+}
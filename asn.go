@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASN performs RDAP query for the given autonomous system number and returns raw JSON
+func (c *Client) ASN(asn uint32) (result []byte, err error) {
+	// Get the appropriate RDAP server for this ASN
+	server, err := c.getASNServer(asn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for AS%d: %w", asn, err)
+	}
+
+	return c.doQuery(objectURL(server, "autnum", strconv.FormatUint(uint64(asn), 10)))
+}
+
+// getASNServer determines the appropriate RDAP server for an ASN
+func (c *Client) getASNServer(asn uint32) (string, error) {
+	if c.provider != nil {
+		return c.providerServer(ObjectAutnum, strconv.FormatUint(uint64(asn), 10))
+	}
+
+	bootstrap, err := c.loadBootstrap(c.asnBootstrapURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+	}
+
+	server, err := c.findServerForASN(asn, bootstrap)
+	if err != nil {
+		return "", fmt.Errorf("no RDAP server found for AS%d: %w", asn, err)
+	}
+
+	return server, nil
+}
+
+// findServerForASN finds the RDAP server whose registered range contains the given ASN
+func (c *Client) findServerForASN(asn uint32, bootstrap *RDAPBootstrap) (string, error) {
+	return bootstrap.serverForASN(asn)
+}
+
+// serverForASN finds the RDAP server whose registered range contains the given ASN
+func (b *RDAPBootstrap) serverForASN(asn uint32) (string, error) {
+	if servers := b.lookupIndex().lookupASN(asn); len(servers) > 0 {
+		// Use the first server in the list
+		return servers[0], nil
+	}
+
+	return "", fmt.Errorf("no server found for ASN: %d", asn)
+}
+
+// ParseASN parses an autonomous system number written as plain decimal
+// ("64512", "4200000000"), with an "AS" prefix ("AS64512") or in the RFC 5396
+// asdot notation used for 32-bit ASNs ("AS1.10" for 65546)
+func ParseASN(s string) (uint32, error) {
+	value := strings.TrimSpace(s)
+	if len(value) >= 2 && strings.EqualFold(value[:2], "AS") {
+		value = value[2:]
+	}
+
+	high, low, dotted := strings.Cut(value, ".")
+	if !dotted {
+		asn, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ASN: %s", s)
+		}
+		return uint32(asn), nil
+	}
+
+	h, err := strconv.ParseUint(high, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ASN: %s", s)
+	}
+	l, err := strconv.ParseUint(low, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ASN: %s", s)
+	}
+	return uint32(h)<<16 | uint32(l), nil
+}
+
+// parseASNRange parses a bootstrap ASN entry such as "2043" or "64512-65534"
+func parseASNRange(entry string) (low, high uint32, err error) {
+	lowStr, highStr, found := strings.Cut(strings.TrimSpace(entry), "-")
+	if !found {
+		highStr = lowStr
+	}
+
+	l, err := strconv.ParseUint(lowStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid ASN range: %s", entry)
+	}
+	h, err := strconv.ParseUint(highStr, 10, 32)
+	if err != nil || h < l {
+		return 0, 0, fmt.Errorf("invalid ASN range: %s", entry)
+	}
+
+	return uint32(l), uint32(h), nil
+}
@@ -0,0 +1,193 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseASNRange(t *testing.T) {
+	tests := []struct {
+		entry string
+		low   uint32
+		high  uint32
+	}{
+		{"2043", 2043, 2043},
+		{"64512-65534", 64512, 65534},
+		{"36864-37887", 36864, 37887},
+	}
+
+	for _, test := range tests {
+		low, high, err := parseASNRange(test.entry)
+		if err != nil {
+			t.Errorf("parseASNRange(%s) returned error: %v", test.entry, err)
+			continue
+		}
+		if low != test.low || high != test.high {
+			t.Errorf("parseASNRange(%s) = %d-%d, expected %d-%d", test.entry, low, high, test.low, test.high)
+		}
+	}
+
+	for _, entry := range []string{"", "abc", "10-5", "1-x"} {
+		if _, _, err := parseASNRange(entry); err == nil {
+			t.Errorf("Expected error for invalid range %q", entry)
+		}
+	}
+}
+
+func TestFindServerForASN(t *testing.T) {
+	client := NewClient()
+
+	bootstrap := &RDAPBootstrap{
+		Services: [][][]string{
+			{
+				{"2043", "36864-37887"},
+				{"https://rdap.afrinic.net/rdap/"},
+			},
+			{
+				{"1-1876"},
+				{"https://rdap.arin.net/registry"},
+			},
+		},
+	}
+
+	server, err := client.findServerForASN(37000, bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for AS37000: %v", err)
+	}
+	if server != "https://rdap.afrinic.net/rdap/" {
+		t.Errorf("Expected AFRINIC server, got %s", server)
+	}
+
+	server, err = client.findServerForASN(701, bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for AS701: %v", err)
+	}
+	if server != "https://rdap.arin.net/registry/" {
+		t.Errorf("Expected ARIN server, got %s", server)
+	}
+
+	if _, err := client.findServerForASN(2042, bootstrap); err == nil {
+		t.Error("Expected error for unregistered ASN")
+	}
+}
+
+func TestASNWithMockServer(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/autnum/64512" {
+			t.Errorf("Expected path /autnum/64512, got %s", r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"objectClassName": "autnum",
+			"startAutnum":     64512,
+			"endAutnum":       64512,
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"64512-65534"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetASNBootstrapURL(bootstrapServer.URL)
+
+	result, err := client.ASN(64512)
+	if err != nil {
+		t.Fatalf("ASN query failed: %v", err)
+	}
+	if !strings.Contains(string(result), "autnum") {
+		t.Errorf("Expected autnum object, got: %s", result)
+	}
+
+	if _, err := client.ASN(1); err == nil {
+		t.Error("Expected error for ASN outside bootstrap")
+	}
+}
+
+func TestParseASN(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint32
+	}{
+		{"64512", 64512},
+		{"AS64512", 64512},
+		{"as4200000000", 4200000000},
+		{"AS1.10", 65546},
+		{"3.0", 196608},
+		{" 4294967295 ", 4294967295},
+	}
+	for _, test := range tests {
+		got, err := ParseASN(test.input)
+		if err != nil {
+			t.Errorf("ParseASN(%q) returned error: %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseASN(%q) = %d, expected %d", test.input, got, test.want)
+		}
+	}
+
+	for _, input := range []string{"", "AS", "4294967296", "1.65536", "1.x", "-1"} {
+		if _, err := ParseASN(input); err == nil {
+			t.Errorf("Expected error for invalid ASN %q", input)
+		}
+	}
+}
+
+func TestASNBootstrapRegistry(t *testing.T) {
+	path, err := filepath.Abs("testdata/asn.json")
+	if err != nil {
+		t.Fatalf("Failed to resolve fixture path: %v", err)
+	}
+	client := NewClient().SetASNBootstrapURL("file://" + path)
+
+	tests := []struct {
+		asn  uint32
+		want string
+	}{
+		{1, "https://rdap.arin.net/registry/"},
+		{1876, "https://rdap.arin.net/registry/"},
+		{1877, "https://rdap.db.ripe.net/"},
+		{2043, "https://rdap.db.ripe.net/"},
+		{2044, "https://rdap.arin.net/registry/"},
+		{37000, "https://rdap.afrinic.net/rdap/"},
+		{131072, "https://rdap.apnic.net/"},
+		{141625, "https://rdap.apnic.net/"},
+		{196608, "https://rdap.db.ripe.net/"},
+		{262144, "https://rdap.lacnic.net/rdap/"},
+		{328704, "https://rdap.afrinic.net/rdap/"},
+		{402431, "https://rdap.arin.net/registry/"},
+	}
+	for _, test := range tests {
+		got, err := client.getASNServer(test.asn)
+		if err != nil {
+			t.Errorf("getASNServer(%d) returned error: %v", test.asn, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("getASNServer(%d) = %s, expected %s", test.asn, got, test.want)
+		}
+	}
+
+	// Unallocated, reserved and private use numbers have no RDAP service
+	for _, asn := range []uint32{0, 2137, 141626, 64512, 4200000000, 4294967295} {
+		if server, err := client.getASNServer(asn); err == nil {
+			t.Errorf("Expected no server for AS%d, got %s", asn, server)
+		}
+	}
+}
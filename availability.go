@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// IsRegistered reports whether the given domain is registered. An RDAP 404
+// from the registry means the domain is available; any other failure is
+// returned as an error. When additional RDAP base URLs or URL templates are
+// given, the domain is also queried at each of them and is only reported available when every
+// server answers 404, for higher confidence.
+func (c *Client) IsRegistered(domain string, servers ...string) (bool, error) {
+	// Normalize domain
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return false, fmt.Errorf("%w: domain cannot be empty", ErrInvalidDomain)
+	}
+	domain = c.registrableDomain(domain)
+
+	// The domain's own servers are queried like any other lookup, with
+	// failover, the fallback chain and the response cache
+	_, err := c.RDAPResponse(domain)
+	switch {
+	case err == nil:
+		return true, nil
+	case !errors.Is(err, ErrNotFound):
+		return false, err
+	}
+
+	var lastErr error
+	for _, server := range servers {
+		if !isURLTemplate(server) {
+			server = normalizeServer(server)
+		}
+		_, err := c.queryDomain(context.Background(), domain, requestOptions{server: server})
+		switch {
+		case err == nil:
+			return true, nil
+		case errors.Is(err, ErrNotFound):
+			continue
+		default:
+			lastErr = err
+		}
+	}
+
+	if lastErr != nil {
+		return false, lastErr
+	}
+	return false, nil
+}
@@ -0,0 +1,117 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsRegistered(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domain/example.com":
+			w.Header().Set("Content-Type", "application/rdap+json")
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+		case "/domain/broken.com":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	registered, err := client.IsRegistered("example.com")
+	if err != nil || !registered {
+		t.Errorf("Expected example.com to be registered, got %v, %v", registered, err)
+	}
+
+	registered, err = client.IsRegistered("available-name.com")
+	if err != nil || registered {
+		t.Errorf("Expected 404 to be reported as available, got %v, %v", registered, err)
+	}
+
+	_, err = client.IsRegistered("broken.com")
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Expected error for server failure, got %v", err)
+	}
+
+	if _, err := client.IsRegistered(""); err == nil {
+		t.Error("Expected error for empty domain")
+	}
+}
+
+func TestIsRegisteredMultipleServers(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	secondOpinion := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	defer secondOpinion.Close()
+
+	registered, err := client.IsRegistered("example.com", secondOpinion.URL)
+	if err != nil || !registered {
+		t.Errorf("Expected domain found by another server to be registered, got %v, %v", registered, err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	if _, err := client.IsRegistered("example.com", failing.URL); err == nil {
+		t.Error("Expected error when a server could not confirm availability")
+	}
+}
+
+func TestIsRegisteredEmptyAnswer(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"rdapConformance": ["rdap_level_0"], "errorCode": 404, "title": "Not Found"}`))
+	})
+
+	registered, err := client.IsRegistered("available-name.com")
+	if err != nil || registered {
+		t.Errorf("Expected an empty answer to be reported as available, got %v, %v", registered, err)
+	}
+
+	// The answer is cached as not found, not as a registered domain
+	if _, err := client.RDAP("available-name.com"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Expected ErrDomainNotFound, got %v", err)
+	}
+}
+
+func TestIsRegisteredFailover(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	defer secondary.Close()
+
+	registered, err := newFailoverClient(primary, secondary).IsRegistered("example.com")
+	if err != nil || !registered {
+		t.Errorf("Expected the secondary server to be queried, got %v, %v", registered, err)
+	}
+}
+
+func TestIsRegisteredServerTemplate(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	secondOpinion := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lookup/example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer secondOpinion.Close()
+
+	registered, err := client.IsRegistered("example.com", secondOpinion.URL+"/lookup/{domain}")
+	if err != nil || !registered {
+		t.Errorf("Expected the URL template to be queried, got %v, %v", registered, err)
+	}
+}
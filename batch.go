@@ -0,0 +1,258 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults of BatchOptions
+const (
+	defaultBatchConcurrency = 16
+	defaultBatchPerRegistry = 2
+	defaultBatchRetries     = 3
+)
+
+// BatchOptions configures a Batch run
+type BatchOptions struct {
+	// Concurrency is the number of lookups in flight across all registries,
+	// 16 by default
+	Concurrency int
+	// PerRegistry is the number of lookups in flight per registry, 2 by default
+	PerRegistry int
+	// RateLimiter paces the lookups sent to each registry host. When nil,
+	// the limiter set with SetRateLimiter is relied on, or without one a
+	// HostRateLimiter applying the DefaultRateLimits.
+	RateLimiter RateLimiter
+	// MaxRetries is how often a lookup is requeued after the registry asked
+	// to slow down with a 429, or a 503 with Retry-After, 3 by default. A
+	// negative value disables the requeue.
+	MaxRetries int
+}
+
+// BatchResult is the outcome of one lookup of a Batch run
+type BatchResult struct {
+	// Domain is the domain as given to Batch
+	Domain string
+	// Registry is the host of the RDAP server the domain was routed to,
+	// empty when it could not be routed
+	Registry string
+	Response *Response
+	Err      error
+}
+
+// Batch looks up a large set of domains, grouped by the registry serving
+// them and interleaved so a slow or throttling registry doesn't hold up the
+// others. Each registry is paced by the rate limiter and gets at most
+// PerRegistry lookups in flight. When a registry answers with a Retry-After
+// wait, its lookups are held back for that long and the throttled domain is
+// requeued. One result is sent per distinct domain, in completion order,
+// and the channel is closed once all were sent; it must be drained. When
+// ctx is done, the lookups not started yet are sent with the error of ctx.
+func (c *Client) Batch(ctx context.Context, domains []string, opts BatchOptions) <-chan BatchResult {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBatchConcurrency
+	}
+	if opts.PerRegistry <= 0 {
+		opts.PerRegistry = defaultBatchPerRegistry
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultBatchRetries
+	}
+	if opts.RateLimiter == nil && c.rateLimiter == nil {
+		opts.RateLimiter = NewHostRateLimiter(0, 0)
+	}
+
+	run := &batchRun{
+		client:  c,
+		opts:    opts,
+		slots:   make(chan struct{}, opts.Concurrency),
+		results: make(chan BatchResult, opts.Concurrency),
+	}
+	go run.start(ctx, domains)
+	return run.results
+}
+
+// batchRun is the state of a Batch run
+type batchRun struct {
+	client  *Client
+	opts    BatchOptions
+	slots   chan struct{}
+	results chan BatchResult
+	mu      sync.Mutex
+}
+
+// batchRegistry is the queue of the lookups routed to one registry
+type batchRegistry struct {
+	host      string
+	queue     []*batchItem
+	notBefore time.Time
+}
+
+// batchItem is a domain waiting in a registry queue
+type batchItem struct {
+	domain  string
+	retries int
+}
+
+// start groups the domains by registry and runs the workers of every registry
+func (r *batchRun) start(ctx context.Context, domains []string) {
+	defer close(r.results)
+
+	registries := make(map[string]*batchRegistry)
+	var order []*batchRegistry
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		name := r.client.registrableDomain(normalizeDomain(domain))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		servers, err := r.client.getRDAPServers(ctx, name)
+		if err != nil {
+			r.results <- BatchResult{Domain: domain, Err: err}
+			continue
+		}
+		host := registryHost(servers[0])
+		registry, ok := registries[host]
+		if !ok {
+			registry = &batchRegistry{host: host}
+			registries[host] = registry
+			order = append(order, registry)
+		}
+		registry.queue = append(registry.queue, &batchItem{domain: domain})
+	}
+
+	var wg sync.WaitGroup
+	for _, registry := range order {
+		for range min(r.opts.PerRegistry, len(registry.queue)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.work(ctx, registry)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// work runs the lookups of a registry queue until it is empty
+func (r *batchRun) work(ctx context.Context, registry *batchRegistry) {
+	for {
+		item, wait, ok := r.next(registry)
+		if !ok {
+			return
+		}
+		if wait > 0 && !sleepContext(ctx, wait) || ctx.Err() != nil {
+			r.send(item, registry, nil, ctx.Err())
+			continue
+		}
+		resp, err := r.lookup(ctx, registry, item)
+		if retryAfter, ok := throttled(err); ok && r.opts.MaxRetries > 0 && item.retries < r.opts.MaxRetries {
+			r.requeue(registry, item, retryAfter)
+			continue
+		}
+		r.send(item, registry, resp, err)
+	}
+}
+
+// lookup runs one lookup within the rate and concurrency limits of the run
+func (r *batchRun) lookup(ctx context.Context, registry *batchRegistry, item *batchItem) (*Response, error) {
+	if r.opts.RateLimiter != nil {
+		host := registry.host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		if err := r.opts.RateLimiter.Wait(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.slots }()
+
+	return r.client.RDAPResponse(item.domain, WithContext(ctx))
+}
+
+// next takes the next lookup of a registry queue and returns how long the
+// registry asked to wait before it is sent
+func (r *batchRun) next(registry *batchRegistry) (*batchItem, time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(registry.queue) == 0 {
+		return nil, 0, false
+	}
+	item := registry.queue[0]
+	registry.queue[0] = nil
+	registry.queue = registry.queue[1:]
+	return item, time.Until(registry.notBefore), true
+}
+
+// requeue puts a throttled lookup back at the end of the registry queue and
+// holds the registry back for the wait it asked for
+func (r *batchRun) requeue(registry *batchRegistry, item *batchItem, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item.retries++
+	registry.queue = append(registry.queue, item)
+	if notBefore := time.Now().Add(wait); notBefore.After(registry.notBefore) {
+		registry.notBefore = notBefore
+	}
+}
+
+// send reports the outcome of a lookup
+func (r *batchRun) send(item *batchItem, registry *batchRegistry, resp *Response, err error) {
+	r.results <- BatchResult{Domain: item.domain, Registry: registry.host, Response: resp, Err: err}
+}
+
+// throttled returns the wait asked by a registry that answered with a 429,
+// or a 503 with Retry-After
+func throttled(err error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+	switch {
+	case statusErr.StatusCode == http.StatusTooManyRequests && statusErr.RetryAfter <= 0:
+		return defaultRetryAfter, true
+	case statusErr.StatusCode == http.StatusTooManyRequests, statusErr.StatusCode == http.StatusServiceUnavailable && statusErr.RetryAfter > 0:
+		return statusErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// registryHost returns the host, with its port, of an RDAP server URL
+func registryHost(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return server
+}
@@ -0,0 +1,138 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBatchClient returns a client routing .com to one server and .net to another
+func newBatchClient(com, netServer *httptest.Server) *Client {
+	bootstrap := fmt.Sprintf(`{"services": [[["com"], ["%s/"]], [["net"], ["%s/"]]]}`, com.URL, netServer.URL)
+	return NewClient().SetBootstrapData([]byte(bootstrap)).SetDisableCache(true).SetAllowInsecureServers(true)
+}
+
+// concurrencyHandler answers domain queries and records the most queries it served at once
+func concurrencyHandler(inFlight, peak *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var comInFlight, comPeak, netInFlight, netPeak atomic.Int32
+	com := httptest.NewServer(concurrencyHandler(&comInFlight, &comPeak))
+	defer com.Close()
+	netServer := httptest.NewServer(concurrencyHandler(&netInFlight, &netPeak))
+	defer netServer.Close()
+
+	var domains []string
+	for i := 0; i < 10; i++ {
+		domains = append(domains, fmt.Sprintf("domain%d.com", i), fmt.Sprintf("domain%d.net", i))
+	}
+	domains = append(domains, "DOMAIN0.com", "invalid")
+
+	client := newBatchClient(com, netServer)
+	results := make(map[string]BatchResult)
+	for result := range client.Batch(context.Background(), domains, BatchOptions{PerRegistry: 2}) {
+		if _, ok := results[result.Domain]; ok {
+			t.Errorf("Duplicate result for %s", result.Domain)
+		}
+		results[result.Domain] = result
+	}
+
+	if len(results) != 21 {
+		t.Fatalf("Expected one result per distinct domain, got %d", len(results))
+	}
+	for domain, result := range results {
+		switch {
+		case domain == "invalid":
+			if result.Err == nil {
+				t.Error("Expected the invalid domain to fail")
+			}
+		case result.Err != nil:
+			t.Errorf("Lookup of %s failed: %v", domain, result.Err)
+		case result.Registry != registryHost(com.URL) && result.Registry != registryHost(netServer.URL):
+			t.Errorf("Unexpected registry %s for %s", result.Registry, domain)
+		}
+	}
+	if comPeak.Load() > 2 || netPeak.Load() > 2 {
+		t.Errorf("Expected at most 2 lookups per registry, got %d and %d", comPeak.Load(), netPeak.Load())
+	}
+}
+
+func TestBatchRetryAfter(t *testing.T) {
+	var mu sync.Mutex
+	var throttledAt, retriedAt time.Time
+	com := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if throttledAt.IsZero() {
+			throttledAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if retriedAt.IsZero() {
+			retriedAt = time.Now()
+		}
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}))
+	defer com.Close()
+	var netDone atomic.Int64
+	netServer := httptest.NewServer(serveJSON(`{"objectClassName": "domain"}`))
+	defer netServer.Close()
+
+	client := newBatchClient(com, netServer)
+	domains := []string{"a.com", "b.com", "a.net", "b.net", "c.net"}
+	start := time.Now()
+	for result := range client.Batch(context.Background(), domains, BatchOptions{PerRegistry: 1}) {
+		if result.Err != nil {
+			t.Errorf("Lookup of %s failed: %v", result.Domain, result.Err)
+		}
+		if result.Registry == registryHost(netServer.URL) {
+			netDone.Store(int64(time.Since(start)))
+		}
+	}
+
+	// The throttled registry is held back while the other one goes on
+	if wait := retriedAt.Sub(throttledAt); wait < 900*time.Millisecond {
+		t.Errorf("Expected the registry to be held back for its Retry-After, retried after %v", wait)
+	}
+	if done := time.Duration(netDone.Load()); done > 500*time.Millisecond {
+		t.Errorf("Expected the other registry not to wait, done after %v", done)
+	}
+}
+
+func TestBatchCancelled(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain"}`))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var results int
+	for result := range newBatchClient(server, server).Batch(ctx, []string{"a.com", "b.com", "a.net"}, BatchOptions{}) {
+		results++
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected %s to be cancelled, got %v", result.Domain, result.Err)
+		}
+	}
+	if results != 3 {
+		t.Errorf("Expected a result per domain, got %d", results)
+	}
+}
@@ -0,0 +1,419 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// embeddedDNSBootstrap is the domain bootstrap used when the bootstrap data
+// cannot be fetched. The file in the tree only lists common TLDs; go generate
+// replaces it with the current IANA dns.json.
+//
+//go:generate go run gen_bootstrap.go
+//go:embed embedded/dns.json
+var embeddedDNSBootstrap []byte
+
+// loadEmbeddedBootstrap parses the embedded dns.json once
+var loadEmbeddedBootstrap = sync.OnceValues(func() (*RDAPBootstrap, error) {
+	var bootstrap RDAPBootstrap
+	if err := json.Unmarshal(embeddedDNSBootstrap, &bootstrap); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded bootstrap JSON: %w", err)
+	}
+	bootstrap.index = newBootstrapIndex(bootstrap.Services)
+	return &bootstrap, nil
+})
+
+// RDAPBootstrap represents the IANA RDAP bootstrap file structure
+type RDAPBootstrap struct {
+	Description string       `json:"description"`
+	Publication string       `json:"publication"`
+	Services    [][][]string `json:"services"`
+	Version     string       `json:"version"`
+
+	index *bootstrapIndex
+}
+
+// PublicationTime returns the publication date of the bootstrap file
+func (b *RDAPBootstrap) PublicationTime() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, b.Publication)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid bootstrap publication date %q: %w", b.Publication, err)
+	}
+	return t, nil
+}
+
+// ListTLDs returns the sorted TLDs listed in a domain bootstrap file
+func (b *RDAPBootstrap) ListTLDs() []string {
+	tlds := slices.Collect(maps.Keys(b.lookupIndex().entries))
+	slices.Sort(tlds)
+	return tlds
+}
+
+// ServersForTLD returns every RDAP base URL registered for a TLD, in bootstrap order
+func (b *RDAPBootstrap) ServersForTLD(tld string) []string {
+	return slices.Clone(b.lookupIndex().lookup(strings.Trim(tld, ".")))
+}
+
+// Bootstrap holds the IANA RDAP bootstrap registries for every object type
+type Bootstrap struct {
+	DNS        *RDAPBootstrap
+	IPv4       *RDAPBootstrap
+	IPv6       *RDAPBootstrap
+	ASN        *RDAPBootstrap
+	ObjectTags *RDAPBootstrap
+}
+
+// ServerForTLD returns the RDAP base URL registered for a TLD
+func (b *Bootstrap) ServerForTLD(tld string) (string, error) {
+	return b.DNS.serverForTLD(strings.ToLower(strings.Trim(tld, ".")))
+}
+
+// ServerForPrefix returns the RDAP base URL registered for an IP address or prefix
+func (b *Bootstrap) ServerForPrefix(prefix netip.Prefix) (string, error) {
+	if prefix.Addr().Is6() {
+		return b.IPv6.serverForIP(prefix)
+	}
+	return b.IPv4.serverForIP(prefix)
+}
+
+// ServerForASN returns the RDAP base URL registered for an autonomous system number
+func (b *Bootstrap) ServerForASN(asn uint32) (string, error) {
+	return b.ASN.serverForASN(asn)
+}
+
+// ServerForTag returns the RDAP base URL registered for an RFC 8521 object tag
+func (b *Bootstrap) ServerForTag(tag string) (string, error) {
+	return b.ObjectTags.serverForTag(tag)
+}
+
+// bootstrapEntry is a cached bootstrap file with the validators needed to revalidate it
+type bootstrapEntry struct {
+	data         *RDAPBootstrap
+	fetchedAt    time.Time
+	etag         string
+	lastModified string
+}
+
+// getBootstrapData returns the IANA RDAP bootstrap data for domains
+func (c *Client) getBootstrapData() (*RDAPBootstrap, error) {
+	return c.getBootstrapDataContext(context.Background())
+}
+
+// getBootstrapDataContext is getBootstrapData giving up when ctx is done
+func (c *Client) getBootstrapDataContext(ctx context.Context) (*RDAPBootstrap, error) {
+	c.bootstrapMu.Lock()
+	url, mirrored := c.bootstrapURL, len(c.bootstrapMirrors) > 1
+	c.bootstrapMu.Unlock()
+
+	if mirrored {
+		return c.loadBootstrapMirrors(ctx)
+	}
+	return c.loadBootstrapContext(ctx, url)
+}
+
+// Bootstrap returns the parsed domain bootstrap data the client routes
+// queries with, loading it if needed
+func (c *Client) Bootstrap() (*RDAPBootstrap, error) {
+	bootstrap, err := c.getDNSBootstrap(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+	}
+	return bootstrap, nil
+}
+
+// getDNSBootstrap returns the bootstrap data for domains, falling back to the
+// embedded snapshot when it cannot be loaded and the fallback is enabled.
+// A cancelled lookup is not answered from the snapshot.
+func (c *Client) getDNSBootstrap(ctx context.Context) (*RDAPBootstrap, error) {
+	bootstrap, err := c.getBootstrapDataContext(ctx)
+	if err == nil || !c.embeddedFallback && !c.offline || ctx.Err() != nil {
+		return bootstrap, err
+	}
+
+	fallback, fallbackErr := loadEmbeddedBootstrap()
+	if fallbackErr != nil {
+		return nil, err
+	}
+	return fallback, nil
+}
+
+// loadBootstrap returns the bootstrap file at the given URL from the cache.
+// An expired copy is still served while it is revalidated in the background.
+func (c *Client) loadBootstrap(url string) (*RDAPBootstrap, error) {
+	return c.loadBootstrapContext(context.Background(), url)
+}
+
+// loadBootstrapContext is loadBootstrap giving up when ctx is done. A lookup
+// waiting on a fetch started by another one stops waiting when its own
+// context is done, and fetches again when the other one was cancelled.
+func (c *Client) loadBootstrapContext(ctx context.Context, url string) (*RDAPBootstrap, error) {
+	if c.disableCache {
+		return c.fetchBootstrap(ctx, url)
+	}
+
+	c.bootstrapMu.Lock()
+	entry, ok := c.bootstrapCache[url]
+	if ok {
+		if c.bootstrapExpired(entry) && !c.revalidating[url] {
+			// Serve the stale copy and revalidate it in the background
+			c.revalidating[url] = true
+			go c.revalidateBootstrap(url)
+		}
+		c.bootstrapMu.Unlock()
+		return entry.data, nil
+	}
+
+	// Concurrent first lookups wait for a single fetch
+	if call, ok := c.loading[url]; ok {
+		c.bootstrapMu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to fetch bootstrap data: %w", ctx.Err())
+		}
+		if isContextError(call.err) && ctx.Err() == nil {
+			return c.loadBootstrapContext(ctx, url)
+		}
+		return call.data, call.err
+	}
+	call := &bootstrapCall{done: make(chan struct{})}
+	c.loading[url] = call
+	c.bootstrapMu.Unlock()
+
+	call.data, call.err = c.refreshBootstrapContext(ctx, url)
+
+	c.bootstrapMu.Lock()
+	delete(c.loading, url)
+	c.bootstrapMu.Unlock()
+	close(call.done)
+
+	return call.data, call.err
+}
+
+// bootstrapExpired reports whether the cached bootstrap entry outlived the cache TTL
+func (c *Client) bootstrapExpired(entry *bootstrapEntry) bool {
+	return c.bootstrapCacheTTL > 0 && time.Since(entry.fetchedAt) >= c.bootstrapCacheTTL
+}
+
+// bootstrapCall is an in-flight bootstrap fetch shared by concurrent lookups
+type bootstrapCall struct {
+	done chan struct{}
+	data *RDAPBootstrap
+	err  error
+}
+
+// revalidateBootstrap refreshes an expired bootstrap file in the background.
+// A failure keeps the stale copy until the next lookup retries.
+func (c *Client) revalidateBootstrap(url string) {
+	c.refreshBootstrap(url)
+
+	c.bootstrapMu.Lock()
+	delete(c.revalidating, url)
+	c.bootstrapMu.Unlock()
+}
+
+// LoadBootstrap loads the five IANA RDAP bootstrap registries, served from
+// the cache while they are fresh. The domain registry is loaded from the
+// current bootstrap source, trying its mirrors in turn.
+func (c *Client) LoadBootstrap() (*Bootstrap, error) {
+	dns, err := c.getBootstrapDataContext(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load domain bootstrap: %w", err)
+	}
+
+	bootstrap := &Bootstrap{DNS: dns}
+	registries := []struct {
+		url  string
+		data **RDAPBootstrap
+	}{
+		{c.ipv4BootstrapURL, &bootstrap.IPv4},
+		{c.ipv6BootstrapURL, &bootstrap.IPv6},
+		{c.asnBootstrapURL, &bootstrap.ASN},
+		{c.tagsBootstrapURL, &bootstrap.ObjectTags},
+	}
+
+	for _, registry := range registries {
+		data, err := c.loadBootstrap(registry.url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bootstrap %s: %w", registry.url, err)
+		}
+		*registry.data = data
+	}
+
+	return bootstrap, nil
+}
+
+// ClearCache clears the bootstrap data cache, and the response cache when it
+// can be purged
+func (c *Client) ClearCache() {
+	c.bootstrapMu.Lock()
+	c.bootstrapCache = make(map[string]*bootstrapEntry)
+	c.bootstrapMu.Unlock()
+
+	if cache, ok := c.cache.(interface{ Purge() }); ok {
+		cache.Purge()
+	}
+}
+
+// fetchBootstrap fetches and parses the bootstrap file at the given URL
+func (c *Client) fetchBootstrap(ctx context.Context, url string) (*RDAPBootstrap, error) {
+	entry, err := c.fetchBootstrapEntry(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return entry.data, nil
+}
+
+// fetchBootstrapEntry fetches and parses the bootstrap file at the given URL.
+// When a cached entry is given, the request is made conditional on its ETag
+// and Last-Modified validators and a 304 response keeps the cached data.
+func (c *Client) fetchBootstrapEntry(ctx context.Context, url string, cached *bootstrapEntry) (*bootstrapEntry, error) {
+	var body []byte
+	var err error
+	entry := &bootstrapEntry{fetchedAt: time.Now()}
+
+	// Check if the bootstrap content was supplied by the caller
+	if url == inlineBootstrapURL {
+		c.bootstrapMu.Lock()
+		body, err = c.bootstrapData, c.bootstrapDataErr
+		c.bootstrapMu.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bootstrap data: %w", err)
+		}
+	} else if strings.HasPrefix(url, "file://") {
+		filepath := strings.TrimPrefix(url, "file://")
+		body, err = readFileContext(ctx, filepath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bootstrap file %s: %w", filepath, err)
+		}
+	} else if c.offline {
+		return nil, fmt.Errorf("failed to fetch bootstrap data from %s: %w", url, ErrOffline)
+	} else if err := c.checkPolicy(url); err != nil {
+		return nil, fmt.Errorf("failed to fetch bootstrap data: %w", err)
+	} else {
+		// Fetch from URL
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		if cached != nil {
+			if cached.etag != "" {
+				req.Header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+		resp, err := c.doFollowingRedirects(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch bootstrap data: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			entry.data = cached.data
+			entry.etag = cached.etag
+			entry.lastModified = cached.lastModified
+			return entry, nil
+		}
+
+		if !isSuccess(resp.StatusCode) {
+			return nil, fmt.Errorf("bootstrap request failed with status: %d", resp.StatusCode)
+		}
+
+		body, err = readBodyContext(ctx, resp.Body, c.maxResponseSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bootstrap response: %w", err)
+		}
+		entry.etag = resp.Header.Get("ETag")
+		entry.lastModified = resp.Header.Get("Last-Modified")
+	}
+
+	var bootstrap RDAPBootstrap
+	if err := json.Unmarshal(body, &bootstrap); err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap JSON: %w", err)
+	}
+	bootstrap.index = newBootstrapIndex(bootstrap.Services)
+	entry.data = &bootstrap
+
+	return entry, nil
+}
+
+// isContextError reports whether err comes from a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// readFileContext reads the named file, giving up when ctx is done
+func readFileContext(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(contextReader{ctx: ctx, r: f})
+}
+
+// contextReader is a reader failing with the context error once ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// findServerForTLD finds the appropriate RDAP server for a given TLD
+func (c *Client) findServerForTLD(tld string, bootstrap *RDAPBootstrap) (string, error) {
+	return bootstrap.serverForTLD(tld)
+}
+
+// serverForTLD finds the RDAP server registered for the given TLD
+func (b *RDAPBootstrap) serverForTLD(tld string) (string, error) {
+	if servers := b.lookupIndex().lookup(tld); len(servers) > 0 {
+		// Use the first server in the list
+		return servers[0], nil
+	}
+
+	return "", fmt.Errorf("%w %s: %w", ErrNoServerForTLD, tld, ErrNoRDAPService)
+}
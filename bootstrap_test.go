@@ -0,0 +1,518 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// bootstrapFixtures are minimal bootstrap files for each IANA registry
+var bootstrapFixtures = map[string]string{
+	"/dns.json":         `{"version": "1.0", "services": [[["com"], ["https://rdap.verisign.com/com/v1/"]]]}`,
+	"/ipv4.json":        `{"version": "1.0", "services": [[["41.0.0.0/8"], ["https://rdap.afrinic.net/rdap/"]]]}`,
+	"/ipv6.json":        `{"version": "1.0", "services": [[["2001:200::/23"], ["https://rdap.apnic.net/"]]]}`,
+	"/asn.json":         `{"version": "1.0", "services": [[["36864-37887"], ["https://rdap.afrinic.net/rdap/"]]]}`,
+	"/object-tags.json": `{"version": "1.0", "services": [[["contact@arin.net"], ["ARIN"], ["https://rdap.arin.net/registry/"]]]}`,
+}
+
+// newBootstrapServer serves the bootstrap fixtures and counts the requests it receives
+func newBootstrapServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		body, ok := bootstrapFixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &hits
+}
+
+// newBootstrapClient returns a client reading every registry from the given server
+func newBootstrapClient(server *httptest.Server) *Client {
+	return NewClient().
+		SetBootstrapURL(server.URL + "/dns.json").
+		SetIPv4BootstrapURL(server.URL + "/ipv4.json").
+		SetIPv6BootstrapURL(server.URL + "/ipv6.json").
+		SetASNBootstrapURL(server.URL + "/asn.json").
+		SetObjectTagsBootstrapURL(server.URL + "/object-tags.json")
+}
+
+func TestLoadBootstrap(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	bootstrap, err := client.LoadBootstrap()
+	if err != nil {
+		t.Fatalf("LoadBootstrap failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		lookup func() (string, error)
+		want   string
+	}{
+		{"tld", func() (string, error) { return bootstrap.ServerForTLD(".COM") }, "https://rdap.verisign.com/com/v1/"},
+		{"ipv4", func() (string, error) { return bootstrap.ServerForPrefix(netip.MustParsePrefix("41.1.2.3/32")) }, "https://rdap.afrinic.net/rdap/"},
+		{"ipv6", func() (string, error) { return bootstrap.ServerForPrefix(netip.MustParsePrefix("2001:200::/32")) }, "https://rdap.apnic.net/"},
+		{"asn", func() (string, error) { return bootstrap.ServerForASN(37000) }, "https://rdap.afrinic.net/rdap/"},
+		{"tag", func() (string, error) { return bootstrap.ServerForTag("arin") }, "https://rdap.arin.net/registry/"},
+	}
+	for _, test := range tests {
+		got, err := test.lookup()
+		if err != nil {
+			t.Errorf("%s lookup failed: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s lookup = %s, expected %s", test.name, got, test.want)
+		}
+	}
+
+	if _, err := bootstrap.ServerForASN(1); err == nil {
+		t.Error("Expected error for unregistered ASN")
+	}
+}
+
+func TestLoadBootstrapMirrors(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	client := newBootstrapClient(server).
+		SetBootstrapURLs([]string{"file:///nonexistent/dns.json", server.URL + "/dns.json"})
+
+	bootstrap, err := client.LoadBootstrap()
+	if err != nil {
+		t.Fatalf("Expected failover to the mirror, got: %v", err)
+	}
+	if got, err := bootstrap.ServerForTLD("com"); err != nil || got != "https://rdap.verisign.com/com/v1/" {
+		t.Errorf("Unexpected server %q, %v", got, err)
+	}
+
+	// The bootstrap source may change while the registries load
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			client.SetBootstrapURL(server.URL + "/dns.json")
+		}
+	}()
+	for range 50 {
+		if _, err := client.LoadBootstrap(); err != nil {
+			t.Fatalf("LoadBootstrap failed: %v", err)
+		}
+	}
+	<-done
+}
+
+func TestLoadBootstrapMissingRegistry(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	client := newBootstrapClient(server).SetASNBootstrapURL(server.URL + "/missing.json")
+
+	_, err := client.LoadBootstrap()
+	if err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("Expected error naming the missing registry, got: %v", err)
+	}
+}
+
+func TestBootstrapCache(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.getTLDServer("com"); err != nil {
+			t.Fatalf("getTLDServer failed: %v", err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected bootstrap to be fetched once, got %d fetches", got)
+	}
+
+	client.ClearCache()
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Fatalf("getTLDServer failed: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected bootstrap to be fetched again after ClearCache, got %d fetches", got)
+	}
+}
+
+func TestBootstrapCacheDisabled(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server).SetDisableCache(true)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.getTLDServer("com"); err != nil {
+			t.Fatalf("getTLDServer failed: %v", err)
+		}
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected bootstrap to be fetched on every lookup, got %d fetches", got)
+	}
+}
+
+func TestBootstrapConcurrentFirstQueries(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.getTLDServer("com"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("getTLDServer failed: %v", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected concurrent first queries to share one fetch, got %d fetches", got)
+	}
+}
+
+func TestBootstrapConditionalRefresh(t *testing.T) {
+	const lastModified = "Tue, 01 Jul 2025 00:00:00 GMT"
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == lastModified {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(bootstrapFixtures["/dns.json"]))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetBootstrapURL(server.URL)
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Fatalf("getTLDServer failed: %v", err)
+	}
+
+	// Expire the cached entry and revalidate it
+	client.bootstrapCache[server.URL].fetchedAt = time.Now().Add(-2 * defaultBootstrapCacheTTL)
+
+	bootstrap, err := client.refreshBootstrap(server.URL)
+	if err != nil {
+		t.Fatalf("refreshBootstrap failed: %v", err)
+	}
+	if got, _ := bootstrap.serverForTLD("com"); got != "https://rdap.verisign.com/com/v1/" {
+		t.Errorf("Expected cached server to be kept, got %s", got)
+	}
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("Expected 1 full fetch and 1 conditional fetch, got %d and %d", full.Load(), notModified.Load())
+	}
+	if time.Since(client.bootstrapCache[server.URL].fetchedAt) > time.Minute {
+		t.Error("Expected revalidated entry to be fresh again")
+	}
+}
+
+func TestEmbeddedBootstrap(t *testing.T) {
+	bootstrap, err := loadEmbeddedBootstrap()
+	if err != nil {
+		t.Fatalf("Failed to parse embedded bootstrap: %v", err)
+	}
+	if bootstrap.Description == "" || len(bootstrap.Services) == 0 {
+		t.Errorf("Unexpected embedded bootstrap: %+v", bootstrap)
+	}
+}
+
+func TestEmbeddedBootstrapFallback(t *testing.T) {
+	client := NewClient().SetBootstrapFile("/nonexistent/dns.json")
+
+	server, err := client.getTLDServer("com")
+	if err != nil {
+		t.Fatalf("Expected embedded fallback, got error: %v", err)
+	}
+	if server != "https://rdap.verisign.com/com/v1/" {
+		t.Errorf("Unexpected fallback server %s", server)
+	}
+
+	client.SetEmbeddedFallback(false)
+	if _, err := client.getTLDServer("com"); err == nil || !strings.Contains(err.Error(), "failed to read bootstrap file") {
+		t.Errorf("Expected bootstrap error with fallback disabled, got: %v", err)
+	}
+}
+
+func TestBootstrapStaleWhileRevalidate(t *testing.T) {
+	release := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			// Hold the revalidation until the stale copy has been served
+			<-release
+			w.Write([]byte(`{"version": "1.0", "services": [[["com"], ["https://rdap.example.net/"]]]}`))
+			return
+		}
+		w.Write([]byte(bootstrapFixtures["/dns.json"]))
+	}))
+	defer server.Close()
+
+	client := NewClient().SetBootstrapURL(server.URL)
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Fatalf("getTLDServer failed: %v", err)
+	}
+
+	client.bootstrapMu.Lock()
+	client.bootstrapCache[server.URL].fetchedAt = time.Now().Add(-2 * defaultBootstrapCacheTTL)
+	client.bootstrapMu.Unlock()
+
+	for i := 0; i < 3; i++ {
+		got, err := client.getTLDServer("com")
+		if err != nil {
+			t.Fatalf("getTLDServer failed on stale data: %v", err)
+		}
+		if got != "https://rdap.verisign.com/com/v1/" {
+			t.Errorf("Expected stale server while revalidating, got %s", got)
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, _ := client.getTLDServer("com"); got == "https://rdap.example.net/" {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, _ := client.getTLDServer("com"); got != "https://rdap.example.net/" {
+		t.Errorf("Expected revalidated server, got %s", got)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected a single background revalidation, got %d requests", got)
+	}
+}
+
+func TestBootstrapCacheTTL(t *testing.T) {
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		fetch int32
+	}{
+		{"short TTL revalidates", time.Nanosecond, 2},
+		{"zero TTL never expires", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, hits := newBootstrapServer(t)
+			client := newBootstrapClient(server).SetBootstrapCacheTTL(tt.ttl)
+			if _, err := client.getTLDServer("com"); err != nil {
+				t.Fatalf("getTLDServer failed: %v", err)
+			}
+
+			client.bootstrapMu.Lock()
+			client.bootstrapCache[server.URL+"/dns.json"].fetchedAt = time.Now().Add(-365 * 24 * time.Hour)
+			client.bootstrapMu.Unlock()
+			if _, err := client.getTLDServer("com"); err != nil {
+				t.Fatalf("getTLDServer failed: %v", err)
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for hits.Load() < tt.fetch && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if got := hits.Load(); got != tt.fetch {
+				t.Errorf("Expected %d bootstrap fetches, got %d", tt.fetch, got)
+			}
+		})
+	}
+}
+
+func TestBootstrapInspection(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{
+		"publication": "2025-06-03T19:00:01Z",
+		"version": "1.0",
+		"services": [
+			[["org", "COM"], ["https://rdap.example.com", "http://rdap.example.com/"]],
+			[["net"], ["https://rdap.example.net/"]]
+		]
+	}`))
+	defer server.Close()
+
+	bootstrap, err := NewClient().SetBootstrapURL(server.URL).Bootstrap()
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	if got := bootstrap.ListTLDs(); !slices.Equal(got, []string{"com", "net", "org"}) {
+		t.Errorf("Unexpected TLDs: %v", got)
+	}
+
+	servers := bootstrap.ServersForTLD(".com")
+	if !slices.Equal(servers, []string{"https://rdap.example.com/", "http://rdap.example.com/"}) {
+		t.Errorf("Unexpected servers: %v", servers)
+	}
+	if servers := bootstrap.ServersForTLD("dev"); servers != nil {
+		t.Errorf("Expected no servers for unlisted TLD, got %v", servers)
+	}
+
+	published, err := bootstrap.PublicationTime()
+	if err != nil {
+		t.Fatalf("PublicationTime failed: %v", err)
+	}
+	if !published.Equal(time.Date(2025, 6, 3, 19, 0, 1, 0, time.UTC)) {
+		t.Errorf("Unexpected publication time: %v", published)
+	}
+}
+
+func TestSetBootstrapData(t *testing.T) {
+	client := NewClient().SetBootstrapData([]byte(bootstrapFixtures["/dns.json"]))
+
+	server, err := client.getTLDServer("com")
+	if err != nil {
+		t.Fatalf("getTLDServer failed with inline bootstrap: %v", err)
+	}
+	if server != "https://rdap.verisign.com/com/v1/" {
+		t.Errorf("Unexpected server %s", server)
+	}
+
+	// Setting new content replaces the cached copy
+	client.SetBootstrapData([]byte(`{"services": [[["com"], ["https://rdap.example.com/"]]]}`))
+	if server, _ := client.getTLDServer("com"); server != "https://rdap.example.com/" {
+		t.Errorf("Expected new inline bootstrap to be used, got %s", server)
+	}
+}
+
+func TestSetBootstrapReader(t *testing.T) {
+	client := NewClient().SetEmbeddedFallback(false).SetBootstrapReader(strings.NewReader(bootstrapFixtures["/dns.json"]))
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Fatalf("getTLDServer failed with bootstrap reader: %v", err)
+	}
+
+	client.SetBootstrapReader(iotest.ErrReader(errors.New("read failed")))
+	_, err := client.getTLDServer("com")
+	if err == nil || !strings.Contains(err.Error(), "read failed") {
+		t.Errorf("Expected read error, got %v", err)
+	}
+
+	client.SetBootstrapData([]byte("invalid json"))
+	if _, err := client.getTLDServer("com"); err == nil || !strings.Contains(err.Error(), "failed to parse bootstrap JSON") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
+
+// newSlowBootstrapServer serves the bootstrap fixtures once the first request,
+// held until the client gives up on it, was cancelled
+func newSlowBootstrapServer(t *testing.T) (*httptest.Server, chan struct{}) {
+	t.Helper()
+
+	var first atomic.Bool
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if first.CompareAndSwap(false, true) {
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		w.Write([]byte(bootstrapFixtures[r.URL.Path]))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, cancelled
+}
+
+func TestBootstrapFetchCancelled(t *testing.T) {
+	server, cancelled := newSlowBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	start := time.Now()
+	_, err := client.RDAP("example.com", WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the lookup to stop at its timeout, took %v", elapsed)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the bootstrap request to be cancelled")
+	}
+
+	// The next lookup fetches the bootstrap again
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Errorf("getTLDServer failed after a cancelled fetch: %v", err)
+	}
+}
+
+func TestBootstrapSharedFetchCancelled(t *testing.T) {
+	server, _ := newSlowBootstrapServer(t)
+	client := newBootstrapClient(server)
+	url := server.URL + "/dns.json"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := client.loadBootstrapContext(ctx, url)
+		leader <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// A waiter giving up leaves the shared fetch running
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+	if _, err := client.loadBootstrapContext(waitCtx, url); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the waiter to stop at its deadline, got %v", err)
+	}
+
+	// A waiter outliving the cancelled fetch fetches again
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := client.loadBootstrapContext(context.Background(), url)
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled fetch to fail, got %v", err)
+	}
+	if err := <-waiter; err != nil {
+		t.Errorf("Expected the waiter to fetch again, got %v", err)
+	}
+}
+
+func TestReadFileContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dns.json")
+	if err := os.WriteFile(path, []byte(bootstrapFixtures["/dns.json"]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, err := readFileContext(context.Background(), path); err != nil || string(body) != bootstrapFixtures["/dns.json"] {
+		t.Errorf("readFileContext returned %q, %v", body, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewClient().SetBootstrapURL("file://" + path)
+	if _, err := client.getDNSBootstrap(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"sync"
+	"time"
+)
+
+// retryBudgetWindow is the number of seconds over which the retry budget
+// weighs retries against requests
+const retryBudgetWindow = 10
+
+// SetRetryBudget limits retries, that is failover to the next server of a
+// TLD or to the fallback aggregator and rate limited queries sent again, to
+// ratio of the requests sent over the last 10 seconds, plus minRetries
+// always allowed so a quiet client can still fail over. A retry over the
+// budget is not sent and the lookup fails with the error of its last
+// attempt, so a widespread outage doesn't multiply the traffic of a batch
+// run. A zero ratio and minRetries, the default, disables the budget.
+func (c *Client) SetRetryBudget(ratio float64, minRetries int) *Client {
+	if ratio <= 0 && minRetries <= 0 {
+		c.retryBudget = nil
+		return c
+	}
+	c.retryBudget = &retryBudget{ratio: max(ratio, 0), minRetries: max(minRetries, 0)}
+	return c
+}
+
+// retryBudget counts the requests and retries of the last seconds in one
+// bucket per second
+type retryBudget struct {
+	mu         sync.Mutex
+	ratio      float64
+	minRetries int
+	buckets    [retryBudgetWindow]budgetBucket
+}
+
+// budgetBucket holds the counts of one second
+type budgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// bucket returns the bucket of the given time, reset if it held an older second
+func (b *retryBudget) bucket(now time.Time) *budgetBucket {
+	second := now.Unix()
+	bucket := &b.buckets[second%retryBudgetWindow]
+	if bucket.second != second {
+		*bucket = budgetBucket{second: second}
+	}
+	return bucket
+}
+
+// request records a request sent
+func (b *retryBudget) request(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(now).requests++
+}
+
+// retry reports whether a retry fits in the budget, recording it if so
+func (b *retryBudget) retry(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		if now.Unix()-bucket.second < retryBudgetWindow {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	if float64(retries) >= float64(b.minRetries)+b.ratio*float64(requests) {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
+
+// recordRequest records a request sent against the retry budget
+func (c *Client) recordRequest() {
+	if c.retryBudget != nil {
+		c.retryBudget.request(time.Now())
+	}
+}
+
+// allowRetry reports whether the retry budget allows one more retry
+func (c *Client) allowRetry() bool {
+	return c.retryBudget == nil || c.retryBudget.retry(time.Now())
+}
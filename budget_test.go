@@ -0,0 +1,90 @@
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	budget := &retryBudget{ratio: 0.2, minRetries: 1}
+	now := time.Unix(1000, 0)
+
+	// The minimum is allowed without any request
+	if !budget.retry(now) {
+		t.Error("Expected the minimum retry to be allowed")
+	}
+	if budget.retry(now) {
+		t.Error("Expected a retry over the minimum to be denied")
+	}
+
+	// Every 5 requests allow one more retry
+	for i := 0; i < 5; i++ {
+		budget.request(now)
+	}
+	if !budget.retry(now) {
+		t.Error("Expected the ratio to allow a retry")
+	}
+	if budget.retry(now) {
+		t.Error("Expected the budget to be spent")
+	}
+
+	// Retries age out of the window
+	if !budget.retry(now.Add(retryBudgetWindow * time.Second)) {
+		t.Error("Expected old retries to be forgotten")
+	}
+}
+
+func TestRetryBudgetFailover(t *testing.T) {
+	var hits atomic.Int32
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	primary := httptest.NewServer(failing)
+	defer primary.Close()
+	secondary := httptest.NewServer(failing)
+	defer secondary.Close()
+
+	client := newFailoverClient(primary, secondary).SetRetryBudget(0, 1)
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected the lookup to fail")
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected the budget to allow one failover, got %d requests", got)
+	}
+
+	// Once the budget is spent, lookups stop at their first server
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected the lookup to fail")
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("Expected no failover over the budget, got %d requests", got)
+	}
+
+	// Disabling the budget restores failover
+	client.SetRetryBudget(0, 0)
+	client.RDAP("example.com")
+	if got := hits.Load(); got != 5 {
+		t.Errorf("Expected failover without a budget, got %d requests", got)
+	}
+}
+
+func TestRetryBudgetRateLimit(t *testing.T) {
+	var hits atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client.SetRateLimitRetry(time.Second).SetRetryBudget(0, 2)
+
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected the lookup to stay rate limited")
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("Expected 2 retries within the budget, got %d requests", got)
+	}
+}
@@ -0,0 +1,427 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultResponseCacheTTL is how long query responses are cached by default
+	defaultResponseCacheTTL = time.Hour
+	// defaultNegativeCacheTTL is how long not found responses are cached by default
+	defaultNegativeCacheTTL = 5 * time.Minute
+	// notFoundKeyPrefix prefixes the cache keys of not found responses
+	notFoundKeyPrefix = "notfound/"
+)
+
+// Cache stores RDAP query responses. Implementations must be safe for
+// concurrent use; a zero ttl means the entry does not expire.
+type Cache interface {
+	// Get returns the value stored under key, or false when there is none
+	Get(key string) ([]byte, bool)
+	// Set stores the value under key for the given duration
+	Set(key string, val []byte, ttl time.Duration)
+	// Delete removes the value stored under key
+	Delete(key string)
+}
+
+// SetCache sets the store domain query responses are cached in, such as a
+// Redis or memcached adapter, in place of the default LRUCache. A nil cache
+// disables response caching
+func (c *Client) SetCache(cache Cache) *Client {
+	c.cache = cache
+	return c
+}
+
+// SetCacheTTL sets how long query responses are kept in the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) *Client {
+	c.cacheTTL = ttl
+	return c
+}
+
+// CacheTTLPolicy returns the cache TTL of the responses of a TLD, given the
+// TTL configured for it with SetCacheTTL or SetCacheTTLForTLD
+type CacheTTLPolicy func(tld string, ttl time.Duration) time.Duration
+
+// SetCacheTTLForTLD sets how long the query responses of a TLD are kept in
+// the cache, overriding the TTL set with SetCacheTTL
+func (c *Client) SetCacheTTLForTLD(tld string, ttl time.Duration) *Client {
+	if c.tldCacheTTLs == nil {
+		c.tldCacheTTLs = make(map[string]time.Duration)
+	}
+	c.tldCacheTTLs[strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))] = ttl
+	return c
+}
+
+// SetCacheTTLPolicy sets a policy deciding the cache TTL of responses
+// dynamically, such as from the rate limits of a registry. A nil policy
+// keeps the configured TTLs
+func (c *Client) SetCacheTTLPolicy(policy CacheTTLPolicy) *Client {
+	c.cacheTTLPolicy = policy
+	return c
+}
+
+// responseTTL returns the cache TTL of the response stored under the given key
+func (c *Client) responseTTL(key string) time.Duration {
+	// Cache keys end with the queried domain
+	tld := getTLD(key)
+
+	ttl := c.cacheTTL
+	if override, ok := c.tldCacheTTLs[tld]; ok {
+		ttl = override
+	}
+	if c.cacheTTLPolicy != nil {
+		ttl = c.cacheTTLPolicy(tld, ttl)
+	}
+	return ttl
+}
+
+// SetNegativeCacheTTL sets how long 404 not found responses are cached, so
+// availability checks don't query registries again for known-free names. A
+// zero or negative TTL disables negative caching
+func (c *Client) SetNegativeCacheTTL(ttl time.Duration) *Client {
+	c.negativeCacheTTL = ttl
+	return c
+}
+
+// SetServeStaleOnError makes failed queries return the cached copy of a
+// response instead of an error when it expired no longer than maxStale ago.
+// Such responses have Stale set and carry their Age. Entries are kept in the
+// cache for the extra window. A zero duration disables stale serving
+func (c *Client) SetServeStaleOnError(maxStale time.Duration) *Client {
+	c.maxStale = maxStale
+	return c
+}
+
+// SetCacheNamespace sets a namespace prefixed to every cache key, so several
+// tenants or client configurations can share one Redis or disk cache without
+// their entries colliding. An empty namespace uses the keys as is
+func (c *Client) SetCacheNamespace(namespace string) *Client {
+	c.cacheNamespace = namespace
+	return c
+}
+
+// responseCache returns the cache for query responses, scoped to the cache
+// namespace, or nil when responses are not cached
+func (c *Client) responseCache() Cache {
+	if c.disableCache || c.cacheBootstrapOnly || c.cache == nil {
+		return nil
+	}
+	if c.cacheNamespace != "" {
+		return namespacedCache{cache: c.cache, prefix: c.cacheNamespace + "/"}
+	}
+	return c.cache
+}
+
+// namespacedCache prefixes the keys of a shared cache with a namespace
+type namespacedCache struct {
+	cache  Cache
+	prefix string
+}
+
+// Get returns the value stored under the namespaced key
+func (n namespacedCache) Get(key string) ([]byte, bool) {
+	return n.cache.Get(n.prefix + key)
+}
+
+// Set stores the value under the namespaced key
+func (n namespacedCache) Set(key string, val []byte, ttl time.Duration) {
+	n.cache.Set(n.prefix+key, val, ttl)
+}
+
+// Delete removes the value stored under the namespaced key
+func (n namespacedCache) Delete(key string) {
+	n.cache.Delete(n.prefix + key)
+}
+
+// responseEntry is a query response as stored in the response cache
+type responseEntry struct {
+	Body         []byte    `json:"body"`
+	StatusCode   int       `json:"status"`
+	URL          string    `json:"url,omitempty"`
+	Source       string    `json:"source,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	CacheControl string    `json:"cacheControl,omitempty"`
+	Expires      time.Time `json:"expires,omitzero"`
+	Stored       time.Time `json:"stored"`
+}
+
+// fresh reports whether the entry can be served without revalidation
+func (e *responseEntry) fresh(now time.Time) bool {
+	return e.Expires.IsZero() || now.Before(e.Expires)
+}
+
+// response returns the cached response of the entry
+func (e *responseEntry) response() *Response {
+	return &Response{
+		Body:       e.Body,
+		StatusCode: e.StatusCode,
+		URL:        e.URL,
+		Source:     e.Source,
+		Cached:     true,
+		Age:        time.Since(e.Stored),
+	}
+}
+
+// validators returns the headers of a conditional request revalidating the entry
+func (e *responseEntry) validators() http.Header {
+	header := make(http.Header)
+	if e.ETag != "" {
+		header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		header.Set("If-Modified-Since", e.LastModified)
+	}
+	return header
+}
+
+// cachedQuery runs a query through the response cache. Fresh entries are
+// served as is; stale entries with validators are revalidated with a
+// conditional request, whose changed answer is passed to check and used
+// like the answer of the query. The query runs when there is no such answer,
+// and stale entries are served when it fails within the serve-stale window
+func (c *Client) cachedQuery(ctx context.Context, key string, options requestOptions, query func() (*Response, error), check func(*Response) error) (*Response, error) {
+	cache := c.responseCache()
+	if cache == nil {
+		return query()
+	}
+
+	// Offline, any cached copy beats a miss unless the caller asked for fresh data
+	entryKey, entry, ok := c.cachedEntry(cache, key)
+	fresh := ok && entry.fresh(time.Now())
+	if ok && (fresh && options.accepts(entry) || c.offline && !options.bypassesCache()) {
+		resp := entry.response()
+		resp.Stale = !fresh
+		c.recordHit(resp)
+		c.onCacheHit(CacheHitInfo{Context: ctx, Key: key, Response: resp})
+		return resp, resp.statusError()
+	}
+
+	var resp *Response
+	var err error
+	if ok {
+		if !fresh {
+			c.stats.stale.Add(1)
+		}
+		if entry.URL != "" && (entry.ETag != "" || entry.LastModified != "") {
+			answer, answerErr := c.doRequestWithHeader(ctx, "", entry.URL, entry.validators())
+			switch {
+			case answer != nil && answer.StatusCode == http.StatusNotModified:
+				resp, err := c.revalidated(cache, entryKey, entry, answer)
+				c.onCacheHit(CacheHitInfo{Context: ctx, Key: key, Response: resp, Revalidated: true})
+				return resp, err
+			case answer != nil && (answerErr == nil || errors.Is(answerErr, ErrNotFound)):
+				// A changed answer is checked like the answer of the query
+				// rather than fetched again; failures are left to the query,
+				// which fails over like for a miss
+				answer.Source = entry.Source
+				if answerErr == nil && check != nil {
+					answerErr = check(answer)
+				}
+				resp, err = answer, answerErr
+			}
+		}
+	}
+	if resp == nil {
+		resp, err = query()
+	}
+	c.stats.misses.Add(1)
+
+	if ok && !options.bypassesCache() && isQueryFailure(err) {
+		if stale, ok := c.staleResponse(entry); ok {
+			c.stats.staleServed.Add(1)
+			c.onCacheHit(CacheHitInfo{Context: ctx, Key: key, Response: stale})
+			return stale, stale.statusError()
+		}
+	}
+	if ok && (err == nil || errors.Is(err, ErrNotFound)) {
+		// Only an answer replaces the cached entry, a failed refresh keeps it
+		cache.Delete(entryKey)
+	}
+	c.storeResponse(key, resp, err)
+	return resp, err
+}
+
+// revalidated refreshes a stale entry confirmed by a 304 Not Modified and returns its response
+func (c *Client) revalidated(cache Cache, key string, entry *responseEntry, notModified *Response) (*Response, error) {
+	// A 304 without caching headers keeps the policy of the stored response
+	header := notModified.Header.Clone()
+	if header.Get("Cache-Control") == "" && header.Get("Expires") == "" {
+		header.Set("Cache-Control", entry.CacheControl)
+	}
+	ttl := c.responseTTL(key)
+	entry.Expires, _ = c.responseExpiry(header, ttl)
+	entry.Stored = time.Now()
+	c.setEntry(cache, key, entry, ttl)
+
+	resp := entry.response()
+	c.stats.revalidations.Add(1)
+	c.recordHit(resp)
+	return resp, resp.statusError()
+}
+
+// staleResponse returns the response of a stale entry when it expired no
+// longer than the serve-stale window ago
+func (c *Client) staleResponse(entry *responseEntry) (*Response, bool) {
+	if c.maxStale <= 0 || time.Since(entry.Expires) > c.maxStale {
+		return nil, false
+	}
+
+	resp := entry.response()
+	resp.Stale = true
+	return resp, true
+}
+
+// cachedEntry returns the cached entry for the given key and the key it was
+// found under, which is the not found key when the object is negatively cached
+func (c *Client) cachedEntry(cache Cache, key string) (string, *responseEntry, bool) {
+	keys := []string{key}
+	if c.negativeCacheTTL > 0 {
+		keys = append(keys, notFoundKeyPrefix+key)
+	}
+
+	for _, k := range keys {
+		val, ok := cache.Get(k)
+		if !ok {
+			continue
+		}
+		var entry responseEntry
+		val, err := decodeCacheValue(val)
+		if err == nil {
+			err = json.Unmarshal(val, &entry)
+		}
+		if err != nil {
+			// Drop entries that were not stored by this client
+			cache.Delete(k)
+			continue
+		}
+		return k, &entry, true
+	}
+	return "", nil, false
+}
+
+// storeResponse caches the response of a query under the given key. Successful
+// responses are kept for the cache TTL, or as long as their Cache-Control or
+// Expires headers allow, and not found responses for the negative cache TTL;
+// other failures and no-store responses are not cached
+func (c *Client) storeResponse(key string, resp *Response, err error) {
+	cache := c.responseCache()
+	if cache == nil || resp == nil {
+		return
+	}
+
+	entry := &responseEntry{Body: resp.Body, StatusCode: resp.StatusCode, URL: resp.URL, Source: resp.Source, Stored: time.Now()}
+	switch {
+	case err == nil:
+		ttl := c.responseTTL(key)
+		expires, store := c.responseExpiry(resp.Header, ttl)
+		if !store {
+			cache.Delete(key)
+			return
+		}
+		entry.Expires = expires
+		entry.ETag = resp.Header.Get("ETag")
+		entry.LastModified = resp.Header.Get("Last-Modified")
+		entry.CacheControl = resp.Header.Get("Cache-Control")
+		c.setEntry(cache, key, entry, ttl)
+	case errors.Is(err, ErrNotFound) && c.negativeCacheTTL > 0:
+		entry.Expires = time.Now().Add(c.negativeCacheTTL)
+		c.setEntry(cache, notFoundKeyPrefix+key, entry, c.negativeCacheTTL)
+	}
+}
+
+// setEntry stores an entry, keeping it at least until it expires, plus the
+// serve-stale window, so stale entries can still be revalidated or served
+func (c *Client) setEntry(cache Cache, key string, entry *responseEntry, ttl time.Duration) {
+	val, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if ttl > 0 && !entry.Expires.IsZero() {
+		ttl = max(ttl, time.Until(entry.Expires)+max(c.maxStale, 0))
+	}
+	cache.Set(key, c.encodeCacheValue(val), ttl)
+}
+
+// responseExpiry returns when a response stops being fresh according to its
+// Cache-Control and Expires headers, or after ttl when it has neither, and
+// whether it may be stored at all
+func (c *Client) responseExpiry(header http.Header, ttl time.Duration) (time.Time, bool) {
+	now := time.Now()
+
+	if cacheControl := header.Get("Cache-Control"); cacheControl != "" {
+		var maxAge, sharedMaxAge = -1, -1
+		for _, directive := range strings.Split(cacheControl, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store":
+				return time.Time{}, false
+			case "no-cache":
+				maxAge = 0
+			case "max-age":
+				if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && maxAge != 0 {
+					maxAge = seconds
+				}
+			case "s-maxage":
+				if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+					sharedMaxAge = seconds
+				}
+			}
+		}
+		if sharedMaxAge >= 0 && maxAge != 0 {
+			maxAge = sharedMaxAge
+		}
+		if maxAge >= 0 {
+			age, _ := strconv.Atoi(header.Get("Age"))
+			return now.Add(time.Duration(maxAge-age) * time.Second), true
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		at, err := http.ParseTime(expires)
+		if err != nil {
+			// An invalid Expires date means already expired
+			return now, true
+		}
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			// Correct for clock skew between the server and this host
+			return now.Add(at.Sub(date)), true
+		}
+		return at, true
+	}
+
+	if ttl <= 0 {
+		return time.Time{}, true
+	}
+	return now.Add(ttl), true
+}
+
+// domainCacheKey returns the cache key of a domain query
+func domainCacheKey(domain string) string {
+	return "domain/" + domain
+}
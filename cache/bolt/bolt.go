@@ -0,0 +1,210 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// Package bolt implements the gordap response cache in a bbolt database file,
+// so command line tools and cron jobs keep cached RDAP responses across runs
+// without an external cache server
+package bolt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBucket is the bucket responses are stored in
+const DefaultBucket = "gordap"
+
+// openTimeout bounds the wait for the file lock held by another process
+const openTimeout = time.Second
+
+// expiryLen is the length of the expiry header stored before every value
+const expiryLen = 8
+
+// Cache is an rdap.Cache storing responses in a bbolt database
+type Cache struct {
+	db      *bolt.DB
+	bucket  []byte
+	owned   bool
+	onError func(error)
+}
+
+// Open opens or creates the cache database file at the given path
+func Open(path string) (*Cache, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+
+	cache, err := New(db, DefaultBucket)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	cache.owned = true
+	return cache, nil
+}
+
+// New returns a cache storing responses in the named bucket of an open
+// database, creating the bucket if needed
+func New(db *bolt.DB, bucket string) (*Cache, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache bucket: %w", err)
+	}
+
+	return &Cache{db: db, bucket: []byte(bucket)}, nil
+}
+
+// SetErrorHandler sets a function called with every database error. Failed
+// operations are otherwise treated as cache misses
+func (c *Cache) SetErrorHandler(handler func(error)) *Cache {
+	c.onError = handler
+	return c
+}
+
+// Get returns the value stored under key, or false when it is missing or expired
+func (c *Cache) Get(key string) ([]byte, bool) {
+	var val []byte
+	var found, expired bool
+	err := c.db.View(func(tx *bolt.Tx) error {
+		stored := tx.Bucket(c.bucket).Get([]byte(key))
+		if len(stored) < expiryLen {
+			return nil
+		}
+		if isExpired(stored, time.Now()) {
+			expired = true
+			return nil
+		}
+		// Values are only valid for the life of the transaction
+		val = append([]byte{}, stored[expiryLen:]...)
+		found = true
+		return nil
+	})
+	if err != nil {
+		c.report(err)
+		return nil, false
+	}
+
+	if expired {
+		c.Delete(key)
+	}
+	return val, found
+}
+
+// Set stores the value under key, expiring it after ttl. A zero ttl never expires
+func (c *Cache) Set(key string, val []byte, ttl time.Duration) {
+	stored := make([]byte, expiryLen+len(val))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(stored, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	copy(stored[expiryLen:], val)
+
+	c.report(c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Put([]byte(key), stored)
+	}))
+}
+
+// Delete removes the value stored under key
+func (c *Cache) Delete(key string) {
+	c.report(c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Delete([]byte(key))
+	}))
+}
+
+// Prune removes every expired entry, keeping the database file from growing
+// with responses that are never read again
+func (c *Cache) Prune() error {
+	now := time.Now()
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(c.bucket).Cursor()
+		for key, stored := cursor.First(); key != nil; key, stored = cursor.Next() {
+			if len(stored) < expiryLen || isExpired(stored, now) {
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	return nil
+}
+
+// Range calls fn with every unexpired entry and the time left before it
+// expires, zero when it never does, until fn returns false. It lets the
+// client export the cache with ExportCache.
+func (c *Cache) Range(fn func(key string, val []byte, ttl time.Duration) bool) {
+	now := time.Now()
+	c.report(c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(c.bucket).Cursor()
+		for key, stored := cursor.First(); key != nil; key, stored = cursor.Next() {
+			if len(stored) < expiryLen || isExpired(stored, now) {
+				continue
+			}
+
+			var ttl time.Duration
+			if expires := binary.BigEndian.Uint64(stored); expires != 0 {
+				ttl = time.Unix(0, int64(expires)).Sub(now)
+			}
+			if !fn(string(key), append([]byte{}, stored[expiryLen:]...), ttl) {
+				return nil
+			}
+		}
+		return nil
+	}))
+}
+
+// Purge removes every entry from the cache
+func (c *Cache) Purge() {
+	c.report(c.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(c.bucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(c.bucket)
+		return err
+	}))
+}
+
+// Close closes the database when it was opened with Open
+func (c *Cache) Close() error {
+	if !c.owned {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// isExpired reports whether a stored value expired at the given time
+func isExpired(stored []byte, now time.Time) bool {
+	expires := binary.BigEndian.Uint64(stored)
+	return expires != 0 && now.UnixNano() >= int64(expires)
+}
+
+// report passes a database error to the error handler
+func (c *Cache) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
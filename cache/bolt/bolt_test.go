@@ -0,0 +1,146 @@
+package bolt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+	bbolt "go.etcd.io/bbolt"
+)
+
+var _ rdap.IterableCache = (*Cache)(nil)
+
+// openTestCache opens a cache in a temporary directory
+func openTestCache(t *testing.T, path string) *Cache {
+	t.Helper()
+
+	cache, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+func TestCache(t *testing.T) {
+	cache := openTestCache(t, filepath.Join(t.TempDir(), "cache.db"))
+
+	if _, ok := cache.Get("domain/example.com"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	cache.Set("domain/example.com", []byte(`{"ldhName": "example.com"}`), time.Minute)
+	val, ok := cache.Get("domain/example.com")
+	if !ok || string(val) != `{"ldhName": "example.com"}` {
+		t.Errorf("Expected cached value, got %q", val)
+	}
+
+	cache.Delete("domain/example.com")
+	if _, ok := cache.Get("domain/example.com"); ok {
+		t.Error("Expected deleted entry to be missing")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := openTestCache(t, filepath.Join(t.TempDir(), "cache.db"))
+
+	cache.Set("short", []byte("1"), time.Nanosecond)
+	cache.Set("empty", nil, 0)
+	cache.Set("forever", []byte("2"), 0)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected expired entry to be missing")
+	}
+	if val, ok := cache.Get("empty"); !ok || len(val) != 0 {
+		t.Errorf("Expected empty value to be cached, got %q", val)
+	}
+	if _, ok := cache.Get("forever"); !ok {
+		t.Error("Expected entry without TTL to be kept")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	cache := openTestCache(t, filepath.Join(t.TempDir(), "cache.db"))
+
+	cache.Set("short", []byte("1"), time.Nanosecond)
+	cache.Set("forever", []byte("2"), 0)
+	time.Sleep(time.Millisecond)
+	if err := cache.Prune(); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	cache.db.View(func(tx *bbolt.Tx) error {
+		if got := tx.Bucket(cache.bucket).Stats().KeyN; got != 1 {
+			t.Errorf("Expected 1 entry left after Prune, got %d", got)
+		}
+		return nil
+	})
+
+	cache.Purge()
+	if _, ok := cache.Get("forever"); ok {
+		t.Error("Expected entry to be removed by Purge")
+	}
+}
+
+func TestCachePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	cache, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	cache.Set("domain/example.com", []byte("1"), time.Hour)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := openTestCache(t, path)
+	if val, ok := reopened.Get("domain/example.com"); !ok || string(val) != "1" {
+		t.Errorf("Expected entry to survive a restart, got %q", val)
+	}
+}
+
+func TestClientWithBoltCache(t *testing.T) {
+	cache := openTestCache(t, filepath.Join(t.TempDir(), "cache.db"))
+
+	var queries atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer registry.Close()
+
+	client := rdap.NewClient().
+		SetBootstrapData([]byte(`{"version": "1.0", "services": [[["com"], ["` + registry.URL + `/"]]]}`)).
+		SetCache(cache)
+	for i := 0; i < 2; i++ {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected the second lookup to be served from disk, got %d queries", got)
+	}
+}
+
+func TestCacheRange(t *testing.T) {
+	cache := openTestCache(t, filepath.Join(t.TempDir(), "cache.db"))
+	cache.Set("a", []byte("1"), time.Hour)
+	cache.Set("b", []byte("2"), 0)
+	cache.Set("expired", []byte("3"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	got := make(map[string]time.Duration)
+	cache.Range(func(key string, val []byte, ttl time.Duration) bool {
+		got[key] = ttl
+		return true
+	})
+	if len(got) != 2 || got["a"] <= 0 || got["a"] > time.Hour || got["b"] != 0 {
+		t.Errorf("Unexpected entries: %v", got)
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/ducksify/gordap/cache/bolt
+
+go 1.24.3
+
+require (
+	github.com/ducksify/gordap v0.0.0-20261014110539-fef4b39742ce
+	go.etcd.io/bbolt v1.4.3
+)
+
+require (
+	golang.org/x/net v0.44.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
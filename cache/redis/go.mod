@@ -0,0 +1,18 @@
+module github.com/ducksify/gordap/cache/redis
+
+go 1.24.3
+
+require (
+	github.com/alicebob/miniredis/v2 v2.39.0
+	github.com/ducksify/gordap v0.0.0-20261014110539-fef4b39742ce
+	github.com/redis/go-redis/v9 v9.22.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	golang.org/x/net v0.44.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
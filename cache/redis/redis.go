@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// Package redis implements the gordap response cache on top of go-redis, so
+// fleets of workers can share cached RDAP responses
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultPrefix is the prefix added to every cache key by default
+	DefaultPrefix = "gordap:"
+	// defaultTimeout bounds every Redis command
+	defaultTimeout = time.Second
+)
+
+// Cache is an rdap.Cache storing responses in Redis
+type Cache struct {
+	client  goredis.UniversalClient
+	prefix  string
+	timeout time.Duration
+	onError func(error)
+}
+
+// New returns a cache storing responses with the given Redis client, which
+// may be a single node, sentinel or cluster client
+func New(client goredis.UniversalClient) *Cache {
+	return &Cache{
+		client:  client,
+		prefix:  DefaultPrefix,
+		timeout: defaultTimeout,
+	}
+}
+
+// SetPrefix sets the prefix added to every cache key, so several
+// applications can share a Redis database
+func (c *Cache) SetPrefix(prefix string) *Cache {
+	c.prefix = prefix
+	return c
+}
+
+// SetTimeout sets the timeout of every Redis command
+func (c *Cache) SetTimeout(timeout time.Duration) *Cache {
+	c.timeout = timeout
+	return c
+}
+
+// SetErrorHandler sets a function called with every Redis error. Failed
+// commands are otherwise treated as cache misses
+func (c *Cache) SetErrorHandler(handler func(error)) *Cache {
+	c.onError = handler
+	return c
+}
+
+// Get returns the value stored under key, or false when it is missing
+func (c *Cache) Get(key string) ([]byte, bool) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	val, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, goredis.Nil) {
+			c.report(err)
+		}
+		return nil, false
+	}
+	return val, true
+}
+
+// Set stores the value under key, expiring it after ttl. A zero ttl never expires
+func (c *Cache) Set(key string, val []byte, ttl time.Duration) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.report(c.client.Set(ctx, c.prefix+key, val, ttl).Err())
+}
+
+// Delete removes the value stored under key
+func (c *Cache) Delete(key string) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	c.report(c.client.Del(ctx, c.prefix+key).Err())
+}
+
+// context returns the context of a Redis command
+func (c *Cache) context() (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// report passes a Redis error to the error handler
+func (c *Cache) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rdap "github.com/ducksify/gordap"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var _ rdap.Cache = (*Cache)(nil)
+
+// newTestCache returns a cache backed by an in-process Redis server
+func newTestCache(t *testing.T) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client), server
+}
+
+func TestCache(t *testing.T) {
+	cache, server := newTestCache(t)
+
+	if _, ok := cache.Get("domain/example.com"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	cache.Set("domain/example.com", []byte(`{"ldhName": "example.com"}`), time.Minute)
+	val, ok := cache.Get("domain/example.com")
+	if !ok || string(val) != `{"ldhName": "example.com"}` {
+		t.Errorf("Expected cached value, got %q", val)
+	}
+	if ttl := server.TTL("gordap:domain/example.com"); ttl != time.Minute {
+		t.Errorf("Expected TTL of 1m on the prefixed key, got %v", ttl)
+	}
+
+	cache.Delete("domain/example.com")
+	if _, ok := cache.Get("domain/example.com"); ok {
+		t.Error("Expected deleted entry to be missing")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache, server := newTestCache(t)
+
+	cache.Set("short", []byte("1"), time.Second)
+	cache.Set("forever", []byte("2"), 0)
+	server.FastForward(2 * time.Second)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected expired entry to be missing")
+	}
+	if _, ok := cache.Get("forever"); !ok {
+		t.Error("Expected entry without TTL to be kept")
+	}
+}
+
+func TestCachePrefix(t *testing.T) {
+	cache, server := newTestCache(t)
+	cache.SetPrefix("workers:")
+
+	cache.Set("domain/example.com", []byte("1"), 0)
+	if !server.Exists("workers:domain/example.com") {
+		t.Errorf("Expected prefixed key, got keys %v", server.Keys())
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	cache, server := newTestCache(t)
+
+	var reported error
+	cache.SetErrorHandler(func(err error) { reported = err })
+	server.SetError("server down")
+
+	if _, ok := cache.Get("domain/example.com"); ok {
+		t.Error("Expected a miss when Redis fails")
+	}
+	if reported == nil || errors.Is(reported, goredis.Nil) {
+		t.Errorf("Expected Redis error to be reported, got %v", reported)
+	}
+}
+
+func TestClientWithRedisCache(t *testing.T) {
+	cache, server := newTestCache(t)
+
+	var queries atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer registry.Close()
+
+	client := rdap.NewClient().
+		SetBootstrapData([]byte(`{"version": "1.0", "services": [[["com"], ["` + registry.URL + `/"]]]}`)).
+		SetCache(cache)
+	for i := 0; i < 2; i++ {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected the second lookup to be served from Redis, got %d queries", got)
+	}
+	if !server.Exists("gordap:domain/example.com") {
+		t.Errorf("Expected response stored in Redis, got keys %v", server.Keys())
+	}
+}
@@ -0,0 +1,533 @@
+package rdap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapCache is a Cache recording the TTL of every stored key
+type mapCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (m *mapCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.values[key]
+	return val, ok
+}
+
+func (m *mapCache) Set(key string, val []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = val
+	m.ttls[key] = ttl
+}
+
+func (m *mapCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	delete(m.ttls, key)
+}
+
+// newCountingDomainClient returns a mock domain client and its query counter
+func newCountingDomainClient(t *testing.T, status int) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+	return client, &queries
+}
+
+func TestResponseCache(t *testing.T) {
+	cache := newMapCache()
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+	client.SetCache(cache).SetCacheTTL(time.Minute)
+
+	first, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if first.Cached {
+		t.Error("Expected first response to come from the server")
+	}
+
+	second, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if !second.Cached || string(second.Body) != string(first.Body) {
+		t.Errorf("Expected cached response, got %+v", second)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected a single query, got %d", got)
+	}
+	if ttl := cache.ttls["domain/example.com"]; ttl != time.Minute {
+		t.Errorf("Expected cache TTL of 1m, got %v", ttl)
+	}
+}
+
+func TestResponseCacheSkipsErrors(t *testing.T) {
+	cache := newMapCache()
+	client, queries := newCountingDomainClient(t, http.StatusInternalServerError)
+	client.SetCache(cache)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.RDAPResponse("example.com"); err == nil {
+			t.Fatal("Expected error for 500 response")
+		}
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected failed responses not to be cached, got %d queries", got)
+	}
+}
+
+func TestResponseCacheFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*Client) *Client
+	}{
+		{"disable cache", func(c *Client) *Client { return c.SetDisableCache(true) }},
+		{"cache bootstrap only", func(c *Client) *Client { return c.SetCacheBootstrapOnly(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, queries := newCountingDomainClient(t, http.StatusOK)
+			tt.configure(client.SetCache(newMapCache()))
+
+			for i := 0; i < 2; i++ {
+				if _, err := client.RDAP("example.com"); err != nil {
+					t.Fatalf("RDAP failed: %v", err)
+				}
+			}
+			if got := queries.Load(); got != 2 {
+				t.Errorf("Expected responses not to be cached, got %d queries", got)
+			}
+		})
+	}
+}
+
+func TestNegativeCache(t *testing.T) {
+	cache := newMapCache()
+	client, queries := newCountingDomainClient(t, http.StatusNotFound)
+	client.SetCache(cache).SetNegativeCacheTTL(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.RDAPResponse("example.com")
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+		if resp == nil || resp.StatusCode != http.StatusNotFound || resp.Cached != (i > 0) {
+			t.Errorf("Unexpected response on lookup %d: %+v", i, resp)
+		}
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected a single query, got %d", got)
+	}
+	if ttl := cache.ttls["notfound/domain/example.com"]; ttl != time.Minute {
+		t.Errorf("Expected negative cache TTL of 1m, got %v", ttl)
+	}
+
+	registered, err := client.IsRegistered("example.com")
+	if err != nil || registered {
+		t.Errorf("Expected cached availability, got %v, %v", registered, err)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected IsRegistered to use the negative cache, got %d queries", got)
+	}
+}
+
+func TestNegativeCacheDisabled(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusNotFound)
+	client.SetNegativeCacheTTL(0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.IsRegistered("example.com"); err != nil {
+			t.Fatalf("IsRegistered failed: %v", err)
+		}
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected not found responses not to be cached, got %d queries", got)
+	}
+}
+
+func TestResponseCacheRevalidation(t *testing.T) {
+	var queries, revalidations atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.RDAPResponse("example.com")
+		if err != nil {
+			t.Fatalf("RDAPResponse failed: %v", err)
+		}
+		if !strings.Contains(string(resp.Body), "example.com") || resp.Cached != (i > 0) {
+			t.Errorf("Unexpected response on lookup %d: %+v", i, resp)
+		}
+	}
+	if got := revalidations.Load(); got != 2 {
+		t.Errorf("Expected stale entries to be revalidated, got %d conditional requests", got)
+	}
+	if got := queries.Load(); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+}
+
+func TestResponseCacheRevalidationQueries(t *testing.T) {
+	var version, queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if r.Header.Get("If-None-Match") != "" && version.Load() == 1 {
+			// The conditional request fails
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		if version.Load() == 2 {
+			w.Write([]byte(`{"rdapConformance": ["rdap_level_0"], "errorCode": 404}`))
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	// A failed revalidation falls back to the query
+	version.Store(1)
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil || resp.Cached || resp.Source != SourceRegistry {
+		t.Fatalf("Expected a fresh registry response, got %+v, %v", resp, err)
+	}
+
+	if got := queries.Load(); got != 3 {
+		t.Errorf("Expected the failed revalidation to be queried again, got %d requests", got)
+	}
+
+	// A changed answer goes through the checks of the query without being
+	// fetched again
+	version.Store(2)
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Expected ErrDomainNotFound, got %v", err)
+	}
+	if got := queries.Load(); got != 4 {
+		t.Errorf("Expected one request for the changed answer, got %d requests", got-3)
+	}
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Expected the changed answer to be cached as not found, got %v", err)
+	}
+	if got := queries.Load(); got != 4 {
+		t.Errorf("Expected the not found answer to be cached, got %d requests", got-4)
+	}
+}
+
+func TestResponseCacheRevalidationChanged(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, queries.Load()))
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.RDAPResponse("example.com")
+		if err != nil {
+			t.Fatalf("RDAPResponse failed: %v", err)
+		}
+		if resp.Cached || resp.Source != SourceRegistry {
+			t.Errorf("Expected a fresh registry response on lookup %d, got %+v", i, resp)
+		}
+	}
+	if got := queries.Load(); got != 3 {
+		t.Errorf("Expected one request per lookup, got %d", got)
+	}
+}
+
+func TestResponseCacheNoStore(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected no-store responses not to be cached, got %d queries", got)
+	}
+}
+
+func TestResponseExpiry(t *testing.T) {
+	date := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		store  bool
+	}{
+		{"default TTL", http.Header{}, time.Hour, true},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=600"}}, 10 * time.Minute, true},
+		{"max-age minus age", http.Header{"Cache-Control": {"max-age=600"}, "Age": {"60"}}, 9 * time.Minute, true},
+		{"s-maxage", http.Header{"Cache-Control": {"max-age=600, s-maxage=60"}}, time.Minute, true},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache, max-age=600"}}, 0, true},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}, 0, false},
+		{"expires", http.Header{
+			"Date":    {date.Format(http.TimeFormat)},
+			"Expires": {date.Add(30 * time.Minute).Format(http.TimeFormat)},
+		}, 30 * time.Minute, true},
+		{"invalid expires", http.Header{"Expires": {"0"}}, 0, true},
+	}
+
+	client := NewClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			expires, store := client.responseExpiry(tt.header, time.Hour)
+			if store != tt.store {
+				t.Fatalf("Expected store %v, got %v", tt.store, store)
+			}
+			if !store {
+				return
+			}
+			if got := expires.Sub(before); got < tt.want-time.Second || got > tt.want+time.Second {
+				t.Errorf("Expected expiry in %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResponseCacheKeyNormalization(t *testing.T) {
+	var queries atomic.Int32
+	var paths []string
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+
+	for _, domain := range []string{"Example.COM", "example.com.", " www.example.com ", "bücher.com", "xn--bcher-kva.com", "BÜCHER.com."} {
+		if _, err := client.RDAP(domain); err != nil {
+			t.Fatalf("RDAP(%q) failed: %v", domain, err)
+		}
+	}
+
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected one query per normalized domain, got %d: %v", got, paths)
+	}
+	if !slices.Equal(paths, []string{"/domain/example.com", "/domain/xn--bcher-kva.com"}) {
+		t.Errorf("Expected normalized query paths, got %v", paths)
+	}
+}
+
+func TestServeStaleOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxStale time.Duration
+		stale    bool
+	}{
+		{"within window", time.Hour, true},
+		{"disabled", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failing atomic.Bool
+			client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if failing.Load() {
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Cache-Control", "max-age=0")
+				w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+			}).SetServeStaleOnError(tt.maxStale)
+
+			if _, err := client.RDAP("example.com"); err != nil {
+				t.Fatalf("RDAP failed: %v", err)
+			}
+			failing.Store(true)
+
+			resp, err := client.RDAPResponse("example.com")
+			if !tt.stale {
+				if err == nil {
+					t.Fatal("Expected error without stale serving")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected stale response, got error: %v", err)
+			}
+			if !resp.Stale || !resp.Cached || resp.Age <= 0 || !strings.Contains(string(resp.Body), "example.com") {
+				t.Errorf("Unexpected stale response: %+v", resp)
+			}
+			if got := client.CacheStats().StaleServed; got != 1 {
+				t.Errorf("Expected 1 stale response served, got %d", got)
+			}
+		})
+	}
+}
+
+func TestServeStaleOnErrorExpiredWindow(t *testing.T) {
+	cache := newMapCache()
+	client, _ := newCountingDomainClient(t, http.StatusBadGateway)
+	client.SetCache(cache).SetServeStaleOnError(time.Minute)
+
+	entry, _ := json.Marshal(responseEntry{
+		Body:       []byte(`{}`),
+		StatusCode: http.StatusOK,
+		Expires:    time.Now().Add(-time.Hour),
+		Stored:     time.Now().Add(-2 * time.Hour),
+	})
+	cache.Set("domain/example.com", entry, 0)
+
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Error("Expected error for an entry past the serve-stale window")
+	}
+}
+
+func TestCacheNamespace(t *testing.T) {
+	shared := newMapCache()
+	tenantA, queriesA := newCountingDomainClient(t, http.StatusOK)
+	tenantB, queriesB := newCountingDomainClient(t, http.StatusOK)
+	tenantA.SetCache(shared).SetCacheNamespace("tenant-a")
+	tenantB.SetCache(shared).SetCacheNamespace("tenant-b")
+
+	for _, client := range []*Client{tenantA, tenantB, tenantA, tenantB} {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+
+	if queriesA.Load() != 1 || queriesB.Load() != 1 {
+		t.Errorf("Expected one query per namespace, got %d and %d", queriesA.Load(), queriesB.Load())
+	}
+	for _, key := range []string{"tenant-a/domain/example.com", "tenant-b/domain/example.com"} {
+		if _, ok := shared.Get(key); !ok {
+			t.Errorf("Expected namespaced key %s in the shared cache", key)
+		}
+	}
+	if _, ok := shared.Get("domain/example.com"); ok {
+		t.Error("Expected no key outside the namespaces")
+	}
+}
+
+func TestCacheTTLPolicy(t *testing.T) {
+	cache := newMapCache()
+	client := NewClient().
+		SetCache(cache).
+		SetCacheTTL(time.Hour).
+		SetCacheTTLForTLD(".IO", 15*time.Minute).
+		SetCacheTTLForTLD("net", 2*time.Hour)
+
+	tests := map[string]time.Duration{
+		"domain/example.com": time.Hour,
+		"domain/example.io":  15 * time.Minute,
+		"domain/example.net": 2 * time.Hour,
+	}
+	for key, want := range tests {
+		if got := client.responseTTL(key); got != want {
+			t.Errorf("responseTTL(%s) = %v, want %v", key, got, want)
+		}
+	}
+
+	client.SetCacheTTLPolicy(func(tld string, ttl time.Duration) time.Duration {
+		if tld == "net" {
+			return ttl / 4
+		}
+		return ttl
+	})
+	if got := client.responseTTL("domain/example.net"); got != 30*time.Minute {
+		t.Errorf("Expected policy TTL of 30m, got %v", got)
+	}
+	if got := client.responseTTL("domain/example.io"); got != 15*time.Minute {
+		t.Errorf("Expected per-TLD TTL of 15m, got %v", got)
+	}
+
+	client.storeResponse("domain/example.net", &Response{Body: []byte(`{}`), StatusCode: http.StatusOK}, nil)
+	if ttl := cache.ttls["domain/example.net"]; ttl != 30*time.Minute {
+		t.Errorf("Expected stored TTL of 30m, got %v", ttl)
+	}
+}
+
+func TestCacheFlagInteraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		configure  func(*Client) *Client
+		bootstraps int32
+		queries    int32
+	}{
+		{"default", func(c *Client) *Client { return c }, 1, 1},
+		{"cache bootstrap only", func(c *Client) *Client { return c.SetCacheBootstrapOnly(true) }, 1, 3},
+		{"disable cache", func(c *Client) *Client { return c.SetDisableCache(true).SetCacheBootstrapOnly(true) }, 3, 3},
+		{"no response cache", func(c *Client) *Client { return c.SetCache(nil) }, 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries atomic.Int32
+			registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				queries.Add(1)
+				w.Write([]byte(`{"objectClassName": "domain"}`))
+			}))
+			defer registry.Close()
+
+			var bootstraps atomic.Int32
+			bootstrap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bootstraps.Add(1)
+				w.Write([]byte(`{"version": "1.0", "services": [[["com"], ["` + registry.URL + `/"]]]}`))
+			}))
+			defer bootstrap.Close()
+
+			client := tt.configure(NewClient().SetBootstrapURL(bootstrap.URL))
+			for i := 0; i < 3; i++ {
+				if _, err := client.RDAP("example.com"); err != nil {
+					t.Fatalf("RDAP failed: %v", err)
+				}
+			}
+
+			if got := bootstraps.Load(); got != tt.bootstraps {
+				t.Errorf("Expected %d bootstrap fetches, got %d", tt.bootstraps, got)
+			}
+			if got := queries.Load(); got != tt.queries {
+				t.Errorf("Expected %d registry queries, got %d", tt.queries, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sources of a domain response, recorded in Response.Source
+const (
+	SourceRegistry   = "registry"
+	SourceRegistrar  = "registrar"
+	SourceAggregator = "aggregator"
+)
+
+// FallbackHandler answers a domain query in a fallback chain, for example
+// from a WHOIS gateway or a local database
+type FallbackHandler func(ctx context.Context, domain string) (*Response, error)
+
+// FallbackStep is a source of domain data in a fallback chain
+type FallbackStep struct {
+	source string
+	query  func(c *Client, ctx context.Context, domain string, options requestOptions) (*Response, error)
+}
+
+// Source returns the name the step records in Response.Source
+func (s FallbackStep) Source() string {
+	return s.source
+}
+
+// FallbackRegistry returns the step querying the registry RDAP servers of
+// the TLD, failing over between them as without a chain
+func FallbackRegistry() FallbackStep {
+	return FallbackStep{source: SourceRegistry, query: (*Client).queryRegistry}
+}
+
+// FallbackRegistrar returns the step querying the registrar RDAP server the
+// cached registry response of the domain refers to with its "related" link,
+// which serves the registration data while the registry is unavailable.
+// The step fails when no cached response, fresh or stale, holds the link.
+// Like in DomainFull, headers given with WithHeader are meant for the registry
+// and are not sent to the registrar.
+func FallbackRegistrar() FallbackStep {
+	return FallbackStep{source: SourceRegistrar, query: (*Client).queryRegistrar}
+}
+
+// FallbackAggregator returns the step querying an RDAP aggregator, given as
+// a base URL or a URL template containing "{domain}". An empty URL stands
+// for DefaultFallbackAggregator.
+func FallbackAggregator(url string) FallbackStep {
+	if url == "" {
+		url = DefaultFallbackAggregator
+	} else if !isURLTemplate(url) {
+		url = normalizeServer(url)
+	}
+	return FallbackStep{source: SourceAggregator, query: func(c *Client, ctx context.Context, domain string, options requestOptions) (*Response, error) {
+		return c.queryRDAPResponse(ctx, domain, url, options.header)
+	}}
+}
+
+// FallbackFunc returns a step answering queries with a custom handler. The
+// source name is recorded in the responses the handler returns without one.
+func FallbackFunc(source string, handler FallbackHandler) FallbackStep {
+	return FallbackStep{source: source, query: func(c *Client, ctx context.Context, domain string, options requestOptions) (*Response, error) {
+		return handler(ctx, domain)
+	}}
+}
+
+// SetFallbackChain sets the ordered steps queried for the domains of a TLD,
+// each one tried until one answers. A registry 404 is an answer and ends the
+// chain; a network error, a server error or a rate limit moves on to the
+// next step. An empty TLD sets the chain of the TLDs without their own, and
+// no steps remove the chain. A chain replaces SetFallbackAggregator for the
+// TLDs it applies to.
+func (c *Client) SetFallbackChain(tld string, steps ...FallbackStep) *Client {
+	tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+	if len(steps) == 0 {
+		delete(c.fallbackChains, tld)
+		return c
+	}
+	if c.fallbackChains == nil {
+		c.fallbackChains = make(map[string][]FallbackStep)
+	}
+	c.fallbackChains[tld] = append([]FallbackStep(nil), steps...)
+	return c
+}
+
+// fallbackChain returns the chain of the TLD of a domain, if any
+func (c *Client) fallbackChain(domain string) ([]FallbackStep, bool) {
+	if chain, ok := c.fallbackChains[getTLD(domain)]; ok {
+		return chain, true
+	}
+	chain, ok := c.fallbackChains[""]
+	return chain, ok
+}
+
+// queryChain runs the steps of a fallback chain until one answers
+func (c *Client) queryChain(ctx context.Context, domain string, options requestOptions, chain []FallbackStep) (*Response, error) {
+	var resp *Response
+	var err error
+	for i, step := range chain {
+		if i > 0 {
+			if !c.allowRetry() {
+				break
+			}
+			c.onRetry(RetryInfo{Context: ctx, Reason: RetryFallback, Query: domain, Next: step.source, Err: err})
+		}
+		resp, err = step.query(c, ctx, domain, options)
+		if resp != nil && resp.Source == "" {
+			resp.Source = step.source
+		}
+		if !isQueryFailure(err) || errors.Is(err, ErrOffline) || ctx.Err() != nil {
+			break
+		}
+	}
+	return resp, err
+}
+
+// queryRegistrar queries the registrar RDAP server of a domain known from its
+// cached responses, without the headers of the lookup
+func (c *Client) queryRegistrar(ctx context.Context, domain string, options requestOptions) (*Response, error) {
+	link, ok := c.cachedRegistrarURL(domain)
+	if !ok {
+		return nil, fmt.Errorf("no registrar RDAP server known for %s", domain)
+	}
+	return c.doRequestWithHeader(ctx, "", link, nil)
+}
+
+// cachedRegistrarURL returns the registrar RDAP URL of a domain, taken from the
+// related link of its cached registry response or from the URL of a cached
+// registrar response
+func (c *Client) cachedRegistrarURL(domain string) (string, bool) {
+	cache := c.responseCache()
+	if cache == nil {
+		return "", false
+	}
+
+	if _, entry, ok := c.cachedEntry(cache, domainCacheKey(domain)); ok {
+		if entry.Source == SourceRegistrar && entry.URL != "" {
+			return entry.URL, true
+		}
+		var registry Domain
+		if json.Unmarshal(entry.Body, &registry) == nil {
+			if link, ok := registry.RelatedLink(); ok && link.Href != entry.URL {
+				return link.Href, true
+			}
+		}
+	}
+	if _, entry, ok := c.cachedEntry(cache, registrarCacheKey(domain)); ok && entry.URL != "" {
+		return entry.URL, true
+	}
+	return "", false
+}
@@ -0,0 +1,148 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFallbackChainCustomHandler(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}).SetFallbackChain("com", FallbackRegistry(), FallbackFunc("whois", func(ctx context.Context, domain string) (*Response, error) {
+		return &Response{Body: []byte(`{"objectClassName": "domain", "ldhName": "` + domain + `"}`), StatusCode: http.StatusOK}, nil
+	}))
+
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("Expected custom handler answer, got: %v", err)
+	}
+	if resp.Source != "whois" || !strings.Contains(string(resp.Body), "example.com") {
+		t.Errorf("Unexpected response from %q: %s", resp.Source, resp.Body)
+	}
+
+	// The source is kept with the cached copy
+	resp, err = client.RDAPResponse("example.com")
+	if err != nil || !resp.Cached || resp.Source != "whois" {
+		t.Errorf("Expected cached whois response, got %+v, %v", resp, err)
+	}
+}
+
+func TestFallbackChainStopsOnAnswer(t *testing.T) {
+	var called atomic.Bool
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).SetFallbackChain("", FallbackRegistry(), FallbackFunc("custom", func(ctx context.Context, domain string) (*Response, error) {
+		called.Store(true)
+		return nil, errors.New("unexpected")
+	}))
+
+	// A registry 404 is an answer and ends the chain
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if called.Load() {
+		t.Error("Expected the chain to stop at the registry answer")
+	}
+}
+
+func TestFallbackChainAggregator(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain"}`)).
+		SetFallbackChain("de", FallbackRegistry(), FallbackAggregator(aggregator.URL))
+
+	resp, err := client.RDAPResponse("example.de")
+	if err != nil {
+		t.Fatalf("Expected aggregator answer, got: %v", err)
+	}
+	if resp.Source != SourceAggregator || resp.Server != aggregator.URL+"/" {
+		t.Errorf("Unexpected response from %q at %s", resp.Source, resp.Server)
+	}
+
+	// The chain only applies to its TLD
+	resp, err = client.RDAPResponse("example.com")
+	if err != nil || resp.Source != SourceRegistry {
+		t.Errorf("Expected registry response, got %+v, %v", resp, err)
+	}
+}
+
+func TestFallbackChainRegistrar(t *testing.T) {
+	var credentials atomic.Bool
+	registrar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			credentials.Store(true)
+		}
+		serveJSON(`{"objectClassName": "domain", "ldhName": "example.com", "port43": "whois.example"}`).ServeHTTP(w, r)
+	}))
+	defer registrar.Close()
+
+	var down atomic.Bool
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{
+			"objectClassName": "domain",
+			"ldhName": "EXAMPLE.COM",
+			"links": [{"rel": "related", "type": "application/rdap+json", "href": "` + registrar.URL + `/domain/EXAMPLE.COM"}]
+		}`))
+	}).SetAllowInsecureServers(true).SetFallbackChain("com", FallbackRegistry(), FallbackRegistrar())
+
+	// Without a cached registry response the registrar is unknown
+	down.Store(true)
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected a failure without a known registrar")
+	}
+
+	down.Store(false)
+	if resp, err := client.RDAPResponse("example.com"); err != nil || resp.Source != SourceRegistry {
+		t.Fatalf("Expected registry response, got %+v, %v", resp, err)
+	}
+
+	down.Store(true)
+	for range 2 {
+		resp, err := client.RDAPResponse("example.com", WithNoCache())
+		if err != nil {
+			t.Fatalf("Expected registrar answer, got: %v", err)
+		}
+		if resp.Source != SourceRegistrar || !strings.Contains(string(resp.Body), "whois.example") {
+			t.Errorf("Unexpected response from %q: %s", resp.Source, resp.Body)
+		}
+	}
+
+	// The credentials of the registry are not sent to the registrar
+	if _, err := client.RDAP("example.com", WithHeader("Authorization", "Bearer registry-token")); err != nil {
+		t.Fatalf("Expected registrar answer, got: %v", err)
+	}
+	if credentials.Load() {
+		t.Error("Expected the registrar step to be sent without the lookup headers")
+	}
+}
+
+func TestResponseSource(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain"}`)).SetFallbackAggregator(aggregator.URL)
+
+	if resp, err := client.RDAPResponse("example.com"); err != nil || resp.Source != SourceRegistry {
+		t.Errorf("Expected registry source, got %+v, %v", resp, err)
+	}
+	if resp, err := client.RDAPResponse("example.de"); err != nil || resp.Source != SourceAggregator {
+		t.Errorf("Expected aggregator source, got %+v, %v", resp, err)
+	}
+}
+
+func TestSetFallbackChainRemove(t *testing.T) {
+	client := NewClient().SetFallbackChain(".COM", FallbackRegistry())
+	if _, ok := client.fallbackChain("example.com"); !ok {
+		t.Fatal("Expected a chain for com")
+	}
+	client.SetFallbackChain("com")
+	if _, ok := client.fallbackChain("example.com"); ok {
+		t.Error("Expected the chain to be removed")
+	}
+}
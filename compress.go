@@ -0,0 +1,72 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// minCompressedSize is the size under which cached values are stored as is,
+// since compressing them saves little
+const minCompressedSize = 512
+
+// gzipMagic starts every gzip stream and never starts a JSON value
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SetCacheCompression gzips the responses stored in the cache, which shrinks
+// RDAP responses with many entities up to tenfold and pays off with Redis or
+// disk caches. Compressed and plain values are both read back whatever the
+// setting, so it can be changed on a populated cache
+func (c *Client) SetCacheCompression(enabled bool) *Client {
+	c.compressCache = enabled
+	return c
+}
+
+// encodeCacheValue returns the value to store in the cache, compressed when enabled
+func (c *Client) encodeCacheValue(val []byte) []byte {
+	if !c.compressCache || len(val) < minCompressedSize {
+		return val
+	}
+
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if _, err := zw.Write(val); err != nil {
+		return val
+	}
+	if err := zw.Close(); err != nil {
+		return val
+	}
+	return buf.Bytes()
+}
+
+// decodeCacheValue returns a value read from the cache, decompressing it when needed
+func decodeCacheValue(val []byte) ([]byte, error) {
+	if !bytes.HasPrefix(val, gzipMagic) {
+		return val, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(val))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
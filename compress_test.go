@@ -0,0 +1,94 @@
+package rdap
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newLargeDomainClient returns a mock domain client serving a response worth compressing
+func newLargeDomainClient(t *testing.T) *Client {
+	t.Helper()
+
+	body := `{"objectClassName": "domain", "ldhName": "example.com", "remarks": [` +
+		strings.Repeat(`{"description": ["Lorem ipsum dolor sit amet"]},`, 50) + `{}]}`
+	return newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+}
+
+func TestCacheCompression(t *testing.T) {
+	plain := newMapCache()
+	if _, err := newLargeDomainClient(t).SetCache(plain).RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	compressed := newMapCache()
+	client := newLargeDomainClient(t).SetCache(compressed).SetCacheCompression(true)
+	body, err := client.RDAP("example.com")
+	if err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	key := domainCacheKey("example.com")
+	val := compressed.values[key]
+	if !bytes.HasPrefix(val, gzipMagic) {
+		t.Fatalf("Expected a gzip value, got %q", val)
+	}
+	if len(val) >= len(plain.values[key]) {
+		t.Errorf("Expected compressed value smaller than %d bytes, got %d", len(plain.values[key]), len(val))
+	}
+
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if !resp.Cached || !bytes.Equal(resp.Body, body) {
+		t.Errorf("Expected the cached body to round-trip, got cached=%v", resp.Cached)
+	}
+}
+
+func TestCacheCompressionSmallValues(t *testing.T) {
+	cache := newMapCache()
+	client, _ := newCountingDomainClient(t, http.StatusOK)
+	if _, err := client.SetCache(cache).SetCacheCompression(true).RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	if val := cache.values[domainCacheKey("example.com")]; bytes.HasPrefix(val, gzipMagic) {
+		t.Error("Expected small value to be stored uncompressed")
+	}
+}
+
+func TestExportCacheCompressed(t *testing.T) {
+	source := newLargeDomainClient(t).SetCacheCompression(true)
+	if _, err := source.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	if err := source.ExportCache(&snapshot); err != nil {
+		t.Fatalf("ExportCache failed: %v", err)
+	}
+	if !strings.Contains(snapshot.String(), `"key":"domain/example.com"`) {
+		t.Fatalf("Expected compressed entry in snapshot, got %s", snapshot.String())
+	}
+
+	target, queries := newCountingDomainClient(t, http.StatusOK)
+	if err := target.ImportCache(&snapshot); err != nil {
+		t.Fatalf("ImportCache failed: %v", err)
+	}
+	if resp, err := target.RDAPResponse("example.com"); err != nil || !resp.Cached || queries.Load() != 0 {
+		t.Errorf("Expected imported response from the cache, got %v after %d queries", err, queries.Load())
+	}
+}
+
+func TestDecodeCacheValueInvalid(t *testing.T) {
+	if _, err := decodeCacheValue([]byte{0x1f, 0x8b, 0x00}); err == nil {
+		t.Error("Expected error for a truncated gzip value")
+	}
+	if val, err := decodeCacheValue([]byte(`{}`)); err != nil || string(val) != `{}` {
+		t.Errorf("Expected plain value unchanged, got %q, %v", val, err)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+const (
+	// defaultKeepAlive is the keep-alive period of connections to RDAP servers
+	defaultKeepAlive = 30 * time.Second
+	// preferredFamilyTimeout bounds the connection attempt over the preferred
+	// address family before the other one is tried
+	preferredFamilyTimeout = 2 * time.Second
+)
+
+// IPPreference selects the address families used to connect to RDAP hosts
+type IPPreference int
+
+// IP preferences
+const (
+	// IPDefault dials both families as the Go dialer does
+	IPDefault IPPreference = iota
+	// IPv4Only only connects over IPv4
+	IPv4Only
+	// IPv6Only only connects over IPv6
+	IPv6Only
+	// PreferIPv4 tries IPv4 first and falls back to IPv6
+	PreferIPv4
+	// PreferIPv6 tries IPv6 first and falls back to IPv4
+	PreferIPv6
+)
+
+// DialContextFunc opens network connections, like net.Dialer.DialContext
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SetResolver sets the resolver looking up the addresses of RDAP hosts, for
+// example to go through an internal DNS server or a DoH proxy, without
+// replacing the HTTP client. A nil resolver uses the system one. Like the
+// transport setters, it only applies to an *http.Transport; it is kept when
+// SetHTTPClient or SetTransport replace it later.
+func (c *Client) SetResolver(resolver *net.Resolver) *Client {
+	c.resolver = resolver
+	c.applyDialer()
+	return c
+}
+
+// SetDialContext sets the function opening connections to RDAP hosts,
+// which then does its own address resolution; the resolver set with
+// SetResolver is not used. A nil function restores the default dialer.
+func (c *Client) SetDialContext(dial DialContextFunc) *Client {
+	c.dialContext = dial
+	c.applyDialer()
+	return c
+}
+
+// SetIPPreference sets the address families used to connect to RDAP hosts,
+// for registry endpoints with broken AAAA records. With PreferIPv4 and
+// PreferIPv6 the other family is tried when the preferred one fails or
+// doesn't connect within 2 seconds. The preference also applies to a
+// function set with SetDialContext, which is called with "tcp4" or "tcp6".
+func (c *Client) SetIPPreference(preference IPPreference) *Client {
+	c.ipPreference = preference
+	c.applyDialer()
+	return c
+}
+
+// dial wraps a dial function to apply the preference to TCP connections
+func (p IPPreference) dial(dial DialContextFunc) DialContextFunc {
+	if p == IPDefault {
+		return dial
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network != "tcp" {
+			return dial(ctx, network, addr)
+		}
+
+		switch p {
+		case IPv4Only:
+			return dial(ctx, "tcp4", addr)
+		case IPv6Only:
+			return dial(ctx, "tcp6", addr)
+		}
+
+		preferred, other := "tcp6", "tcp4"
+		if p == PreferIPv4 {
+			preferred, other = other, preferred
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, preferredFamilyTimeout)
+		conn, err := dial(attemptCtx, preferred, addr)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return conn, err
+		}
+		return dial(ctx, other, addr)
+	}
+}
+
+// newDialer returns the dialer of the default transport using the given resolver
+func newDialer(resolver *net.Resolver) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+		Resolver:  resolver,
+	}
+}
+
+// reapplyDialer installs the dialer in a new transport when any dialer
+// setting differs from the defaults, leaving the dialer of the transport
+// alone otherwise
+func (c *Client) reapplyDialer() {
+	denyPrivate := c.serverPolicy != nil && c.serverPolicy.DenyPrivateIPs
+	if c.resolver != nil || c.dialContext != nil || c.ipPreference != IPDefault || denyPrivate {
+		c.applyDialer()
+	}
+}
+
+// applyDialer installs the dialer built from the client settings in the transport
+func (c *Client) applyDialer() {
+	transport := c.ownTransport()
+	if transport == nil {
+		return
+	}
+
+	denyPrivate := c.serverPolicy != nil && c.serverPolicy.DenyPrivateIPs
+	dial := c.dialContext
+	switch {
+	case dial == nil && denyPrivate:
+		dial = denyPrivateDial(newDialer(c.resolver)).DialContext
+	case dial == nil:
+		dial = newDialer(c.resolver).DialContext
+	case denyPrivate:
+		dial = denyPrivateConn(dial)
+	}
+	transport.DialContext = c.ipPreference.dial(dial)
+}
@@ -0,0 +1,144 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSetResolver(t *testing.T) {
+	var lookups atomic.Int32
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			lookups.Add(1)
+			return nil, errors.New("internal resolver unreachable")
+		},
+	}
+	client := NewClient().
+		SetServerOverride("example", "https://rdap.nic.example/").
+		SetResolver(resolver)
+
+	_, err := client.RDAP("domain.example")
+	if err == nil || !strings.Contains(err.Error(), "internal resolver unreachable") {
+		t.Errorf("Expected the custom resolver error, got %v", err)
+	}
+	if lookups.Load() == 0 {
+		t.Error("Expected the custom resolver to be used")
+	}
+}
+
+func TestSetDialContext(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "domain.example"}`))
+	defer server.Close()
+
+	var dialed atomic.Value
+	client := NewClient().
+		SetAllowInsecureServers(true).
+		SetServerOverride("example", "http://rdap.nic.example/").
+		SetDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed.Store(addr)
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+		})
+
+	if _, err := client.RDAP("domain.example"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got, _ := dialed.Load().(string); got != "rdap.nic.example:80" {
+		t.Errorf("Expected a dial to rdap.nic.example:80, got %q", got)
+	}
+
+	// A nil function restores the default dialer
+	if client.SetDialContext(nil).transport().DialContext == nil {
+		t.Error("Expected the default dialer")
+	}
+}
+
+func TestSetResolverCustomHTTPClient(t *testing.T) {
+	var calls atomic.Int32
+	client := NewClient().SetHTTPClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("network disabled")
+	}))
+
+	// Custom HTTP clients are left alone
+	client.SetResolver(net.DefaultResolver)
+	client.RDAP("example.com")
+	if calls.Load() == 0 {
+		t.Error("Expected the custom HTTP client to be used")
+	}
+}
+
+func TestIPPreferenceDial(t *testing.T) {
+	tests := []struct {
+		preference IPPreference
+		failing    string
+		want       []string
+	}{
+		{IPv4Only, "", []string{"tcp4"}},
+		{IPv6Only, "", []string{"tcp6"}},
+		{PreferIPv6, "", []string{"tcp6"}},
+		{PreferIPv6, "tcp6", []string{"tcp6", "tcp4"}},
+		{PreferIPv4, "tcp4", []string{"tcp4", "tcp6"}},
+	}
+
+	for _, tt := range tests {
+		var networks []string
+		dial := tt.preference.dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			networks = append(networks, network)
+			if network == tt.failing {
+				return nil, errors.New("network unreachable")
+			}
+			return nil, nil
+		})
+
+		if _, err := dial(context.Background(), "tcp", "rdap.example.com:443"); err != nil {
+			t.Errorf("preference %d: unexpected error %v", tt.preference, err)
+		}
+		if strings.Join(networks, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("preference %d with %q failing: dialed %v, want %v", tt.preference, tt.failing, networks, tt.want)
+		}
+	}
+}
+
+func TestIPPreferenceOtherNetworks(t *testing.T) {
+	var got string
+	dial := IPv6Only.dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		got = network
+		return nil, nil
+	})
+
+	dial(context.Background(), "tcp4", "127.0.0.1:443")
+	if got != "tcp4" {
+		t.Errorf("Expected an explicit network to be kept, got %s", got)
+	}
+}
+
+func TestSetIPPreference(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "domain.example"}`))
+	defer server.Close()
+
+	var network atomic.Value
+	client := NewClient().
+		SetAllowInsecureServers(true).
+		SetServerOverride("example", "http://rdap.nic.example/").
+		SetDialContext(func(ctx context.Context, n, addr string) (net.Conn, error) {
+			network.Store(n)
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, n, server.Listener.Addr().String())
+		}).
+		SetIPPreference(IPv4Only)
+
+	if _, err := client.RDAP("domain.example"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got, _ := network.Load().(string); got != "tcp4" {
+		t.Errorf("Expected an IPv4 dial, got %q", got)
+	}
+}
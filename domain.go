@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Domain performs RDAP query for the given domain and returns the parsed domain object
+func (c *Client) Domain(domain string, opts ...RequestOption) (*Domain, error) {
+	body, err := c.RDAP(domain, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Domain
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// HasDNSSEC reports whether the delegation of the given domain is DNSSEC signed
+func (c *Client) HasDNSSEC(domain string, opts ...RequestOption) (bool, error) {
+	result, err := c.Domain(domain, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.SecureDNS.IsSigned(), nil
+}
+
+// Expiration returns the expiration date of the given domain
+func (c *Client) Expiration(domain string, opts ...RequestOption) (time.Time, error) {
+	result, err := c.Domain(domain, opts...)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return result.ExpirationDate()
+}
+
+// Registrar returns the registrar of the given domain with its IANA ID and abuse contact
+func (c *Client) Registrar(domain string, opts ...RequestOption) (*Registrar, error) {
+	result, err := c.Domain(domain, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	registrar, ok := result.Registrar()
+	if !ok {
+		return nil, fmt.Errorf("no registrar found for %s", domain)
+	}
+
+	return registrar, nil
+}
+
+// Nameservers returns the lowercase host names of the given domain's nameservers
+func (c *Client) Nameservers(domain string, opts ...RequestOption) ([]string, error) {
+	result, err := c.Domain(domain, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.NameserverNames(), nil
+}
+
+// DomainAge returns the time elapsed since the given domain was registered
+func (c *Client) DomainAge(domain string, opts ...RequestOption) (time.Duration, error) {
+	result, err := c.Domain(domain, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Age(time.Now())
+}
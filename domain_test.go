@@ -0,0 +1,179 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newMockDomainClient returns a client whose .com bootstrap entry points to
+// an RDAP server answering with the given handler
+func newMockDomainClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	mockServer := httptest.NewServer(handler)
+	t.Cleanup(mockServer.Close)
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"com"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	t.Cleanup(bootstrapServer.Close)
+
+	return NewClient().SetBootstrapURL(bootstrapServer.URL)
+}
+
+// serveJSON returns a handler answering every request with the given RDAP JSON
+func serveJSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestDomain(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"status": ["active"]
+	}`))
+
+	domain, err := client.Domain("example.com")
+	if err != nil {
+		t.Fatalf("Domain failed: %v", err)
+	}
+	if domain.LDHName != "example.com" || len(domain.Status) != 1 {
+		t.Errorf("Unexpected domain: %+v", domain)
+	}
+}
+
+func TestDomainInvalidJSON(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON("invalid json"))
+
+	if _, err := client.Domain("example.com"); err == nil {
+		t.Error("Expected error for invalid JSON response")
+	}
+}
+
+func TestHasDNSSEC(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"secureDNS": {
+			"delegationSigned": true,
+			"dsData": [{"keyTag": 370, "algorithm": 13, "digestType": 2, "digest": "BE74"}]
+		}
+	}`))
+
+	signed, err := client.HasDNSSEC("example.com")
+	if err != nil {
+		t.Fatalf("HasDNSSEC failed: %v", err)
+	}
+	if !signed {
+		t.Error("Expected example.com to be signed")
+	}
+
+	client = newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	signed, err = client.HasDNSSEC("example.com")
+	if err != nil {
+		t.Fatalf("HasDNSSEC failed: %v", err)
+	}
+	if signed {
+		t.Error("Expected domain without secureDNS to be unsigned")
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"events": [
+			{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+			{"eventAction": "expiration", "eventDate": "2025-08-13T04:00:00+0000"}
+		]
+	}`))
+
+	expires, err := client.Expiration("example.com")
+	if err != nil {
+		t.Fatalf("Expiration failed: %v", err)
+	}
+	expected := time.Date(2025, 8, 13, 4, 0, 0, 0, time.UTC)
+	if !expires.Equal(expected) {
+		t.Errorf("Expected expiration %v, got %v", expected, expires)
+	}
+}
+
+func TestRegistrar(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"entities": [
+			{
+				"objectClassName": "entity",
+				"handle": "376",
+				"roles": ["registrar"],
+				"publicIds": [{"type": "IANA Registrar ID", "identifier": "376"}],
+				"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "RESERVED-Internet Assigned Numbers Authority"]]]
+			}
+		]
+	}`))
+
+	registrar, err := client.Registrar("example.com")
+	if err != nil {
+		t.Fatalf("Registrar failed: %v", err)
+	}
+	if registrar.IANAID != "376" || registrar.Name != "RESERVED-Internet Assigned Numbers Authority" {
+		t.Errorf("Unexpected registrar: %+v", registrar)
+	}
+
+	client = newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	if _, err := client.Registrar("example.com"); err == nil {
+		t.Error("Expected error when the response has no registrar")
+	}
+}
+
+func TestNameservers(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"nameservers": [
+			{"objectClassName": "nameserver", "ldhName": "A.IANA-SERVERS.NET"},
+			{"objectClassName": "nameserver", "ldhName": "B.IANA-SERVERS.NET"}
+		]
+	}`))
+
+	nameservers, err := client.Nameservers("example.com")
+	if err != nil {
+		t.Fatalf("Nameservers failed: %v", err)
+	}
+	if len(nameservers) != 2 || nameservers[0] != "a.iana-servers.net" || nameservers[1] != "b.iana-servers.net" {
+		t.Errorf("Unexpected nameservers: %v", nameservers)
+	}
+}
+
+func TestDomainAge(t *testing.T) {
+	registered := time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+	client := newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "example.com",
+		"events": [{"eventAction": "registration", "eventDate": "`+registered+`"}]
+	}`))
+
+	age, err := client.DomainAge("example.com")
+	if err != nil {
+		t.Fatalf("DomainAge failed: %v", err)
+	}
+	if age < 71*time.Hour || age > 73*time.Hour {
+		t.Errorf("Expected age of about 72h, got %v", age)
+	}
+}
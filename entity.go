@@ -0,0 +1,91 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entity performs RDAP query for the given entity handle and returns raw JSON.
+// The registry is selected from the RFC 8521 object tag suffix of the handle,
+// e.g. "ABC123-ARIN" is routed to the server registered for the "ARIN" tag.
+func (c *Client) Entity(handle string) (result []byte, err error) {
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return nil, fmt.Errorf("handle cannot be empty")
+	}
+
+	// Get the appropriate RDAP server for this handle
+	server, err := c.getEntityServer(handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", handle, err)
+	}
+
+	return c.doQuery(objectURL(server, "entity", handle))
+}
+
+// getEntityServer determines the appropriate RDAP server for an entity handle
+func (c *Client) getEntityServer(handle string) (string, error) {
+	if c.provider != nil {
+		return c.providerServer(ObjectEntity, handle)
+	}
+
+	tag := getObjectTag(handle)
+	if tag == "" {
+		return "", fmt.Errorf("handle has no object tag: %s", handle)
+	}
+
+	bootstrap, err := c.loadBootstrap(c.tagsBootstrapURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+	}
+
+	server, err := c.findServerForTag(tag, bootstrap)
+	if err != nil {
+		return "", fmt.Errorf("no RDAP server found for tag %s: %w", tag, err)
+	}
+
+	return server, nil
+}
+
+// findServerForTag finds the RDAP server registered for the given object tag.
+// Object tag services carry an extra leading element with registrant contacts.
+func (c *Client) findServerForTag(tag string, bootstrap *RDAPBootstrap) (string, error) {
+	return bootstrap.serverForTag(tag)
+}
+
+// serverForTag finds the RDAP server registered for the given object tag
+func (b *RDAPBootstrap) serverForTag(tag string) (string, error) {
+	if servers := b.lookupIndex().lookup(tag); len(servers) > 0 {
+		// Use the first server in the list
+		return servers[0], nil
+	}
+
+	return "", fmt.Errorf("no server found for tag: %s", tag)
+}
+
+// getObjectTag extracts the RFC 8521 object tag from an entity handle
+func getObjectTag(handle string) string {
+	i := strings.LastIndex(handle, "-")
+	if i <= 0 || i == len(handle)-1 {
+		return ""
+	}
+	return handle[i+1:]
+}
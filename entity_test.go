@@ -0,0 +1,113 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetObjectTag(t *testing.T) {
+	tests := []struct {
+		handle   string
+		expected string
+	}{
+		{"ABC123-ARIN", "ARIN"},
+		{"XYZ-1-RIPE", "RIPE"},
+		{"NOTAG", ""},
+		{"-ARIN", ""},
+		{"ABC123-", ""},
+	}
+
+	for _, test := range tests {
+		result := getObjectTag(test.handle)
+		if result != test.expected {
+			t.Errorf("getObjectTag(%s) = %s, expected %s", test.handle, result, test.expected)
+		}
+	}
+}
+
+func TestFindServerForTag(t *testing.T) {
+	client := NewClient()
+
+	bootstrap := &RDAPBootstrap{
+		Services: [][][]string{
+			{
+				{"andy@arin.net"},
+				{"ARIN"},
+				{"https://rdap.arin.net/registry/", "http://rdap.arin.net/registry/"},
+			},
+			{
+				{"rdap@ripe.net"},
+				{"RIPE"},
+				{"https://rdap.db.ripe.net"},
+			},
+		},
+	}
+
+	server, err := client.findServerForTag("ARIN", bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for ARIN: %v", err)
+	}
+	if server != "https://rdap.arin.net/registry/" {
+		t.Errorf("Expected first ARIN server, got %s", server)
+	}
+
+	server, err = client.findServerForTag("ripe", bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for ripe: %v", err)
+	}
+	if server != "https://rdap.db.ripe.net/" {
+		t.Errorf("Expected RIPE server, got %s", server)
+	}
+
+	if _, err := client.findServerForTag("UNKNOWN", bootstrap); err == nil {
+		t.Error("Expected error for unknown tag")
+	}
+}
+
+func TestEntityWithMockServer(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/entity/ABC123-ARIN" {
+			t.Errorf("Expected path /entity/ABC123-ARIN, got %s", r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"objectClassName": "entity",
+			"handle":          "ABC123-ARIN",
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"andy@arin.net"},
+					{"ARIN"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetObjectTagsBootstrapURL(bootstrapServer.URL)
+
+	result, err := client.Entity("ABC123-ARIN")
+	if err != nil {
+		t.Fatalf("Entity query failed: %v", err)
+	}
+	if !strings.Contains(string(result), "ABC123-ARIN") {
+		t.Errorf("Expected entity handle in response, got: %s", result)
+	}
+
+	_, err = client.Entity("NOTAG")
+	if err == nil || !strings.Contains(err.Error(), "handle has no object tag") {
+		t.Errorf("Expected error about missing tag, got: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrNotFound is matched by errors.Is when an RDAP server answers 404 for the queried object
+var ErrNotFound = errors.New("object not found")
+
+// ErrInsecureServer is matched by errors.Is when a query is refused because
+// the RDAP server is plain http and insecure servers are not allowed
+var ErrInsecureServer = errors.New("insecure RDAP server")
+
+// ErrNoRDAPService is matched by errors.Is when a TLD has no RDAP service in
+// the bootstrap data, so callers can fall back to WHOIS
+var ErrNoRDAPService = errors.New("no RDAP service")
+
+// ErrNoServerForTLD is matched by errors.Is along with ErrNoRDAPService when
+// the bootstrap data registers no RDAP server for the TLD of a query
+var ErrNoServerForTLD = errors.New("no RDAP server for TLD")
+
+// ErrBootstrapUnavailable is matched by errors.Is when the bootstrap data
+// could not be loaded from any of its sources
+var ErrBootstrapUnavailable = errors.New("failed to get bootstrap data")
+
+// ErrInvalidDomain is matched by errors.Is when a domain name is empty or
+// has no TLD
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ErrDomainNotFound is matched by errors.Is along with ErrNotFound when the
+// registry answers that the queried domain doesn't exist
+var ErrDomainNotFound = errors.New("domain not found")
+
+// ErrOffline is matched by errors.Is when a lookup needs the network while
+// the client is in offline mode
+var ErrOffline = errors.New("offline mode")
+
+// ErrRateLimited is matched by errors.Is when an RDAP server answers 429;
+// the *StatusError carries the wait asked by the server in RetryAfter
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrCircuitOpen is matched by errors.Is when a query is not sent because the
+// circuit breaker of the RDAP server is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// ErrTooManyRedirects is matched by errors.Is when a query was redirected
+// more often than allowed by SetMaxRedirects
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// ErrRedirectLoop is matched by errors.Is when a query was redirected back
+// to a URL it already visited
+var ErrRedirectLoop = errors.New("redirect loop")
+
+// ErrRedirectDenied is matched by errors.Is when the redirect policy refused
+// to follow a redirect
+var ErrRedirectDenied = errors.New("redirect denied")
+
+// ErrServerDenied is matched by errors.Is when a server URL or the address
+// it resolves to is refused by the policy set with SetServerPolicy
+var ErrServerDenied = errors.New("server denied by policy")
+
+// ErrNonRDAPResponse is matched by errors.Is when a server answers a query
+// with an HTML page, such as a maintenance page or a firewall block, instead
+// of RDAP JSON
+var ErrNonRDAPResponse = errors.New("non-RDAP response")
+
+// ErrDomainMismatch is matched by errors.Is when a domain response names
+// another domain than the queried one, with SetVerifyDomainName enabled
+var ErrDomainMismatch = errors.New("response names another domain")
+
+// ErrResponseTooLarge is matched by errors.Is when a response body exceeds
+// the limit set with SetMaxResponseSize
+var ErrResponseTooLarge = errors.New("response too large")
+
+// maxQueryErrorBody bounds the response body kept in a QueryError
+const maxQueryErrorBody = 512
+
+// QueryError is returned by failed domain lookups with the context of the
+// failure. It wraps the cause, so the sentinel errors and *StatusError still
+// match with errors.Is and errors.As, and a not found answer also matches
+// ErrDomainNotFound.
+type QueryError struct {
+	// Domain is the normalized domain that was queried
+	Domain string
+	// Server is the RDAP base URL of the last request, empty when none was sent
+	Server string
+	// URL is the URL of the last request, or the one that answered
+	URL string
+	// StatusCode is the HTTP status of the answer, zero without one
+	StatusCode int
+	// Attempts counts the requests sent for the lookup, across rate limit
+	// retries, failover and fallbacks; zero when served from the cache
+	Attempts int
+	// Body is the start of the response body, truncated to 512 bytes
+	Body string
+	// Err is the cause of the failure
+	Err error
+}
+
+// Error returns the message of the cause prefixed with the domain and server
+func (e *QueryError) Error() string {
+	msg := "RDAP query for " + e.Domain
+	if e.Server != "" {
+		msg += " to " + e.Server
+	}
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the failure
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrDomainNotFound and the lookup got a not found answer
+func (e *QueryError) Is(target error) bool {
+	return target == ErrDomainNotFound && errors.Is(e.Err, ErrNotFound)
+}
+
+// queryTrace records the requests sent for a domain lookup
+type queryTrace struct {
+	mu       sync.Mutex
+	attempts int
+	last     RequestInfo
+}
+
+// queryTraceKey is the context key of the trace of a lookup
+type queryTraceKey struct{}
+
+// withQueryTrace returns a context recording the requests of a lookup in trace
+func withQueryTrace(ctx context.Context, trace *queryTrace) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, trace)
+}
+
+// traceRequest records a request in the trace of its lookup, if any
+func traceRequest(info RequestInfo) {
+	trace, ok := info.Context.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	trace.attempts++
+	trace.last = info
+}
+
+// queryError returns the error of a domain lookup as a *QueryError, filled
+// from the trace of the lookup and its last response
+func queryError(domain string, resp *Response, err error, trace *queryTrace) error {
+	if err == nil {
+		return nil
+	}
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return err
+	}
+
+	queryErr = &QueryError{Domain: domain, Err: err}
+	if trace != nil {
+		trace.mu.Lock()
+		queryErr.Attempts = trace.attempts
+		queryErr.Server = trace.last.Server
+		queryErr.URL = trace.last.URL
+		trace.mu.Unlock()
+	}
+	var body []byte
+	var statusErr *StatusError
+	switch {
+	case resp != nil:
+		if resp.Server != "" {
+			queryErr.Server = resp.Server
+		}
+		queryErr.URL = resp.URL
+		queryErr.StatusCode = resp.StatusCode
+		body = resp.Body
+	case errors.As(err, &statusErr):
+		queryErr.StatusCode = statusErr.StatusCode
+		body = []byte(statusErr.Body)
+	}
+	if len(body) > maxQueryErrorBody {
+		body = body[:maxQueryErrorBody]
+	}
+	queryErr.Body = strings.ToValidUTF8(string(body), "")
+	return queryErr
+}
+
+// StatusError is returned when an RDAP server answers with a non-success status
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the wait asked by the Retry-After header of a 429 or 503
+	// response, zero when the server didn't say
+	RetryAfter time.Duration
+}
+
+// Error returns the error message including the response body
+func (e *StatusError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("RDAP query failed with status %d (retry after %s): %s", e.StatusCode, e.RetryAfter, e.Body)
+	}
+	return fmt.Sprintf("RDAP query failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether the status error matches the target sentinel error
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsRetryable reports whether a lookup that failed with err may succeed when
+// retried later: timeouts, network failures such as a connection reset or
+// a temporary DNS failure, an open circuit, and 408, 429 and 5xx answers
+// other than 501. Other errors, such as a 404, 400 or 422 answer, an unknown
+// host, a refused server or a cancelled context, are permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		case http.StatusNotImplemented:
+			return false
+		}
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrServerDenied), errors.Is(err, ErrInsecureServer),
+		errors.Is(err, ErrOffline), errors.Is(err, ErrNoRDAPService), errors.Is(err, ErrResponseTooLarge),
+		errors.Is(err, ErrTooManyRedirects), errors.Is(err, ErrRedirectLoop), errors.Is(err, ErrRedirectDenied):
+		return false
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrCircuitOpen):
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -0,0 +1,164 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusNotFound, Body: "not found"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("Expected 404 status error to match ErrNotFound")
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected StatusError to be extractable, got %v", err)
+	}
+
+	if errors.Is(&StatusError{StatusCode: http.StatusInternalServerError}, ErrNotFound) {
+		t.Error("Expected 500 status error not to match ErrNotFound")
+	}
+}
+
+func TestQueryRDAPNotFound(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	client := NewClient()
+	_, err := client.queryRDAP("example.com", mockServer.URL+"/")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"nil", nil, false},
+		{"404", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"400", &StatusError{StatusCode: http.StatusBadRequest}, false},
+		{"422", &StatusError{StatusCode: http.StatusUnprocessableEntity}, false},
+		{"408", &StatusError{StatusCode: http.StatusRequestTimeout}, true},
+		{"429", fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusTooManyRequests}), true},
+		{"501", &StatusError{StatusCode: http.StatusNotImplemented}, false},
+		{"503", &StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"timeout", fmt.Errorf("RDAP query failed: %w", context.DeadlineExceeded), true},
+		{"cancelled", context.Canceled, false},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"temporary DNS failure", &net.DNSError{Err: "server misbehaving", Name: "rdap.example", IsTemporary: true}, true},
+		{"unknown host", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "rdap.example", IsNotFound: true}}, false},
+		{"circuit open", ErrCircuitOpen, true},
+		{"denied dial", &net.OpError{Op: "dial", Err: ErrServerDenied}, false},
+		{"insecure server", ErrInsecureServer, false},
+		{"no RDAP service", ErrNoRDAPService, false},
+		{"invalid input", errors.New("invalid domain: example"), false},
+		{"invalid domain", fmt.Errorf("%w: example", ErrInvalidDomain), false},
+	}
+	for _, test := range tests {
+		if got := IsRetryable(test.err); got != test.retryable {
+			t.Errorf("IsRetryable(%s) = %v, expected %v", test.name, got, test.retryable)
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.RDAP("missing.com")
+	if !errors.Is(err, ErrDomainNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrDomainNotFound and ErrNotFound, got %v", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a *StatusError, got %v", err)
+	}
+
+	// A cached not found answer matches too
+	if _, err := client.RDAP("missing.com"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Expected ErrDomainNotFound from the cache, got %v", err)
+	}
+
+	_, err = client.RDAP("example.de")
+	if !errors.Is(err, ErrNoServerForTLD) || !errors.Is(err, ErrNoRDAPService) {
+		t.Errorf("Expected ErrNoServerForTLD and ErrNoRDAPService, got %v", err)
+	}
+
+	for _, domain := range []string{"", "localhost"} {
+		if _, err := client.RDAP(domain); !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("Expected ErrInvalidDomain for %q, got %v", domain, err)
+		}
+	}
+}
+
+func TestBootstrapUnavailableError(t *testing.T) {
+	client := NewClient().SetBootstrapURL("http://127.0.0.1:0/dns.json").SetEmbeddedFallback(false)
+
+	_, err := client.RDAP("example.com")
+	if !errors.Is(err, ErrBootstrapUnavailable) {
+		t.Errorf("Expected ErrBootstrapUnavailable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "failed to get bootstrap data") {
+		t.Errorf("Expected the bootstrap failure in the message, got %v", err)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(strings.Repeat("x", 2*maxQueryErrorBody)))
+	}))
+	defer secondary.Close()
+
+	_, err := newFailoverClient(primary, secondary).RDAP("Example.COM")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("Expected a *QueryError, got %v", err)
+	}
+	if queryErr.Domain != "example.com" || queryErr.Server != secondary.URL+"/" || queryErr.URL != secondary.URL+"/domain/example.com" {
+		t.Errorf("Unexpected query context: %+v", queryErr)
+	}
+	if queryErr.StatusCode != http.StatusBadGateway || queryErr.Attempts != 2 || len(queryErr.Body) != maxQueryErrorBody {
+		t.Errorf("Expected the 502 after 2 attempts with a truncated body, got %d after %d with %d bytes", queryErr.StatusCode, queryErr.Attempts, len(queryErr.Body))
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the wrapped *StatusError, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "RDAP query for example.com to "+secondary.URL+"/ after 2 attempts: ") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestQueryErrorNetwork(t *testing.T) {
+	client := NewClient().SetBootstrapData([]byte(`{"services": [[["com"], ["http://127.0.0.1:0/"]]]}`)).SetAllowInsecureServers(true)
+
+	_, err := client.RDAP("example.com")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("Expected a *QueryError, got %v", err)
+	}
+	if queryErr.Server != "http://127.0.0.1:0/" || queryErr.StatusCode != 0 || queryErr.Attempts != 1 {
+		t.Errorf("Unexpected query context: %+v", queryErr)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+)
+
+// serverCooldown is how long a failing RDAP server is tried last
+const serverCooldown = time.Minute
+
+// latencyWeight is the weight of the latest query in the moving averages of
+// the latency and error rate of a server
+const latencyWeight = 0.3
+
+// ServerStatus is the health of an RDAP server, as seen by the queries of the client
+type ServerStatus struct {
+	URL string
+	// Failures is the number of consecutive failed queries
+	Failures    int
+	LastError   error
+	LastFailure time.Time
+	LastSuccess time.Time
+	// OpenUntil is when the open circuit of the server lets a trial query
+	// through, zero when the circuit is closed
+	OpenUntil time.Time
+	// Latency is the moving average of the response time of answered queries
+	Latency time.Duration
+	// ErrorRate is the moving average of the share of failed queries
+	ErrorRate float64
+
+	probedAt time.Time
+}
+
+// Healthy reports whether the last query to the server got an answer
+func (s ServerStatus) Healthy() bool {
+	return s.Failures == 0
+}
+
+// CircuitOpen reports whether queries to the server fail fast
+func (s ServerStatus) CircuitOpen() bool {
+	return time.Now().Before(s.OpenUntil)
+}
+
+// SetCircuitBreaker opens the circuit of an RDAP server after threshold
+// consecutive failed queries: for cooldown, queries to it fail fast with
+// ErrCircuitOpen, so domain queries go to the next server of the TLD or the
+// fallback aggregator instead. After the cooldown a single trial query is
+// let through, which closes the circuit on success or reopens it. A zero
+// threshold, the default, disables the breaker.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) *Client {
+	c.breakerThreshold = max(threshold, 0)
+	c.breakerCooldown = cooldown
+	return c
+}
+
+// SetAdaptiveServerSelection orders the servers registered for a TLD by
+// their observed latency, weighted by their error rate, so a TLD served
+// from several continents is queried at the nearest answering server.
+// Servers not measured yet are tried first, and every probeInterval a
+// slower server is tried first once to refresh its measurement. A zero
+// interval, the default, keeps the bootstrap or selector order.
+func (c *Client) SetAdaptiveServerSelection(probeInterval time.Duration) *Client {
+	c.probeInterval = max(probeInterval, 0)
+	return c
+}
+
+// ServerHealth returns the health of every RDAP server queried by the client, sorted by URL
+func (c *Client) ServerHealth() []ServerStatus {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	statuses := make([]ServerStatus, 0, len(c.serverHealth))
+	for _, health := range c.serverHealth {
+		statuses = append(statuses, *health)
+	}
+	slices.SortFunc(statuses, func(a, b ServerStatus) int {
+		if a.URL < b.URL {
+			return -1
+		}
+		if a.URL > b.URL {
+			return 1
+		}
+		return 0
+	})
+	return statuses
+}
+
+// orderServers returns the servers to try, those that failed within the
+// cooldown moved to the end
+func (c *Client) orderServers(servers []string) []string {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	var healthy, failing []string
+	for _, server := range servers {
+		health, ok := c.serverHealth[server]
+		if ok && health.Failures > 0 && time.Since(health.LastFailure) < serverCooldown {
+			failing = append(failing, server)
+			continue
+		}
+		healthy = append(healthy, server)
+	}
+	if c.probeInterval > 0 && len(healthy) > 1 {
+		c.rankServers(healthy)
+	}
+	return append(healthy, failing...)
+}
+
+// rankServers sorts healthy servers by expected latency, unmeasured servers
+// first, then moves a server due for a probe to the front. The caller holds
+// healthMu.
+func (c *Client) rankServers(servers []string) {
+	slices.SortStableFunc(servers, func(a, b string) int {
+		return cmp.Compare(c.expectedLatency(a), c.expectedLatency(b))
+	})
+
+	now := time.Now()
+	for i, server := range servers[1:] {
+		health, ok := c.serverHealth[server]
+		if !ok || now.Sub(health.LastSuccess) < c.probeInterval || now.Sub(health.probedAt) < c.probeInterval {
+			continue
+		}
+		// Concurrent lookups don't all probe the same server
+		health.probedAt = now
+		copy(servers[1:i+2], servers[:i+1])
+		servers[0] = server
+		return
+	}
+}
+
+// expectedLatency returns the time a server is expected to take to answer,
+// counting the queries that fail, zero when it was not measured yet. The
+// caller holds healthMu.
+func (c *Client) expectedLatency(server string) time.Duration {
+	health, ok := c.serverHealth[server]
+	if !ok || health.Latency == 0 {
+		return 0
+	}
+	if health.ErrorRate >= 1 {
+		return math.MaxInt64
+	}
+	return time.Duration(float64(health.Latency) / (1 - health.ErrorRate))
+}
+
+// allowServer reports whether a query may be sent to the server, claiming
+// the trial query of an open circuit whose cooldown is over
+func (c *Client) allowServer(server string) bool {
+	if c.breakerThreshold == 0 {
+		return true
+	}
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	health, ok := c.serverHealth[server]
+	if !ok || health.Failures < c.breakerThreshold {
+		return true
+	}
+	now := time.Now()
+	if now.Before(health.OpenUntil) {
+		return false
+	}
+	// Hold other queries off while the trial query is in flight
+	health.OpenUntil = now.Add(c.breakerCooldown)
+	return true
+}
+
+// recordServer records the outcome of a query to a server. Answers such as
+// a 404 count as successes; only failures to get an answer count against it.
+// A query given up because ctx was cancelled or expired says nothing about
+// the server and is not recorded.
+func (c *Client) recordServer(ctx context.Context, server string, result *Response, err error) {
+	if ctx.Err() != nil && isContextError(err) {
+		return
+	}
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if c.serverHealth == nil {
+		c.serverHealth = make(map[string]*ServerStatus)
+	}
+	health, ok := c.serverHealth[server]
+	if !ok {
+		health = &ServerStatus{URL: server}
+		c.serverHealth[server] = health
+	}
+	if isQueryFailure(err) {
+		health.ErrorRate += latencyWeight * (1 - health.ErrorRate)
+		health.Failures++
+		health.LastError = err
+		health.LastFailure = time.Now()
+		if c.breakerThreshold > 0 && health.Failures >= c.breakerThreshold {
+			health.OpenUntil = health.LastFailure.Add(c.breakerCooldown)
+		}
+		return
+	}
+	health.ErrorRate -= latencyWeight * health.ErrorRate
+	if result != nil && result.Duration > 0 {
+		if health.Latency == 0 {
+			health.Latency = result.Duration
+		} else {
+			health.Latency += time.Duration(latencyWeight * float64(result.Duration-health.Latency))
+		}
+	}
+	health.Failures = 0
+	health.LastError = nil
+	health.LastSuccess = time.Now()
+	health.OpenUntil = time.Time{}
+}
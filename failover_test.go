@@ -0,0 +1,278 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFailoverClient returns a client whose bootstrap lists the given servers for .com
+func newFailoverClient(servers ...*httptest.Server) *Client {
+	bootstrap := `{"services": [[["com"], [`
+	for i, server := range servers {
+		if i > 0 {
+			bootstrap += ", "
+		}
+		bootstrap += `"` + server.URL + `/"`
+	}
+	bootstrap += `]]]}`
+	return NewClient().SetBootstrapData([]byte(bootstrap)).SetDisableCache(true)
+}
+
+func TestServerFailover(t *testing.T) {
+	var primaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	defer secondary.Close()
+
+	client := newFailoverClient(primary, secondary)
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("Expected failover to the secondary server, got: %v", err)
+	}
+	if resp.Server != secondary.URL+"/" {
+		t.Errorf("Expected answer from %s, got %s", secondary.URL, resp.Server)
+	}
+
+	statuses := client.ServerHealth()
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 server statuses, got %d", len(statuses))
+	}
+	for _, status := range statuses {
+		switch status.URL {
+		case primary.URL + "/":
+			if status.Healthy() || status.Failures != 1 || status.LastError == nil {
+				t.Errorf("Expected primary to be unhealthy, got %+v", status)
+			}
+		case secondary.URL + "/":
+			if !status.Healthy() || status.LastSuccess.IsZero() {
+				t.Errorf("Expected secondary to be healthy, got %+v", status)
+			}
+		}
+	}
+
+	// The failing primary is tried after the healthy secondary during its cooldown
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got := primaryHits.Load(); got != 1 {
+		t.Errorf("Expected failing primary to be skipped, got %d requests", got)
+	}
+}
+
+func TestServerFailoverNotFound(t *testing.T) {
+	var secondaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondaryHits.Add(1)
+	}))
+	defer secondary.Close()
+
+	// A 404 is an answer and is not retried on the next server
+	client := newFailoverClient(primary, secondary)
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected not found error")
+	}
+	if got := secondaryHits.Load(); got != 0 {
+		t.Errorf("Expected no query to the secondary server, got %d", got)
+	}
+	if statuses := client.ServerHealth(); len(statuses) != 1 || !statuses[0].Healthy() {
+		t.Errorf("Expected the answering primary to be healthy, got %+v", statuses)
+	}
+}
+
+func TestServerFailoverAllFailing(t *testing.T) {
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	primary := httptest.NewServer(failing)
+	defer primary.Close()
+	secondary := httptest.NewServer(failing)
+	defer secondary.Close()
+
+	client := newFailoverClient(primary, secondary)
+	resp, err := client.RDAPResponse("example.com")
+	if err == nil {
+		t.Fatal("Expected error when every server fails")
+	}
+	if resp == nil || resp.Server != secondary.URL+"/" {
+		t.Errorf("Expected the response of the last server tried, got %+v", resp)
+	}
+	for _, status := range client.ServerHealth() {
+		if status.Healthy() {
+			t.Errorf("Expected %s to be unhealthy", status.URL)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer server.Close()
+
+	client := newFailoverClient(server).SetCircuitBreaker(2, 100*time.Millisecond)
+	for range 2 {
+		if _, err := client.RDAP("example.com"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected server error, got %v", err)
+		}
+	}
+
+	// The open circuit fails fast without querying the server
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected 2 requests, got %d", got)
+	}
+	if statuses := client.ServerHealth(); len(statuses) != 1 || !statuses[0].CircuitOpen() {
+		t.Errorf("Expected an open circuit, got %+v", statuses)
+	}
+
+	// After the cooldown a trial query closes the circuit again
+	healthy.Store(true)
+	time.Sleep(150 * time.Millisecond)
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Expected trial query to succeed, got %v", err)
+	}
+	if statuses := client.ServerHealth(); statuses[0].CircuitOpen() || !statuses[0].Healthy() {
+		t.Errorf("Expected a closed circuit, got %+v", statuses[0])
+	}
+}
+
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	var slow atomic.Bool
+	slow.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer server.Close()
+
+	client := newFailoverClient(server).SetCircuitBreaker(1, time.Minute)
+	for range 2 {
+		if _, err := client.RDAP("example.com", WithTimeout(20*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected the lookup to time out, got %v", err)
+		}
+	}
+	for _, status := range client.ServerHealth() {
+		if status.CircuitOpen() || status.Failures > 0 {
+			t.Errorf("Expected timed out lookups not to count against the server, got %+v", status)
+		}
+	}
+
+	slow.Store(false)
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Errorf("Expected the server to be queried, got %v", err)
+	}
+}
+
+func TestCircuitBreakerAllOpen(t *testing.T) {
+	var hits atomic.Int32
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	primary := httptest.NewServer(failing)
+	defer primary.Close()
+	secondary := httptest.NewServer(failing)
+	defer secondary.Close()
+
+	client := newFailoverClient(primary, secondary).SetCircuitBreaker(1, time.Minute)
+	if _, err := client.RDAP("example.com"); err == nil {
+		t.Fatal("Expected error when every server fails")
+	}
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Expected each server to be queried once, got %d requests", got)
+	}
+}
+
+func TestAdaptiveServerSelection(t *testing.T) {
+	var slowHits, fastHits atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slowHits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fastHits.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer fast.Close()
+
+	client := newFailoverClient(slow, fast).SetAdaptiveServerSelection(time.Hour)
+
+	// Both servers are measured before the fastest one is preferred
+	for i := 0; i < 5; i++ {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+	if slowHits.Load() != 1 || fastHits.Load() != 4 {
+		t.Errorf("Expected the fast server to be preferred, got %d slow and %d fast queries", slowHits.Load(), fastHits.Load())
+	}
+	for _, status := range client.ServerHealth() {
+		if status.Latency <= 0 {
+			t.Errorf("Expected a measured latency, got %+v", status)
+		}
+	}
+
+	// A server not measured within the probe interval is tried first once
+	client.healthMu.Lock()
+	client.serverHealth[slow.URL+"/"].LastSuccess = time.Now().Add(-2 * time.Hour)
+	client.healthMu.Unlock()
+	servers := []string{slow.URL + "/", fast.URL + "/"}
+	if got := client.orderServers(servers); got[0] != slow.URL+"/" {
+		t.Errorf("Expected the slow server to be probed, got %v", got)
+	}
+	if got := client.orderServers(servers); got[0] != fast.URL+"/" {
+		t.Errorf("Expected a single probe, got %v", got)
+	}
+}
+
+func TestAdaptiveServerSelectionErrorRate(t *testing.T) {
+	client := NewClient().SetAdaptiveServerSelection(time.Hour)
+	client.recordServer(context.Background(), "https://a/", &Response{Duration: 10 * time.Millisecond}, nil)
+	client.recordServer(context.Background(), "https://b/", &Response{Duration: 12 * time.Millisecond}, nil)
+
+	// A server failing often is expected to take longer to answer
+	client.recordServer(context.Background(), "https://a/", nil, errors.New("connection reset"))
+	client.recordServer(context.Background(), "https://a/", &Response{Duration: 10 * time.Millisecond}, nil)
+	if got := client.orderServers([]string{"https://a/", "https://b/"}); got[0] != "https://b/" {
+		t.Errorf("Expected the reliable server first, got %v", got)
+	}
+
+	// Without adaptive selection the given order is kept
+	client.SetAdaptiveServerSelection(0)
+	if got := client.orderServers([]string{"https://a/", "https://b/"}); got[0] != "https://a/" {
+		t.Errorf("Expected the given order, got %v", got)
+	}
+}
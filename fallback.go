@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"errors"
+	"net/http"
+)
+
+// DefaultFallbackAggregator is the rdap.org aggregator, which redirects
+// domain queries to the registry serving them
+const DefaultFallbackAggregator = "https://rdap.org/"
+
+// SetFallbackAggregator sets an RDAP aggregator queried for domains whose TLD
+// has no RDAP service in the bootstrap data, or whose registry server fails.
+// The aggregator is a base URL or a URL template containing "{domain}". An
+// empty URL disables the fallback, which is the default.
+func (c *Client) SetFallbackAggregator(url string) *Client {
+	if url != "" && !isURLTemplate(url) {
+		url = normalizeServer(url)
+	}
+	c.fallbackAggregator = url
+	return c
+}
+
+// shouldFallback reports whether a failed domain query is retried against the aggregator
+func (c *Client) shouldFallback(err error) bool {
+	if c.fallbackAggregator == "" || err == nil || errors.Is(err, ErrOffline) {
+		return false
+	}
+	return errors.Is(err, ErrNoRDAPService) || isQueryFailure(err)
+}
+
+// isQueryFailure reports whether a query error is a failure to get an answer,
+// such as a network error or a server error, rather than an answer
+func isQueryFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Client errors such as 404 are answers from the registry, not failures,
+	// except a rate limit that kept the registry from answering
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
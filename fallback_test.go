@@ -0,0 +1,69 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newMockAggregator returns an aggregator answering every domain query
+func newMockAggregator(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	aggregator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/domain/")
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "` + name + `"}`))
+	}))
+	t.Cleanup(aggregator.Close)
+	return aggregator
+}
+
+func TestFallbackAggregatorNoRDAPService(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	client := newMockDomainClient(t, serveJSON(`{}`)).SetFallbackAggregator(aggregator.URL)
+
+	resp, err := client.RDAPResponse("example.de")
+	if err != nil {
+		t.Fatalf("Expected aggregator fallback, got: %v", err)
+	}
+	if resp.Server != aggregator.URL+"/" || !strings.Contains(string(resp.Body), "example.de") {
+		t.Errorf("Unexpected fallback response from %s: %s", resp.Server, resp.Body)
+	}
+}
+
+func TestFallbackAggregatorServerFailure(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}).SetFallbackAggregator(aggregator.URL + "/domain/{domain}")
+
+	result, err := client.RDAP("example.com")
+	if err != nil {
+		t.Fatalf("Expected aggregator fallback, got: %v", err)
+	}
+	if !strings.Contains(string(result), "example.com") {
+		t.Errorf("Unexpected fallback response: %s", result)
+	}
+}
+
+func TestFallbackAggregatorNotFound(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).SetFallbackAggregator(aggregator.URL)
+
+	// A registry 404 is an answer and is not retried
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound without fallback, got %v", err)
+	}
+}
+
+func TestFallbackAggregatorDisabled(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{}`))
+
+	if _, err := client.RDAP("example.de"); !errors.Is(err, ErrNoRDAPService) {
+		t.Errorf("Expected ErrNoRDAPService without aggregator, got %v", err)
+	}
+}
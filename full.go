@@ -0,0 +1,113 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/ducksify/gordap/types"
+)
+
+// FullDomain holds the domain object of the registry along with the one of
+// the registrar it refers to, as thin registries such as .com and .net only
+// hold the registration data at the registrar
+type FullDomain struct {
+	Registry *Domain
+	// RegistrarDomain is the domain object of the registrar RDAP server, nil
+	// when the registry gave no related link or the registrar lookup failed
+	RegistrarDomain *Domain
+	// RegistrarURL is the related link followed, empty without one
+	RegistrarURL string
+	// RegistrarErr is the error of the registrar lookup
+	RegistrarErr error
+}
+
+// Merged returns the registry domain object completed with the entities of
+// the registrar response whose roles the registry doesn't list, such as the
+// registrant and the technical contact
+func (f *FullDomain) Merged() *Domain {
+	merged := *f.Registry
+	if f.RegistrarDomain == nil {
+		return &merged
+	}
+
+	merged.Entities = slices.Clone(f.Registry.Entities)
+	for _, entity := range f.RegistrarDomain.Entities {
+		if !slices.ContainsFunc(entity.Roles, func(role string) bool {
+			_, ok := types.FindEntityByRole(f.Registry.Entities, role)
+			return ok
+		}) {
+			merged.Entities = append(merged.Entities, entity)
+		}
+	}
+	return &merged
+}
+
+// DomainFull performs the RDAP query of the given domain at its registry,
+// then follows the "related" link of the response to the registrar RDAP
+// server. The registrar response is cached like the registry one. A failure
+// of the registrar lookup is reported in RegistrarErr without failing the
+// call, as the registry data is still valid. Headers given with WithHeader
+// are only sent to the registry.
+func (c *Client) DomainFull(domain string, opts ...RequestOption) (*FullDomain, error) {
+	resp, err := c.RDAPResponse(domain, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var registry Domain
+	if err := json.Unmarshal(resp.Body, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %w", err)
+	}
+	full := &FullDomain{Registry: &registry}
+
+	link, ok := registry.RelatedLink()
+	if !ok || link.Href == resp.URL {
+		return full, nil
+	}
+	full.RegistrarURL = link.Href
+
+	options := newRequestOptions(opts)
+	ctx, cancel := options.context()
+	defer cancel()
+	query := func() (*Response, error) {
+		return c.doRequestWithHeader(ctx, "", link.Href, nil)
+	}
+	key := registrarCacheKey(c.registrableDomain(normalizeDomain(domain)))
+	registrarResp, err := c.cachedQuery(ctx, key, options, query, nil)
+	if err != nil {
+		full.RegistrarErr = fmt.Errorf("failed to query registrar RDAP server %s: %w", link.Href, err)
+		return full, nil
+	}
+
+	var registrar Domain
+	if err := json.Unmarshal(registrarResp.Body, &registrar); err != nil {
+		full.RegistrarErr = fmt.Errorf("failed to parse registrar RDAP response: %w", err)
+		return full, nil
+	}
+	full.RegistrarDomain = &registrar
+	return full, nil
+}
+
+// registrarCacheKey returns the cache key of the registrar response of a domain
+func registrarCacheKey(domain string) string {
+	return "registrar/" + domain
+}
@@ -0,0 +1,93 @@
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newThinRegistryClient returns a client whose registry refers to the given registrar server
+func newThinRegistryClient(t *testing.T, registrar *httptest.Server) *Client {
+	t.Helper()
+	return newMockDomainClient(t, serveJSON(`{
+		"objectClassName": "domain",
+		"ldhName": "EXAMPLE.COM",
+		"entities": [{"objectClassName": "entity", "handle": "292", "roles": ["registrar"]}],
+		"links": [{"rel": "related", "type": "application/rdap+json", "href": "`+registrar.URL+`/domain/EXAMPLE.COM"}]
+	}`)).SetAllowInsecureServers(true)
+}
+
+func TestDomainFull(t *testing.T) {
+	var hits atomic.Int32
+	registrar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{
+			"objectClassName": "domain",
+			"ldhName": "example.com",
+			"entities": [
+				{"objectClassName": "entity", "handle": "REG-X", "roles": ["registrar"]},
+				{"objectClassName": "entity", "handle": "C-1", "roles": ["registrant"]}
+			]
+		}`))
+	}))
+	defer registrar.Close()
+
+	client := newThinRegistryClient(t, registrar)
+	full, err := client.DomainFull("example.com")
+	if err != nil {
+		t.Fatalf("DomainFull failed: %v", err)
+	}
+	if full.RegistrarErr != nil || full.RegistrarDomain == nil {
+		t.Fatalf("Expected the registrar response, got %v", full.RegistrarErr)
+	}
+	if full.RegistrarURL != registrar.URL+"/domain/EXAMPLE.COM" {
+		t.Errorf("Unexpected registrar URL %s", full.RegistrarURL)
+	}
+
+	// The registrant comes from the registrar, the registrar entity from the registry
+	merged := full.Merged()
+	if len(merged.Entities) != 2 || merged.Entities[0].Handle != "292" || merged.Entities[1].Handle != "C-1" {
+		t.Errorf("Unexpected merged entities: %+v", merged.Entities)
+	}
+	if len(full.Registry.Entities) != 1 {
+		t.Errorf("Expected the registry object to be left untouched, got %+v", full.Registry.Entities)
+	}
+
+	// The registrar response is cached
+	if _, err := client.DomainFull("example.com"); err != nil {
+		t.Fatalf("DomainFull failed: %v", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected the registrar response to be cached, got %d queries", got)
+	}
+}
+
+func TestDomainFullRegistrarFailure(t *testing.T) {
+	registrar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer registrar.Close()
+
+	full, err := newThinRegistryClient(t, registrar).DomainFull("example.com")
+	if err != nil {
+		t.Fatalf("Expected the registry data despite the registrar failure, got %v", err)
+	}
+	if full.RegistrarErr == nil || full.RegistrarDomain != nil {
+		t.Errorf("Expected the registrar failure to be reported, got %+v", full)
+	}
+	if merged := full.Merged(); len(merged.Entities) != 1 {
+		t.Errorf("Expected the registry entities, got %+v", merged.Entities)
+	}
+}
+
+func TestDomainFullWithoutRelatedLink(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	full, err := client.DomainFull("example.com")
+	if err != nil {
+		t.Fatalf("DomainFull failed: %v", err)
+	}
+	if full.RegistrarURL != "" || full.RegistrarDomain != nil || full.RegistrarErr != nil {
+		t.Errorf("Expected no registrar lookup, got %+v", full)
+	}
+}
@@ -0,0 +1,78 @@
+//go:build ignore
+
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// gen_bootstrap refreshes the embedded domain bootstrap with the current IANA
+// dns.json. Run it with go generate from the module root.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	url := flag.String("url", "https://data.iana.org/rdap/dns.json", "bootstrap file to download")
+	out := flag.String("o", "embedded/dns.json", "file to write")
+	flag.Parse()
+
+	if err := fetch(*url, *out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fetch downloads the bootstrap file and writes it once it parses
+func fetch(url, out string) error {
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	var bootstrap struct {
+		Publication string       `json:"publication"`
+		Services    [][][]string `json:"services"`
+	}
+	if err := json.Unmarshal(body, &bootstrap); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", url, err)
+	}
+	if bootstrap.Publication == "" || len(bootstrap.Services) == 0 {
+		return fmt.Errorf("%s is not a bootstrap file", url)
+	}
+
+	if err := os.WriteFile(out, body, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	log.Printf("wrote %s: %d services published %s", out, len(bootstrap.Services), bootstrap.Publication)
+	return nil
+}
@@ -1,3 +1,7 @@
 module github.com/ducksify/gordap
 
 go 1.24.3
+
+require golang.org/x/net v0.44.0
+
+require golang.org/x/text v0.29.0 // indirect
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkConcurrency is the number of servers CheckServers probes at once
+const checkConcurrency = 8
+
+// ServerCheck is the outcome of probing an RDAP server
+type ServerCheck struct {
+	URL string
+	// TLDs are the checked TLDs the server is registered for
+	TLDs []string
+	// StatusCode is the HTTP status of the probe, zero without an answer
+	StatusCode int
+	Latency    time.Duration
+	// Err is set when the server gave no answer, or a 5xx or 429 one
+	Err error
+}
+
+// Up reports whether the server answered the probe
+func (s ServerCheck) Up() bool {
+	return s.Err == nil
+}
+
+// CheckServers probes every RDAP server registered for the given TLDs, or
+// for every TLD of the bootstrap when none are given, with a request to its
+// help path. A server answering with a client error, such as one not
+// implementing help, is up. The probes bypass open circuits and update the
+// health reported by ServerHealth. The checks are sorted by URL; TLDs
+// without an RDAP server are returned as joined errors along with them.
+func (c *Client) CheckServers(ctx context.Context, tlds []string) ([]ServerCheck, error) {
+	var errs []error
+	all := len(tlds) == 0
+	if all {
+		bootstrap, err := c.getDNSBootstrap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+		}
+		tlds = bootstrap.ListTLDs()
+	}
+
+	// Each server is probed once, whatever the number of TLDs it serves
+	checks := make(map[string]*ServerCheck)
+	for _, tld := range tlds {
+		tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+		servers, err := c.getTLDServers(ctx, tld)
+		if err != nil {
+			if !all {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		for _, server := range servers {
+			check, ok := checks[server]
+			if !ok {
+				check = &ServerCheck{URL: server}
+				checks[server] = check
+			}
+			if !slices.Contains(check.TLDs, tld) {
+				check.TLDs = append(check.TLDs, tld)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, checkConcurrency)
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				c.checkServer(ctx, check)
+			case <-ctx.Done():
+				check.Err = ctx.Err()
+			}
+		}()
+	}
+	wg.Wait()
+
+	results := make([]ServerCheck, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, *check)
+	}
+	slices.SortFunc(results, func(a, b ServerCheck) int {
+		return strings.Compare(a.URL, b.URL)
+	})
+	return results, errors.Join(errs...)
+}
+
+// checkServer probes the help path of a server and records the outcome
+func (c *Client) checkServer(ctx context.Context, check *ServerCheck) {
+	start := time.Now()
+	resp, err := c.doRequestWithHeader(ctx, "", check.URL+"help", nil)
+	check.Latency = time.Since(start)
+	if resp != nil {
+		check.StatusCode = resp.StatusCode
+		check.Latency = resp.Duration
+	}
+	if isQueryFailure(err) {
+		check.Err = err
+	}
+	c.recordServer(ctx, check.URL, resp, err)
+}
@@ -0,0 +1,90 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckServers(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/help" {
+			t.Errorf("Expected a help probe, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"rdapConformance": ["rdap_level_0"]}`))
+	}))
+	defer up.Close()
+	noHelp := httptest.NewServer(http.NotFoundHandler())
+	defer noHelp.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	client := newFailoverClient(up, noHelp, down)
+	checks, err := client.CheckServers(context.Background(), []string{"COM", "xyz"})
+	if !errors.Is(err, ErrNoRDAPService) {
+		t.Errorf("Expected the TLD without server to be reported, got %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("Expected 3 server checks, got %+v", checks)
+	}
+
+	for _, check := range checks {
+		if len(check.TLDs) != 1 || check.TLDs[0] != "com" {
+			t.Errorf("Expected %s to serve com, got %v", check.URL, check.TLDs)
+		}
+		switch check.URL {
+		case up.URL + "/":
+			if !check.Up() || check.StatusCode != http.StatusOK || check.Latency <= 0 {
+				t.Errorf("Expected the server to be up, got %+v", check)
+			}
+		case noHelp.URL + "/":
+			if !check.Up() || check.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected a server without help to be up, got %+v", check)
+			}
+		case down.URL + "/":
+			if check.Up() || check.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("Expected the server to be down, got %+v", check)
+			}
+		}
+	}
+
+	// The probes feed the server health
+	for _, status := range client.ServerHealth() {
+		if healthy := status.URL != down.URL+"/"; status.Healthy() != healthy {
+			t.Errorf("Unexpected health for %s: %+v", status.URL, status)
+		}
+	}
+}
+
+func TestCheckServersAllTLDs(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{}`))
+	defer server.Close()
+
+	client := newFailoverClient(server)
+	checks, err := client.CheckServers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("CheckServers failed: %v", err)
+	}
+	if len(checks) != 1 || !checks[0].Up() {
+		t.Errorf("Expected the bootstrap server to be checked, got %+v", checks)
+	}
+}
+
+func TestCheckServersCancelled(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{}`))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checks, err := newFailoverClient(server).CheckServers(ctx, []string{"com"})
+	if err != nil {
+		t.Fatalf("CheckServers failed: %v", err)
+	}
+	if len(checks) != 1 || !errors.Is(checks[0].Err, context.Canceled) {
+		t.Errorf("Expected a cancelled check, got %+v", checks)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"time"
+)
+
+// Retry reasons reported by the OnRetry hook
+const (
+	RetryRateLimited = "rate limited"
+	RetryFailover    = "failover"
+	RetryFallback    = "fallback"
+)
+
+// RequestInfo describes an RDAP request sent to a server
+type RequestInfo struct {
+	Context context.Context
+	// Server is the RDAP base URL of the query, empty for requests outside
+	// of server selection such as a followed link
+	Server string
+	URL    string
+	// Attempt counts the sends of the request, starting at 1, as rate
+	// limited requests are sent again
+	Attempt int
+}
+
+// RetryInfo describes a query tried again after a failure
+type RetryInfo struct {
+	Context context.Context
+	// Reason is RetryRateLimited, RetryFailover or RetryFallback
+	Reason string
+	// Query is the URL sent again for a rate limit, the domain otherwise
+	Query string
+	// Next is the server or fallback source tried next
+	Next string
+	// Wait is the delay before a rate limited request is sent again
+	Wait time.Duration
+	// Err is the error of the failed attempt
+	Err error
+}
+
+// CacheHitInfo describes a lookup served from the response cache
+type CacheHitInfo struct {
+	Context  context.Context
+	Key      string
+	Response *Response
+	// Revalidated reports whether a stale entry was confirmed by a 304 Not Modified
+	Revalidated bool
+}
+
+// ServerSelectionInfo describes the registry server chosen for an attempt of a domain query
+type ServerSelectionInfo struct {
+	Context context.Context
+	Domain  string
+	Server  string
+	// Candidates lists the servers of the TLD in the order they are tried
+	Candidates []string
+	// Attempt is the index of Server in Candidates plus one
+	Attempt int
+}
+
+// Hooks holds callbacks run on the events of the queries of a Client, so
+// metrics, tracing or policies can be attached without wrapping it. Any of
+// them may be nil. Hooks run synchronously on the goroutine of the query
+// and must be safe for concurrent use.
+type Hooks struct {
+	// OnRequest runs before each send of an RDAP request
+	OnRequest func(info RequestInfo)
+	// OnResponse runs after each send with its response and error
+	OnResponse func(info RequestInfo, resp *Response, err error)
+	// OnRetry runs before a query is tried again
+	OnRetry func(info RetryInfo)
+	// OnCacheHit runs when a lookup is served from the response cache,
+	// stale copies included
+	OnCacheHit func(info CacheHitInfo)
+	// OnServerSelected runs before each registry server of a domain query is tried
+	OnServerSelected func(info ServerSelectionInfo)
+}
+
+// SetHooks sets the hooks of the client, replacing the previous ones
+func (c *Client) SetHooks(hooks Hooks) *Client {
+	c.hooks = hooks
+	return c
+}
+
+// onRequest runs the OnRequest hook
+func (c *Client) onRequest(info RequestInfo) {
+	if c.hooks.OnRequest != nil {
+		c.hooks.OnRequest(info)
+	}
+}
+
+// onResponse runs the OnResponse hook
+func (c *Client) onResponse(info RequestInfo, resp *Response, err error) {
+	if c.hooks.OnResponse != nil {
+		c.hooks.OnResponse(info, resp, err)
+	}
+}
+
+// onRetry runs the OnRetry hook
+func (c *Client) onRetry(info RetryInfo) {
+	if c.hooks.OnRetry != nil {
+		c.hooks.OnRetry(info)
+	}
+}
+
+// onCacheHit runs the OnCacheHit hook
+func (c *Client) onCacheHit(info CacheHitInfo) {
+	if c.hooks.OnCacheHit != nil {
+		c.hooks.OnCacheHit(info)
+	}
+}
+
+// onServerSelected runs the OnServerSelected hook
+func (c *Client) onServerSelected(info ServerSelectionInfo) {
+	if c.hooks.OnServerSelected != nil {
+		c.hooks.OnServerSelected(info)
+	}
+}
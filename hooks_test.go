@@ -0,0 +1,153 @@
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hookRecorder records the hook events of a client
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) add(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *hookRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, "\n")
+}
+
+// hooks returns hooks recording every event with the servers replaced by their names
+func (r *hookRecorder) hooks(names map[string]string) Hooks {
+	name := func(s string) string {
+		for url, name := range names {
+			s = strings.ReplaceAll(s, url, name)
+		}
+		return s
+	}
+	return Hooks{
+		OnRequest: func(info RequestInfo) {
+			r.add("request %s #%d", name(info.URL), info.Attempt)
+		},
+		OnResponse: func(info RequestInfo, resp *Response, err error) {
+			r.add("response %s #%d %d", name(info.URL), info.Attempt, resp.StatusCode)
+		},
+		OnRetry: func(info RetryInfo) {
+			r.add("retry %s %s", info.Reason, name(info.Next))
+		},
+		OnCacheHit: func(info CacheHitInfo) {
+			r.add("cache hit %s", info.Key)
+		},
+		OnServerSelected: func(info ServerSelectionInfo) {
+			r.add("server %s %d/%d", name(info.Server), info.Attempt, len(info.Candidates))
+		},
+	}
+}
+
+func TestHooksFailover(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(serveJSON(`{"objectClassName": "domain"}`))
+	defer secondary.Close()
+
+	var recorder hookRecorder
+	client := newFailoverClient(primary, secondary).
+		SetHooks(recorder.hooks(map[string]string{primary.URL: "primary", secondary.URL: "secondary"}))
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"server primary/ 1/2",
+		"request primary/domain/example.com #1",
+		"response primary/domain/example.com #1 503",
+		"retry failover secondary/",
+		"server secondary/ 2/2",
+		"request secondary/domain/example.com #1",
+		"response secondary/domain/example.com #1 200",
+	}, "\n")
+	if got := recorder.String(); got != want {
+		t.Errorf("Unexpected events:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHooksRateLimitRetry(t *testing.T) {
+	var queries atomic.Int32
+	var recorder hookRecorder
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if queries.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}).SetRateLimitRetry(time.Second)
+	client.SetHooks(Hooks{
+		OnRetry: func(info RetryInfo) {
+			recorder.add("retry %s", info.Reason)
+		},
+		OnRequest: func(info RequestInfo) {
+			if strings.Contains(info.URL, "/domain/") {
+				recorder.add("request #%d", info.Attempt)
+			}
+		},
+	})
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got := recorder.String(); got != "request #1\nretry rate limited\nrequest #2" {
+		t.Errorf("Unexpected events:\n%s", got)
+	}
+}
+
+func TestHooksCacheHit(t *testing.T) {
+	var recorder hookRecorder
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain"}`)).SetHooks(Hooks{
+		OnCacheHit: func(info CacheHitInfo) {
+			recorder.add("cache hit %s %v", info.Key, info.Response.Cached)
+		},
+	})
+
+	for range 2 {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+	}
+	if got := recorder.String(); got != "cache hit "+domainCacheKey("example.com")+" true" {
+		t.Errorf("Unexpected events:\n%s", got)
+	}
+}
+
+func TestHooksFallback(t *testing.T) {
+	aggregator := newMockAggregator(t)
+	var recorder hookRecorder
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}).SetFallbackAggregator(aggregator.URL).SetHooks(Hooks{
+		OnRetry: func(info RetryInfo) {
+			recorder.add("retry %s %s", info.Reason, info.Query)
+		},
+	})
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got := recorder.String(); got != "retry fallback example.com" {
+		t.Errorf("Unexpected events:\n%s", got)
+	}
+}
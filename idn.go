@@ -0,0 +1,48 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ToASCII returns the lowercase A-label (punycode) form of a domain name
+// without its trailing dot, as it is queried: "Bücher.de." is
+// "xn--bcher-kva.de". It fails for names that are not valid IDNs.
+func ToASCII(domain string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrInvalidDomain, domain, err)
+	}
+	return strings.ToLower(ascii), nil
+}
+
+// ToUnicode returns the U-label form of a domain name for display:
+// "xn--bcher-kva.de" is "bücher.de". It fails for names that are not valid
+// IDNs.
+func ToUnicode(domain string) (string, error) {
+	unicode, err := idna.Display.ToUnicode(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrInvalidDomain, domain, err)
+	}
+	return unicode, nil
+}
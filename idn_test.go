@@ -0,0 +1,61 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := map[string]string{
+		"Bücher.de.":       "xn--bcher-kva.de",
+		"XN--BCHER-KVA.de": "xn--bcher-kva.de",
+		"例え.jp":            "xn--r8jz45g.jp",
+		"example.com":      "example.com",
+	}
+	for input, want := range tests {
+		if got, err := ToASCII(input); err != nil || got != want {
+			t.Errorf("ToASCII(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ToASCII("exa mple.com"); !errors.Is(err, ErrInvalidDomain) {
+		t.Errorf("Expected ErrInvalidDomain, got %v", err)
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := map[string]string{
+		"xn--bcher-kva.de": "bücher.de",
+		"xn--r8jz45g.jp.":  "例え.jp",
+		"example.com":      "example.com",
+	}
+	for input, want := range tests {
+		if got, err := ToUnicode(input); err != nil || got != want {
+			t.Errorf("ToUnicode(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	if _, err := ToUnicode("xn--zz.com"); !errors.Is(err, ErrInvalidDomain) {
+		t.Errorf("Expected ErrInvalidDomain, got %v", err)
+	}
+}
+
+func TestIDNQuery(t *testing.T) {
+	var path string
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "xn--bcher-kva.com", "unicodeName": "bücher.com"}`))
+	})
+
+	domain, err := client.Domain("Bücher.COM")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if path != "/domain/xn--bcher-kva.com" {
+		t.Errorf("Expected the A-label to be queried, got %s", path)
+	}
+	if domain.DisplayName() != "bücher.com" {
+		t.Errorf("Unexpected display name: %s", domain.DisplayName())
+	}
+}
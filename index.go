@@ -0,0 +1,149 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// bootstrapIndex is the lookup structure built from the services of a
+// bootstrap file, so lookups do not scan every service
+type bootstrapIndex struct {
+	// entries maps lowercase TLDs and object tags to their servers
+	entries map[string][]string
+	// prefixes maps registered IP prefixes to their servers
+	prefixes map[netip.Prefix][]string
+	// prefixLengths holds the distinct registered prefix lengths, ascending
+	prefixLengths []int
+	// asnRanges holds the registered ASN ranges sorted by their low bound
+	asnRanges []asnRange
+}
+
+// asnRange is a registered ASN range with its servers
+type asnRange struct {
+	low, high uint32
+	servers   []string
+}
+
+// lookupIndex returns the index built when the bootstrap was parsed, or a
+// fresh one for a bootstrap that was constructed directly
+func (b *RDAPBootstrap) lookupIndex() *bootstrapIndex {
+	if b.index != nil {
+		return b.index
+	}
+	return newBootstrapIndex(b.Services)
+}
+
+// newBootstrapIndex indexes bootstrap services. Domain, IP and ASN services
+// have two elements, object tag services have a leading registrant contacts
+// element. The first service registering an entry wins.
+func newBootstrapIndex(services [][][]string) *bootstrapIndex {
+	index := &bootstrapIndex{
+		entries:  make(map[string][]string),
+		prefixes: make(map[netip.Prefix][]string),
+	}
+
+	for _, service := range services {
+		if len(service) < 2 || len(service) > 3 {
+			continue
+		}
+		entries := service[len(service)-2]
+		servers := normalizeServers(service[len(service)-1])
+		if len(servers) == 0 {
+			continue
+		}
+
+		for _, entry := range entries {
+			if len(service) == 2 {
+				if prefix, err := netip.ParsePrefix(entry); err == nil {
+					prefix = prefix.Masked()
+					if _, ok := index.prefixes[prefix]; !ok {
+						index.prefixes[prefix] = servers
+						index.prefixLengths = append(index.prefixLengths, prefix.Bits())
+					}
+					continue
+				}
+				if low, high, err := parseASNRange(entry); err == nil {
+					index.asnRanges = append(index.asnRanges, asnRange{low: low, high: high, servers: servers})
+					continue
+				}
+			}
+			key := strings.ToLower(entry)
+			if _, ok := index.entries[key]; !ok {
+				index.entries[key] = servers
+			}
+		}
+	}
+
+	slices.Sort(index.prefixLengths)
+	index.prefixLengths = slices.Compact(index.prefixLengths)
+	sort.SliceStable(index.asnRanges, func(i, j int) bool {
+		return index.asnRanges[i].low < index.asnRanges[j].low
+	})
+
+	return index
+}
+
+// lookup returns the servers registered for a TLD or object tag
+func (i *bootstrapIndex) lookup(entry string) []string {
+	return i.entries[strings.ToLower(entry)]
+}
+
+// lookupPrefix returns the servers of the most specific registered prefix
+// covering the given prefix
+func (i *bootstrapIndex) lookupPrefix(prefix netip.Prefix) []string {
+	for j := len(i.prefixLengths) - 1; j >= 0; j-- {
+		bits := i.prefixLengths[j]
+		if bits > prefix.Bits() {
+			continue
+		}
+		registered, err := prefix.Addr().Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if servers, ok := i.prefixes[registered]; ok {
+			return servers
+		}
+	}
+	return nil
+}
+
+// lookupASN returns the servers of the registered range containing the given ASN
+func (i *bootstrapIndex) lookupASN(asn uint32) []string {
+	// Find the first range starting after the ASN, the one before it may contain it
+	n := sort.Search(len(i.asnRanges), func(j int) bool {
+		return i.asnRanges[j].low > asn
+	})
+	if n > 0 && asn <= i.asnRanges[n-1].high {
+		return i.asnRanges[n-1].servers
+	}
+	return nil
+}
+
+// normalizeServers returns the server base URLs with a trailing slash
+func normalizeServers(servers []string) []string {
+	result := make([]string, len(servers))
+	for i, server := range servers {
+		result[i] = normalizeServer(server)
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package rdap
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestBootstrapIndex(t *testing.T) {
+	index := newBootstrapIndex([][][]string{
+		{{"com", "NET"}, {"https://rdap.verisign.com/com/v1"}},
+		{{"com"}, {"https://rdap.duplicate.example/"}},
+		{{"41.0.0.0/8", "2001:200::/23"}, {"https://rdap.afrinic.net/rdap/"}},
+		{{"1-1876", "36864-37887"}, {"https://rdap.arin.net/registry/"}},
+		{{"contact@arin.net"}, {"ARIN"}, {"https://rdap.arin.net/registry/"}},
+		{{"org"}, {}},
+	})
+
+	if got := index.lookup("net"); !slices.Equal(got, []string{"https://rdap.verisign.com/com/v1/"}) {
+		t.Errorf("Unexpected servers for net: %v", got)
+	}
+	if got := index.lookup("com"); !slices.Equal(got, []string{"https://rdap.verisign.com/com/v1/"}) {
+		t.Errorf("Expected the first service to win for com, got %v", got)
+	}
+	if got := index.lookup("arin"); len(got) != 1 {
+		t.Errorf("Unexpected servers for ARIN tag: %v", got)
+	}
+	if got := index.lookup("org"); got != nil {
+		t.Errorf("Expected service without servers to be skipped, got %v", got)
+	}
+
+	for _, prefix := range []string{"41.1.2.3/32", "41.0.0.0/16", "2001:200::1/128"} {
+		if got := index.lookupPrefix(netip.MustParsePrefix(prefix)); len(got) != 1 {
+			t.Errorf("Expected servers for %s, got %v", prefix, got)
+		}
+	}
+	for _, prefix := range []string{"40.0.0.0/7", "42.0.0.1/32", "2001:400::/32"} {
+		if got := index.lookupPrefix(netip.MustParsePrefix(prefix)); got != nil {
+			t.Errorf("Expected no servers for %s, got %v", prefix, got)
+		}
+	}
+
+	for asn, found := range map[uint32]bool{1: true, 1876: true, 1877: false, 36864: true, 37887: true, 40000: false} {
+		if got := index.lookupASN(asn); (got != nil) != found {
+			t.Errorf("lookupASN(%d) = %v, expected found %v", asn, got, found)
+		}
+	}
+}
+
+func BenchmarkServerForTLD(b *testing.B) {
+	services := make([][][]string, 0, 1500)
+	for i := 0; i < 1500; i++ {
+		services = append(services, [][]string{{fmt.Sprintf("tld%d", i)}, {fmt.Sprintf("https://rdap%d.example/", i)}})
+	}
+	bootstrap := &RDAPBootstrap{Services: services}
+	bootstrap.index = newBootstrapIndex(services)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := bootstrap.serverForTLD("tld1499"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
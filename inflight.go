@@ -0,0 +1,79 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"errors"
+	"sync"
+)
+
+// errQueryAborted is returned to callers waiting on a shared query that panicked
+var errQueryAborted = errors.New("shared RDAP query aborted")
+
+// queryCall is an in-flight query shared by concurrent identical lookups
+type queryCall struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// queryGroup coalesces concurrent identical queries into one upstream request
+type queryGroup struct {
+	mu    sync.Mutex
+	calls map[string]*queryCall
+}
+
+// do runs query once for concurrent callers with the same key. Waiting
+// callers get a copy of the first caller's response, sharing its body. When
+// the query panics, the panic is raised in the first caller only.
+func (g *queryGroup) do(key string, query func() (*Response, error)) (*Response, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		if call.resp == nil {
+			return nil, call.err
+		}
+		resp := *call.resp
+		return &resp, call.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*queryCall)
+	}
+	call := &queryCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	returned := false
+	defer func() {
+		if !returned {
+			// The query panicked: the panic goes on in this caller, and
+			// waiting callers get an error rather than no answer at all
+			call.resp, call.err = nil, errQueryAborted
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.resp, call.err = query()
+	returned = true
+	return call.resp, call.err
+}
@@ -0,0 +1,134 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentQueriesShareRequest(t *testing.T) {
+	var queries atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if queries.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}).SetCache(nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Domain("Example.com"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Domain failed: %v", err)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected concurrent lookups to share one request, got %d", got)
+	}
+
+	// Later lookups are not coalesced with finished ones
+	if _, err := client.Domain("example.com"); err != nil {
+		t.Fatalf("Domain failed: %v", err)
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected a new request after the shared one finished, got %d", got)
+	}
+}
+
+func TestQueryGroupSharesError(t *testing.T) {
+	var group queryGroup
+	errFailed := errors.New("failed")
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var calls atomic.Int32
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := group.do("key", func() (*Response, error) {
+				calls.Add(1)
+				<-release
+				return &Response{StatusCode: http.StatusBadGateway}, errFailed
+			})
+			results <- err
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for err := range results {
+		if !errors.Is(err, errFailed) {
+			t.Errorf("Expected shared error, got %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected one call, got %d", got)
+	}
+}
+
+func TestQueryGroupPanic(t *testing.T) {
+	var group queryGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		group.do("key", func() (*Response, error) {
+			close(started)
+			<-release
+			panic("query failed")
+		})
+	}()
+
+	<-started
+	waiter := make(chan error, 1)
+	go func() {
+		resp, err := group.do("key", func() (*Response, error) {
+			return &Response{StatusCode: http.StatusOK}, nil
+		})
+		if resp != nil {
+			err = errors.New("unexpected response")
+		}
+		waiter <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if got := <-panicked; got != "query failed" {
+		t.Errorf("Expected the panic to reach the first caller, got %v", got)
+	}
+	if err := <-waiter; !errors.Is(err, errQueryAborted) {
+		t.Errorf("Expected the waiting caller to get an error, got %v", err)
+	}
+
+	// The key is released for later queries
+	if _, err := group.do("key", func() (*Response, error) { return &Response{}, nil }); err != nil {
+		t.Errorf("Expected a new query to run, got %v", err)
+	}
+}
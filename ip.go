@@ -0,0 +1,120 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// IP performs RDAP query for the given IP address or CIDR prefix and returns raw JSON
+func (c *Client) IP(ip string) (result []byte, err error) {
+	ip = strings.TrimSpace(ip)
+	if ip == "" {
+		return nil, fmt.Errorf("ip cannot be empty")
+	}
+
+	prefix, err := parseIPQuery(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the appropriate RDAP server for this address
+	server, err := c.getIPServer(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", ip, err)
+	}
+
+	// A single address is queried as such, a network with its prefix length
+	return c.doQuery(objectURL(server, "ip", strings.Split(prefixQuery(prefix), "/")...))
+}
+
+// getIPServer determines the appropriate RDAP server for an IP prefix
+func (c *Client) getIPServer(prefix netip.Prefix) (string, error) {
+	if c.provider != nil {
+		return c.providerServer(ObjectIP, prefixQuery(prefix))
+	}
+
+	bootstrapURL := c.ipv4BootstrapURL
+	if prefix.Addr().Is6() {
+		bootstrapURL = c.ipv6BootstrapURL
+	}
+
+	bootstrap, err := c.loadBootstrap(bootstrapURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+	}
+
+	server, err := c.findServerForIP(prefix, bootstrap)
+	if err != nil {
+		return "", fmt.Errorf("no RDAP server found for IP %s: %w", prefix, err)
+	}
+
+	return server, nil
+}
+
+// findServerForIP finds the RDAP server of the longest registered prefix covering the given prefix
+func (c *Client) findServerForIP(prefix netip.Prefix, bootstrap *RDAPBootstrap) (string, error) {
+	return bootstrap.serverForIP(prefix)
+}
+
+// serverForIP finds the RDAP server of the longest registered prefix covering the given prefix
+func (b *RDAPBootstrap) serverForIP(prefix netip.Prefix) (string, error) {
+	if servers := b.lookupIndex().lookupPrefix(prefix); len(servers) > 0 {
+		// Use the first server in the list
+		return servers[0], nil
+	}
+
+	return "", fmt.Errorf("no server found for IP: %s", prefix)
+}
+
+// parseIPQuery parses an IP address or CIDR prefix into a masked prefix
+func parseIPQuery(ip string) (netip.Prefix, error) {
+	if strings.Contains(ip, "/") {
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP prefix: %s", ip)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	addr = addr.Unmap().WithZone("")
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// IPNetwork performs RDAP query for the given IP address or CIDR prefix and returns the parsed network object
+func (c *Client) IPNetwork(ip string) (*IPNetwork, error) {
+	body, err := c.IP(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	var result IPNetwork
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,183 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestParseIPQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"192.0.2.1", "192.0.2.1/32"},
+		{"192.0.2.1/24", "192.0.2.0/24"},
+		{"2001:db8::1", "2001:db8::1/128"},
+		{"2001:db8::/32", "2001:db8::/32"},
+		{"::ffff:192.0.2.1", "192.0.2.1/32"},
+	}
+
+	for _, test := range tests {
+		prefix, err := parseIPQuery(test.input)
+		if err != nil {
+			t.Errorf("parseIPQuery(%s) returned error: %v", test.input, err)
+			continue
+		}
+		if prefix.String() != test.expected {
+			t.Errorf("parseIPQuery(%s) = %s, expected %s", test.input, prefix, test.expected)
+		}
+	}
+
+	for _, input := range []string{"not-an-ip", "192.0.2.300", "192.0.2.0/33"} {
+		if _, err := parseIPQuery(input); err == nil {
+			t.Errorf("Expected error for invalid input %s", input)
+		}
+	}
+}
+
+func TestFindServerForIP(t *testing.T) {
+	client := NewClient()
+
+	bootstrap := &RDAPBootstrap{
+		Services: [][][]string{
+			{
+				{"41.0.0.0/8", "102.0.0.0/8"},
+				{"https://rdap.afrinic.net/rdap/"},
+			},
+			{
+				{"2001:200::/23"},
+				{"https://rdap.apnic.net"},
+			},
+		},
+	}
+
+	server, err := client.findServerForIP(netip.MustParsePrefix("41.57.96.1/32"), bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for IPv4 address: %v", err)
+	}
+	if server != "https://rdap.afrinic.net/rdap/" {
+		t.Errorf("Expected AFRINIC server, got %s", server)
+	}
+
+	server, err = client.findServerForIP(netip.MustParsePrefix("2001:200::/32"), bootstrap)
+	if err != nil {
+		t.Fatalf("Failed to find server for IPv6 prefix: %v", err)
+	}
+	if server != "https://rdap.apnic.net/" {
+		t.Errorf("Expected APNIC server with trailing slash, got %s", server)
+	}
+
+	// A prefix wider than any registered block must not match
+	if _, err := client.findServerForIP(netip.MustParsePrefix("40.0.0.0/7"), bootstrap); err == nil {
+		t.Error("Expected error for prefix not covered by bootstrap")
+	}
+}
+
+func TestIPWithMockServer(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ip/192.0.2.1" && r.URL.Path != "/ip/2001:db8::1" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"objectClassName": "ip network",
+			"handle":          "NET-192-0-2-0-1",
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	newBootstrap := func(prefix string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bootstrap := RDAPBootstrap{
+				Services: [][][]string{
+					{
+						{prefix},
+						{mockServer.URL + "/"},
+					},
+				},
+				Version: "1.0",
+			}
+			json.NewEncoder(w).Encode(bootstrap)
+		}))
+	}
+	ipv4Bootstrap := newBootstrap("192.0.0.0/8")
+	defer ipv4Bootstrap.Close()
+	ipv6Bootstrap := newBootstrap("2001:db8::/32")
+	defer ipv6Bootstrap.Close()
+
+	client := NewClient().
+		SetIPv4BootstrapURL(ipv4Bootstrap.URL).
+		SetIPv6BootstrapURL(ipv6Bootstrap.URL)
+
+	for _, ip := range []string{"192.0.2.1", "2001:db8::1"} {
+		result, err := client.IP(ip)
+		if err != nil {
+			t.Fatalf("IP query for %s failed: %v", ip, err)
+		}
+		if !strings.Contains(string(result), "ip network") {
+			t.Errorf("Expected ip network object, got: %s", result)
+		}
+	}
+
+	if _, err := client.IP("198.51.100.1"); err == nil {
+		t.Error("Expected error for address outside bootstrap")
+	}
+}
+
+func TestIPInvalidInput(t *testing.T) {
+	client := NewClient()
+
+	_, err := client.IP("")
+	if err == nil || !strings.Contains(err.Error(), "ip cannot be empty") {
+		t.Errorf("Expected error about empty IP, got: %v", err)
+	}
+
+	_, err = client.IP("example.com")
+	if err == nil || !strings.Contains(err.Error(), "invalid IP address") {
+		t.Errorf("Expected error about invalid IP, got: %v", err)
+	}
+}
+
+func TestFindServerForIPLongestPrefix(t *testing.T) {
+	client := NewClient()
+
+	bootstrap := &RDAPBootstrap{
+		Services: [][][]string{
+			{
+				{"41.0.0.0/8", "2001:200::/23"},
+				{"https://rdap.afrinic.net/rdap/"},
+			},
+			{
+				{"41.57.96.0/19", "2001:200:100::/40"},
+				{"https://rdap.nir.example/"},
+			},
+		},
+	}
+
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"41.57.100.1/32", "https://rdap.nir.example/"},
+		{"41.57.96.0/19", "https://rdap.nir.example/"},
+		{"41.57.96.0/18", "https://rdap.afrinic.net/rdap/"},
+		{"41.1.1.1/32", "https://rdap.afrinic.net/rdap/"},
+		{"2001:200:100::1/128", "https://rdap.nir.example/"},
+		{"2001:200:200::/48", "https://rdap.afrinic.net/rdap/"},
+	}
+	for _, test := range tests {
+		got, err := client.findServerForIP(netip.MustParsePrefix(test.prefix), bootstrap)
+		if err != nil {
+			t.Errorf("findServerForIP(%s) returned error: %v", test.prefix, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("findServerForIP(%s) = %s, expected %s", test.prefix, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheEntries is the number of responses the default cache holds
+	defaultCacheEntries = 1024
+	// defaultCacheBytes is the memory budget of the default cache
+	defaultCacheBytes = 64 << 20
+)
+
+// LRUCache is an in-memory Cache evicting the least recently used entry once
+// it holds its maximum number of entries or exceeds its memory budget. It is
+// safe for concurrent use.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	entries    map[string]*list.Element
+	order      *list.List
+	evictions  uint64
+}
+
+// lruEntry is a value stored in an LRUCache
+type lruEntry struct {
+	key     string
+	val     []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRU cache holding up to maxEntries responses. A
+// zero or negative maxEntries leaves the cache unbounded
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// SetMaxBytes sets the memory budget of the cache, counted as the size of the
+// stored keys and values. Least recently used entries are evicted to stay
+// within it, and values larger than the budget are not stored. A zero or
+// negative budget leaves the size unbounded
+func (l *LRUCache) SetMaxBytes(maxBytes int64) *LRUCache {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxBytes = maxBytes
+	l.evict()
+	return l
+}
+
+// Get returns the value stored under key, or false when it is missing or expired
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		l.remove(elem)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return append([]byte(nil), entry.val...), true
+}
+
+// Set stores the value under key for the given duration, evicting the least
+// recently used entry when the cache is full. A zero ttl never expires
+func (l *LRUCache) Set(key string, val []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	val = append([]byte(nil), val...)
+
+	if elem, ok := l.entries[key]; ok {
+		l.remove(elem)
+	}
+	entry := &lruEntry{key: key, val: val, expires: expires}
+	if l.maxBytes > 0 && entry.size() > l.maxBytes {
+		// Storing the value would evict everything else and still not fit
+		l.evictions++
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(entry)
+	l.size += entry.size()
+	l.evict()
+}
+
+// evict drops least recently used entries until the cache is within its limits
+func (l *LRUCache) evict() {
+	for l.order.Len() > 0 && (l.maxEntries > 0 && l.order.Len() > l.maxEntries || l.maxBytes > 0 && l.size > l.maxBytes) {
+		l.remove(l.order.Back())
+		l.evictions++
+	}
+}
+
+// Delete removes the value stored under key
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		l.remove(elem)
+	}
+}
+
+// Len returns the number of entries in the cache, including expired ones not yet evicted
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// Size returns the memory used by the cached keys and values, in bytes
+func (l *LRUCache) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// Evictions returns the number of entries evicted to make room for new ones
+func (l *LRUCache) Evictions() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.evictions
+}
+
+// Range calls fn with every unexpired entry, most recently used first, and
+// the time left before it expires, zero when it never does. It stops when
+// fn returns false.
+func (l *LRUCache) Range(fn func(key string, val []byte, ttl time.Duration) bool) {
+	l.mu.Lock()
+	type item struct {
+		key string
+		val []byte
+		ttl time.Duration
+	}
+	var items []item
+	now := time.Now()
+	for elem := l.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*lruEntry)
+		var ttl time.Duration
+		if !entry.expires.IsZero() {
+			if ttl = entry.expires.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+		items = append(items, item{entry.key, entry.val, ttl})
+	}
+	l.mu.Unlock()
+
+	// fn runs without the lock so it may use the cache
+	for _, it := range items {
+		if !fn(it.key, append([]byte(nil), it.val...), it.ttl) {
+			return
+		}
+	}
+}
+
+// Purge removes every entry from the cache
+func (l *LRUCache) Purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = make(map[string]*list.Element)
+	l.order.Init()
+	l.size = 0
+}
+
+// remove drops an element from the cache
+func (l *LRUCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	l.order.Remove(elem)
+	delete(l.entries, entry.key)
+	l.size -= entry.size()
+}
+
+// size returns the memory accounted for the entry
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.val))
+}
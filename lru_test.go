@@ -0,0 +1,144 @@
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("b", []byte("2"), 0)
+
+	// Touch a so that b is the least recently used entry
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+	cache.Set("c", []byte("3"), 0)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Expected 2 entries, got %d", got)
+	}
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	cache := NewLRUCache(0)
+	cache.Set("short", []byte("1"), time.Nanosecond)
+	cache.Set("forever", []byte("2"), 0)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected expired entry to be missing")
+	}
+	if val, ok := cache.Get("forever"); !ok || string(val) != "2" {
+		t.Errorf("Expected entry without TTL, got %q", val)
+	}
+}
+
+func TestLRUCacheUpdateAndDelete(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), 0)
+	cache.Set("a", []byte("2"), 0)
+	if val, _ := cache.Get("a"); string(val) != "2" {
+		t.Errorf("Expected updated value, got %q", val)
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected deleted entry to be missing")
+	}
+
+	cache.Set("b", []byte("3"), 0)
+	cache.Purge()
+	if got := cache.Len(); got != 0 {
+		t.Errorf("Expected empty cache after Purge, got %d entries", got)
+	}
+}
+
+func TestLRUCacheConcurrent(t *testing.T) {
+	cache := NewLRUCache(16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d-%d", i, j%32)
+				cache.Set(key, []byte(key), time.Minute)
+				cache.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := cache.Len(); got > 16 {
+		t.Errorf("Expected at most 16 entries, got %d", got)
+	}
+}
+
+func TestDefaultResponseCache(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+	for i := 0; i < 3; i++ {
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected the default cache to serve repeated lookups, got %d queries", got)
+	}
+
+	client.ClearCache()
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected a query after ClearCache, got %d queries", got)
+	}
+}
+
+func TestLRUCacheMaxBytes(t *testing.T) {
+	cache := NewLRUCache(0).SetMaxBytes(10)
+	cache.Set("a", []byte("1234"), 0) // 5 bytes
+	cache.Set("b", []byte("1234"), 0) // 10 bytes
+	cache.Set("c", []byte("12"), 0)   // evicts a
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected a to be evicted to stay within the budget")
+	}
+	if got := cache.Size(); got != 8 {
+		t.Errorf("Expected 8 bytes used, got %d", got)
+	}
+
+	cache.Set("b", []byte("1"), 0)
+	if got := cache.Size(); got != 5 {
+		t.Errorf("Expected replaced value to be accounted, got %d bytes", got)
+	}
+
+	cache.Set("huge", make([]byte, 64), 0)
+	if _, ok := cache.Get("huge"); ok {
+		t.Error("Expected a value larger than the budget not to be stored")
+	}
+	if got, evictions := cache.Len(), cache.Evictions(); got != 2 || evictions != 2 {
+		t.Errorf("Expected 2 entries and 2 evictions, got %d and %d", got, evictions)
+	}
+
+	cache.SetMaxBytes(3)
+	if got := cache.Size(); got > 3 {
+		t.Errorf("Expected shrinking the budget to evict, got %d bytes", got)
+	}
+	cache.Purge()
+	if got := cache.Size(); got != 0 {
+		t.Errorf("Expected empty cache after Purge, got %d bytes", got)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Middleware wraps the HTTP client sending the requests of a Client, for
+// example to add authentication, log requests or inject failures
+type Middleware func(next HTTPClient) HTTPClient
+
+// HTTPClientFunc adapts a function to the HTTPClient interface
+type HTTPClientFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f HTTPClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use adds middlewares around the HTTP client for every outbound request,
+// RDAP queries and bootstrap downloads alike. The first middleware is the
+// outermost one, and middlewares added later wrap closer to the HTTP
+// client. Each middleware is called once when the chain is built, so it can
+// keep state across requests; the chain is rebuilt around a new HTTP client
+// set with SetHTTPClient or SetTransport.
+func (c *Client) Use(middlewares ...Middleware) *Client {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.buildChain()
+	return c
+}
+
+// buildChain wraps the HTTP client with the middlewares
+func (c *Client) buildChain() {
+	if len(c.middlewares) == 0 {
+		c.chain = nil
+		return
+	}
+
+	chain := c.httpClient
+	for _, middleware := range slices.Backward(c.middlewares) {
+		chain = middleware(chain)
+	}
+	c.chain = chain
+}
+
+// do sends a request through the middlewares and the HTTP client
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if err := c.checkHostAddrs(req); err != nil {
+		return nil, err
+	}
+	if c.chain != nil {
+		return c.chain.Do(req)
+	}
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,89 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// recordMiddleware returns a middleware appending its name to the calls before and after each request
+func recordMiddleware(name string, calls *[]string) Middleware {
+	return func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name)
+			resp, err := next.Do(req)
+			*calls = append(*calls, "/"+name)
+			return resp, err
+		})
+	}
+}
+
+func TestUseOrder(t *testing.T) {
+	var calls []string
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain"}`)).SetDisableCache(true).
+		Use(recordMiddleware("a", &calls), recordMiddleware("b", &calls))
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	// The bootstrap download and the query both go through the chain
+	if got := strings.Join(calls, " "); got != "a b /b /a a b /b /a" {
+		t.Errorf("Unexpected middleware calls: %s", got)
+	}
+}
+
+func TestUseHeader(t *testing.T) {
+	var auth string
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	}).Use(func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer token")
+			return next.Do(req)
+		})
+	})
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Expected the middleware header, got %q", auth)
+	}
+}
+
+func TestUseChaos(t *testing.T) {
+	injected := errors.New("injected failure")
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain"}`)).Use(func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/domain/") {
+				return nil, injected
+			}
+			return next.Do(req)
+		})
+	})
+
+	if _, err := client.RDAP("example.com"); !errors.Is(err, injected) {
+		t.Errorf("Expected the injected failure, got %v", err)
+	}
+}
+
+func TestUseRebuiltForHTTPClient(t *testing.T) {
+	var built, calls int
+	client := NewClient().SetBootstrapData([]byte(`{"services": [[["com"], ["https://rdap.example/"]]]}`)).
+		Use(func(next HTTPClient) HTTPClient {
+			built++
+			return next
+		})
+
+	client.SetHTTPClient(httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("network disabled")
+	}))
+	client.RDAP("example.com")
+	if built != 2 || calls == 0 {
+		t.Errorf("Expected the chain rebuilt around the new client, got %d builds and %d calls", built, calls)
+	}
+}
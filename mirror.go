@@ -0,0 +1,137 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"time"
+)
+
+// mirrorCooldown is how long a failing bootstrap mirror is tried last
+const mirrorCooldown = time.Minute
+
+// MirrorStatus is the health of a bootstrap mirror
+type MirrorStatus struct {
+	URL string
+	// Failures is the number of consecutive failed loads
+	Failures    int
+	LastError   error
+	LastFailure time.Time
+	LastSuccess time.Time
+}
+
+// Healthy reports whether the last load from the mirror succeeded
+func (s MirrorStatus) Healthy() bool {
+	return s.Failures == 0
+}
+
+// SetBootstrapURLs sets the domain bootstrap URLs, tried in order. Mirrors
+// that failed recently are tried after the healthy ones.
+func (c *Client) SetBootstrapURLs(urls []string) *Client {
+	if len(urls) == 0 {
+		return c
+	}
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+	c.bootstrapURL = urls[0]
+	c.bootstrapMirrors = append([]string(nil), urls...)
+	return c
+}
+
+// BootstrapMirrors returns the health of every domain bootstrap mirror in configured order
+func (c *Client) BootstrapMirrors() []MirrorStatus {
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+
+	statuses := make([]MirrorStatus, 0, len(c.bootstrapMirrors))
+	for _, url := range c.mirrorURLs() {
+		status := MirrorStatus{URL: url}
+		if health, ok := c.mirrorHealth[url]; ok {
+			status = *health
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// loadBootstrapMirrors loads the domain bootstrap from the first mirror that
+// answers, recording the health of every mirror tried
+func (c *Client) loadBootstrapMirrors(ctx context.Context) (*RDAPBootstrap, error) {
+	var lastErr error
+	for _, url := range c.orderedMirrors() {
+		bootstrap, err := c.loadBootstrapContext(ctx, url)
+		if ctx.Err() != nil {
+			// A cancelled lookup says nothing about the mirror
+			return nil, err
+		}
+		c.recordMirror(url, err)
+		if err == nil {
+			return bootstrap, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// mirrorURLs returns the configured mirrors, or the single bootstrap URL
+func (c *Client) mirrorURLs() []string {
+	if len(c.bootstrapMirrors) == 0 {
+		return []string{c.bootstrapURL}
+	}
+	return c.bootstrapMirrors
+}
+
+// orderedMirrors returns the mirrors to try, those that failed within the
+// cooldown moved to the end
+func (c *Client) orderedMirrors() []string {
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+
+	var healthy, failing []string
+	for _, url := range c.mirrorURLs() {
+		health, ok := c.mirrorHealth[url]
+		if ok && health.Failures > 0 && time.Since(health.LastFailure) < mirrorCooldown {
+			failing = append(failing, url)
+			continue
+		}
+		healthy = append(healthy, url)
+	}
+	return append(healthy, failing...)
+}
+
+// recordMirror records the outcome of a load from a mirror
+func (c *Client) recordMirror(url string, err error) {
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+
+	health, ok := c.mirrorHealth[url]
+	if !ok {
+		health = &MirrorStatus{URL: url}
+		c.mirrorHealth[url] = health
+	}
+	if err != nil {
+		health.Failures++
+		health.LastError = err
+		health.LastFailure = time.Now()
+		return
+	}
+	health.Failures = 0
+	health.LastError = nil
+	health.LastSuccess = time.Now()
+}
@@ -0,0 +1,70 @@
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBootstrapMirrorFailover(t *testing.T) {
+	var primaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	mirror, _ := newBootstrapServer(t)
+
+	client := NewClient().
+		SetEmbeddedFallback(false).
+		SetBootstrapURLs([]string{primary.URL, mirror.URL + "/dns.json"})
+
+	server, err := client.getTLDServer("com")
+	if err != nil {
+		t.Fatalf("Expected failover to the mirror, got: %v", err)
+	}
+	if server != "https://rdap.verisign.com/com/v1/" {
+		t.Errorf("Unexpected server %s", server)
+	}
+
+	statuses := client.BootstrapMirrors()
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 mirror statuses, got %d", len(statuses))
+	}
+	if statuses[0].Healthy() || statuses[0].Failures != 1 || statuses[0].LastError == nil {
+		t.Errorf("Expected primary to be unhealthy, got %+v", statuses[0])
+	}
+	if !statuses[1].Healthy() || statuses[1].LastSuccess.IsZero() {
+		t.Errorf("Expected mirror to be healthy, got %+v", statuses[1])
+	}
+
+	// The failing primary is tried after the healthy mirror during its cooldown
+	client.ClearCache()
+	if _, err := client.getTLDServer("com"); err != nil {
+		t.Fatalf("getTLDServer failed: %v", err)
+	}
+	if got := primaryHits.Load(); got != 1 {
+		t.Errorf("Expected failing primary to be skipped, got %d requests", got)
+	}
+}
+
+func TestBootstrapMirrorsAllFailing(t *testing.T) {
+	client := NewClient().
+		SetEmbeddedFallback(false).
+		SetBootstrapURLs([]string{"file:///nonexistent/a.json", "file:///nonexistent/b.json"})
+
+	if _, err := client.getBootstrapData(); err == nil {
+		t.Fatal("Expected error when every mirror fails")
+	}
+	for _, status := range client.BootstrapMirrors() {
+		if status.Healthy() {
+			t.Errorf("Expected %s to be unhealthy", status.URL)
+		}
+	}
+
+	client.SetBootstrapURL("file:///nonexistent/c.json")
+	if statuses := client.BootstrapMirrors(); len(statuses) != 1 || statuses[0].URL != "file:///nonexistent/c.json" {
+		t.Errorf("Expected SetBootstrapURL to replace the mirrors, got %+v", statuses)
+	}
+}
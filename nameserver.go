@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+)
+
+// Nameserver performs RDAP query for the given nameserver host name and returns raw JSON.
+// The query is sent to the RDAP server of the nameserver's TLD.
+func (c *Client) Nameserver(fqdn string) (result []byte, err error) {
+	// Normalize host name
+	fqdn = normalizeDomain(fqdn)
+	if fqdn == "" {
+		return nil, fmt.Errorf("nameserver cannot be empty")
+	}
+
+	if getTLD(fqdn) == "" {
+		return nil, fmt.Errorf("invalid nameserver: %s", fqdn)
+	}
+
+	// Get the appropriate RDAP server for this host
+	server, err := c.getHostServer(ObjectNameserver, fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", fqdn, err)
+	}
+
+	return c.doQuery(objectURL(server, "nameserver", fqdn))
+}
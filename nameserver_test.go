@@ -0,0 +1,77 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNameserverWithMockServer(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nameserver/ns1.example.com" {
+			t.Errorf("Expected path /nameserver/ns1.example.com, got %s", r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"objectClassName": "nameserver",
+			"ldhName":         "ns1.example.com",
+			"ipAddresses": map[string]interface{}{
+				"v4": []string{"192.0.2.53"},
+			},
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"com"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetBootstrapURL(bootstrapServer.URL)
+
+	result, err := client.Nameserver(" NS1.Example.COM. ")
+	if err != nil {
+		t.Fatalf("Nameserver query failed: %v", err)
+	}
+	if !strings.Contains(string(result), "192.0.2.53") {
+		t.Errorf("Expected nameserver address in response, got: %s", result)
+	}
+}
+
+func TestNameserverInvalidInput(t *testing.T) {
+	client := NewClient()
+
+	_, err := client.Nameserver("")
+	if err == nil || !strings.Contains(err.Error(), "nameserver cannot be empty") {
+		t.Errorf("Expected error about empty nameserver, got: %v", err)
+	}
+
+	_, err = client.Nameserver("localhost")
+	if err == nil || !strings.Contains(err.Error(), "invalid nameserver") {
+		t.Errorf("Expected error about invalid nameserver, got: %v", err)
+	}
+}
+
+func TestGetTLDServerForCH(t *testing.T) {
+	client := NewClient()
+
+	server, err := client.getTLDServer("ch")
+	if err != nil {
+		t.Fatalf("Failed to get RDAP server for .ch: %v", err)
+	}
+	if server != "https://rdap.nic.ch/" {
+		t.Errorf("Expected .ch base server, got %s", server)
+	}
+}
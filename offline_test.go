@@ -0,0 +1,69 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOfflineServesCache(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	client.SetOffline(true)
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("Expected cached response offline, got error: %v", err)
+	}
+	if !resp.Cached || !resp.Stale {
+		t.Errorf("Expected stale cached response, got %+v", resp)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected no query offline, got %d queries", got)
+	}
+
+	if _, err := client.RDAP("other.com"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline on a cache miss, got %v", err)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected no query offline, got %d queries", got)
+	}
+}
+
+func TestOfflineBootstrap(t *testing.T) {
+	var requests int
+	client := NewClient().
+		SetHTTPClient(httpClientFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return nil, errors.New("network used")
+		})).
+		SetEmbeddedFallback(false).
+		SetFallbackAggregator(DefaultFallbackAggregator).
+		SetOffline(true)
+
+	server, err := client.getRDAPServer("example.com")
+	if err != nil {
+		t.Fatalf("Expected the embedded bootstrap offline, got error: %v", err)
+	}
+	if server == "" {
+		t.Error("Expected a server from the embedded bootstrap")
+	}
+
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline, got %v", err)
+	}
+	if _, err := client.IP("192.0.2.1"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline for the IP bootstrap, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no network request offline, got %d", requests)
+	}
+}
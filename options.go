@@ -0,0 +1,144 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOption configures a single lookup
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings of a single lookup
+type requestOptions struct {
+	noCache bool
+	maxAge  time.Duration
+	timeout time.Duration
+	server  string
+	header  http.Header
+	ctx     context.Context
+}
+
+// WithNoCache forces a fresh fetch from the registry for one lookup, without
+// disabling the cache for others. The fresh response replaces the cached one;
+// a failed fetch leaves it in place.
+func WithNoCache() RequestOption {
+	return func(o *requestOptions) {
+		o.noCache = true
+	}
+}
+
+// WithMaxAge only accepts a cached response fetched or revalidated no longer
+// than maxAge ago, fetching a fresh one otherwise
+func WithMaxAge(maxAge time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.maxAge = max(maxAge, 0)
+	}
+}
+
+// WithTimeout bounds one lookup, including retries and failover, so one
+// client can serve callers with different latency budgets. It applies on
+// top of the timeout of the HTTP client.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithContext runs one lookup under the given context, so cancelling it
+// aborts the bootstrap fetch and the queries made on its behalf
+func WithContext(ctx context.Context) RequestOption {
+	return func(o *requestOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithServer queries the given RDAP base URL or URL template containing
+// "{domain}" instead of the server selected from the bootstrap data. The
+// lookup skips the response cache.
+func WithServer(server string) RequestOption {
+	return func(o *requestOptions) {
+		if server != "" && !isURLTemplate(server) {
+			server = normalizeServer(server)
+		}
+		o.server = server
+	}
+}
+
+// WithHeader adds a header to the requests of one lookup, such as the
+// credentials of a registry offering more data to authenticated clients.
+// The lookup skips the response cache, as the header may change the answer.
+// Like Authorization and Cookie, the header is not sent along redirects to
+// another host.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// newRequestOptions applies the given options to the defaults
+func newRequestOptions(opts []RequestOption) requestOptions {
+	options := requestOptions{maxAge: -1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// bypassesCache reports whether the lookup may have to skip fresh cached responses
+func (o requestOptions) bypassesCache() bool {
+	return o.noCache || o.maxAge >= 0
+}
+
+// skipsCache reports whether the lookup neither reads nor stores cached
+// responses, because its answer may differ from the shared one
+func (o requestOptions) skipsCache() bool {
+	return o.server != "" || len(o.header) > 0
+}
+
+// bounded reports whether the lookup may end before the shared one would,
+// through its own deadline or context
+func (o requestOptions) bounded() bool {
+	return o.timeout > 0 || o.ctx != nil
+}
+
+// context returns the context of the lookup, bounded by its timeout
+func (o requestOptions) context() (context.Context, context.CancelFunc) {
+	parent := o.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	if o.timeout > 0 {
+		return context.WithTimeout(parent, o.timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// accepts reports whether a cached entry satisfies the lookup
+func (o requestOptions) accepts(entry *responseEntry) bool {
+	if o.noCache {
+		return false
+	}
+	return o.maxAge < 0 || time.Since(entry.Stored) <= o.maxAge
+}
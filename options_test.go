@@ -0,0 +1,195 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithNoCache(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	resp, err := client.RDAPResponse("example.com", WithNoCache())
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if resp.Cached {
+		t.Error("Expected a fresh response with WithNoCache")
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected WithNoCache to query the registry, got %d queries", got)
+	}
+
+	// The fresh response replaced the cached copy and the cache still works
+	if _, err := client.Domain("example.com"); err != nil {
+		t.Fatalf("Domain failed: %v", err)
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected later lookups to use the cache, got %d queries", got)
+	}
+}
+
+func TestWithNoCacheFailedRefresh(t *testing.T) {
+	var failing atomic.Bool
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	failing.Store(true)
+	if _, err := client.RDAP("example.com", WithNoCache()); err == nil {
+		t.Fatal("Expected the forced refresh to fail")
+	}
+	if _, err := client.RDAP("example.com", WithMaxAge(0)); err == nil {
+		t.Fatal("Expected the refresh to fail")
+	}
+
+	// The fresh entry survives the failed refreshes
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil || !resp.Cached {
+		t.Errorf("Expected the cached entry to be kept, got cached=%v, %v", resp != nil && resp.Cached, err)
+	}
+}
+
+func TestWithMaxAge(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if _, err := client.RDAP("example.com", WithMaxAge(time.Hour)); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected a young cached response to be accepted, got %d queries", got)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := client.HasDNSSEC("example.com", WithMaxAge(time.Millisecond)); err != nil {
+		t.Fatalf("HasDNSSEC failed: %v", err)
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected an older cached response to be refetched, got %d queries", got)
+	}
+}
+
+func TestRequestOptionsOffline(t *testing.T) {
+	client, _ := newCountingDomainClient(t, http.StatusOK)
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	client.SetOffline(true)
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Errorf("Expected cached response offline, got %v", err)
+	}
+	if _, err := client.RDAP("example.com", WithNoCache()); err == nil {
+		t.Error("Expected WithNoCache to fail offline")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	start := time.Now()
+	_, err := client.RDAP("example.com", WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the lookup to stop at its timeout, took %v", elapsed)
+	}
+}
+
+func TestWithServer(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+	var special atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		special.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com", "port43": "special"}`))
+	}))
+	defer server.Close()
+
+	resp, err := client.RDAPResponse("example.com", WithServer(server.URL))
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if resp.Server != server.URL+"/" || special.Load() != 1 || queries.Load() != 0 {
+		t.Errorf("Expected the given server to answer, got %s after %d queries", resp.Server, queries.Load())
+	}
+
+	// The special answer is not cached for other lookups
+	body, err := client.RDAP("example.com")
+	if err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if strings.Contains(string(body), "special") || queries.Load() != 1 {
+		t.Errorf("Expected the registry answer, got %s", body)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var auth atomic.Value
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	if _, err := client.RDAP("example.com", WithHeader("Authorization", "Bearer token")); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got, _ := auth.Load().(string); got != "Bearer token" {
+		t.Errorf("Expected the header to be sent, got %q", got)
+	}
+
+	// The lookup with a header skipped the cache
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if resp.Cached {
+		t.Error("Expected the answer to the lookup with a header not to be cached")
+	}
+	if got, _ := auth.Load().(string); got != "" {
+		t.Errorf("Expected no header on other lookups, got %q", got)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.RDAP("example.com", WithContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if queries.Load() != 0 {
+		t.Errorf("Expected no query after cancellation, got %d", queries.Load())
+	}
+
+	if _, err := client.RDAP("example.com", WithContext(context.Background())); err != nil {
+		t.Errorf("RDAP failed: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ObjectType is an RDAP object type routed by a BootstrapProvider
+type ObjectType string
+
+// Object types as used in RDAP lookup paths
+const (
+	ObjectDomain     = ObjectType("domain")
+	ObjectNameserver = ObjectType("nameserver")
+	ObjectIP         = ObjectType("ip")
+	ObjectAutnum     = ObjectType("autnum")
+	ObjectEntity     = ObjectType("entity")
+)
+
+// BootstrapProvider routes RDAP queries to servers in place of the IANA
+// bootstrap data. The query is the domain or host name, the IP address or
+// CIDR prefix, the decimal ASN or the entity handle, and the result is the
+// RDAP base URL to query.
+type BootstrapProvider interface {
+	ServerFor(objectType ObjectType, query string) (string, error)
+}
+
+// SetBootstrapProvider sets the provider routing queries in place of the
+// bootstrap data. Server overrides still take precedence for domains and
+// nameservers. A nil provider restores the bootstrap data.
+func (c *Client) SetBootstrapProvider(provider BootstrapProvider) *Client {
+	c.provider = provider
+	return c
+}
+
+// ServerFor routes a query with the bootstrap registries, so a loaded
+// Bootstrap can be used as a BootstrapProvider
+func (b *Bootstrap) ServerFor(objectType ObjectType, query string) (string, error) {
+	switch objectType {
+	case ObjectDomain, ObjectNameserver:
+		return b.ServerForTLD(getTLD(strings.TrimSuffix(strings.ToLower(query), ".")))
+	case ObjectIP:
+		prefix, err := parseIPQuery(query)
+		if err != nil {
+			return "", err
+		}
+		return b.ServerForPrefix(prefix)
+	case ObjectAutnum:
+		asn, err := ParseASN(query)
+		if err != nil {
+			return "", err
+		}
+		return b.ServerForASN(asn)
+	case ObjectEntity:
+		tag := getObjectTag(query)
+		if tag == "" {
+			return "", fmt.Errorf("handle has no object tag: %s", query)
+		}
+		return b.ServerForTag(tag)
+	default:
+		return "", fmt.Errorf("unsupported object type: %s", objectType)
+	}
+}
+
+// getHostServer determines the RDAP server for a domain or nameserver host name
+func (c *Client) getHostServer(objectType ObjectType, host string) (string, error) {
+	servers, err := c.getHostServers(context.Background(), objectType, host)
+	if err != nil {
+		return "", err
+	}
+	return servers[0], nil
+}
+
+// getHostServers determines the RDAP servers for a domain or nameserver host
+// name, in the order they should be tried
+func (c *Client) getHostServers(ctx context.Context, objectType ObjectType, host string) ([]string, error) {
+	tld := getTLD(host)
+	if tld == "" {
+		return nil, fmt.Errorf("invalid %s: %s", objectType, host)
+	}
+
+	if _, ok := c.serverOverrides[tld]; !ok && c.provider != nil {
+		server, err := c.providerServer(objectType, host)
+		if err != nil {
+			return nil, err
+		}
+		return []string{server}, nil
+	}
+	return c.getTLDServers(ctx, tld)
+}
+
+// providerServer asks the bootstrap provider for the server of a query
+func (c *Client) providerServer(objectType ObjectType, query string) (string, error) {
+	server, err := c.provider.ServerFor(objectType, query)
+	if err != nil {
+		return "", fmt.Errorf("bootstrap provider failed for %s %s: %w", objectType, query, err)
+	}
+	if server == "" {
+		return "", fmt.Errorf("bootstrap provider returned no server for %s %s", objectType, query)
+	}
+	return normalizeServer(server), nil
+}
+
+// prefixQuery returns the provider query for an IP prefix: the address for a
+// single host, the CIDR notation otherwise
+func prefixQuery(prefix netip.Prefix) string {
+	if prefix.Bits() == prefix.Addr().BitLen() {
+		return prefix.Addr().String()
+	}
+	return prefix.String()
+}
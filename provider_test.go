@@ -0,0 +1,110 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// providerFunc adapts a function to the BootstrapProvider interface
+type providerFunc func(objectType ObjectType, query string) (string, error)
+
+func (f providerFunc) ServerFor(objectType ObjectType, query string) (string, error) {
+	return f(objectType, query)
+}
+
+func TestBootstrapProvider(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer mockServer.Close()
+
+	var routed []string
+	client := NewClient().
+		SetBootstrapURL("file:///nonexistent/dns.json").
+		SetBootstrapProvider(providerFunc(func(objectType ObjectType, query string) (string, error) {
+			routed = append(routed, string(objectType)+" "+query)
+			return mockServer.URL + "/rdap", nil
+		}))
+
+	calls := []func() ([]byte, error){
+		func() ([]byte, error) { return client.RDAP("Example.COM") },
+		func() ([]byte, error) { return client.Nameserver("ns1.example.com") },
+		func() ([]byte, error) { return client.IP("192.0.2.0/24") },
+		func() ([]byte, error) { return client.ASN(64512) },
+		func() ([]byte, error) { return client.Entity("ABC123-ARIN") },
+	}
+	for i, call := range calls {
+		result, err := call()
+		if err != nil {
+			t.Fatalf("Query %d failed: %v", i, err)
+		}
+		if !strings.Contains(string(result), `"/rdap/`) {
+			t.Errorf("Query %d not sent to the provider server: %s", i, result)
+		}
+	}
+
+	expected := []string{
+		"domain example.com",
+		"nameserver ns1.example.com",
+		"ip 192.0.2.0/24",
+		"autnum 64512",
+		"entity ABC123-ARIN",
+	}
+	if strings.Join(routed, ",") != strings.Join(expected, ",") {
+		t.Errorf("Unexpected provider queries: %v", routed)
+	}
+}
+
+func TestBootstrapProviderError(t *testing.T) {
+	client := NewClient().SetBootstrapProvider(providerFunc(func(ObjectType, string) (string, error) {
+		return "", ErrNoRDAPService
+	}))
+
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrNoRDAPService) {
+		t.Errorf("Expected provider error to be wrapped, got %v", err)
+	}
+
+	// Server overrides take precedence over the provider
+	if server, err := client.getHostServer(ObjectDomain, "example.ch"); err != nil || server != "https://rdap.nic.ch/" {
+		t.Errorf("Expected .ch override, got %s, %v", server, err)
+	}
+}
+
+func TestBootstrapServerFor(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	bootstrap, err := newBootstrapClient(server).LoadBootstrap()
+	if err != nil {
+		t.Fatalf("LoadBootstrap failed: %v", err)
+	}
+
+	tests := []struct {
+		objectType ObjectType
+		query      string
+		expected   string
+	}{
+		{ObjectDomain, "example.com", "https://rdap.verisign.com/com/v1/"},
+		{ObjectNameserver, "ns1.example.com.", "https://rdap.verisign.com/com/v1/"},
+		{ObjectIP, "41.1.2.3", "https://rdap.afrinic.net/rdap/"},
+		{ObjectAutnum, "AS37000", "https://rdap.afrinic.net/rdap/"},
+		{ObjectEntity, "ABC123-ARIN", "https://rdap.arin.net/registry/"},
+	}
+	for _, test := range tests {
+		got, err := bootstrap.ServerFor(test.objectType, test.query)
+		if err != nil {
+			t.Errorf("ServerFor(%s, %s) failed: %v", test.objectType, test.query, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("ServerFor(%s, %s) = %s, expected %s", test.objectType, test.query, got, test.expected)
+		}
+	}
+
+	if _, err := bootstrap.ServerFor(ObjectType("help"), ""); err == nil {
+		t.Error("Expected error for unsupported object type")
+	}
+
+	var _ BootstrapProvider = bootstrap
+}
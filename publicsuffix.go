@@ -0,0 +1,76 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// PublicSuffixList returns the public suffix of a domain, such as "co.uk"
+// for "example.co.uk", and whether it is managed by ICANN rather than being
+// a private suffix such as "github.io"
+type PublicSuffixList interface {
+	PublicSuffix(domain string) (suffix string, icann bool)
+}
+
+// ICANNPublicSuffixList is the public suffix list compiled into golang.org/x/net/publicsuffix
+var ICANNPublicSuffixList PublicSuffixList = suffixListFunc(publicsuffix.PublicSuffix)
+
+// suffixListFunc adapts a function to the PublicSuffixList interface
+type suffixListFunc func(domain string) (string, bool)
+
+// PublicSuffix returns the public suffix of the domain
+func (f suffixListFunc) PublicSuffix(domain string) (string, bool) {
+	return f(domain)
+}
+
+// SetPublicSuffixList sets the public suffix list used to reduce queried
+// names to their registrable domain, e.g. "foo.bar.example.co.uk" to
+// "example.co.uk". A nil list queries names as given.
+func (c *Client) SetPublicSuffixList(list PublicSuffixList) *Client {
+	c.suffixList = list
+	return c
+}
+
+// registrableDomain reduces a domain name to the domain registered under its
+// ICANN public suffix. Private suffixes are registered as domains themselves,
+// so "foo.github.io" is reduced to "github.io". Names that are a public
+// suffix are returned as is.
+func (c *Client) registrableDomain(domain string) string {
+	if c.suffixList == nil {
+		return domain
+	}
+
+	suffix, icann := c.suffixList.PublicSuffix(domain)
+	for !icann {
+		_, parent, ok := strings.Cut(suffix, ".")
+		if !ok {
+			break
+		}
+		suffix, icann = c.suffixList.PublicSuffix(parent)
+	}
+
+	if len(domain) <= len(suffix) || !strings.HasSuffix(domain, "."+suffix) {
+		return domain
+	}
+	labels := strings.TrimSuffix(domain, "."+suffix)
+	return labels[strings.LastIndex(labels, ".")+1:] + "." + suffix
+}
@@ -0,0 +1,47 @@
+package rdap
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	client := NewClient()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"www.example.com", "example.com"},
+		{"foo.bar.example.co.uk", "example.co.uk"},
+		{"example.co.uk", "example.co.uk"},
+		{"foo.github.io", "github.io"},
+		{"a.b.example.internal", "example.internal"},
+		{"co.uk", "co.uk"},
+		{"com", "com"},
+	}
+	for _, test := range tests {
+		if got := client.registrableDomain(test.input); got != test.expected {
+			t.Errorf("registrableDomain(%s) = %s, expected %s", test.input, got, test.expected)
+		}
+	}
+
+	client.SetPublicSuffixList(nil)
+	if got := client.registrableDomain("www.example.com"); got != "www.example.com" {
+		t.Errorf("Expected names to be kept without a suffix list, got %s", got)
+	}
+}
+
+func TestRDAPQueriesRegistrableDomain(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/example.com" {
+			t.Errorf("Expected registrable domain query, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	if _, err := client.RDAP("mail.eu.Example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query sends an RDAP request for any path or absolute URL and returns raw JSON.
+// Absolute http(s) URLs are requested as is. Paths such as "domain/example.com",
+// "ip/192.0.2.0/24", "autnum/64512", "entity/ABC123-ARIN" or
+// "nameserver/ns1.example.com" are routed to the server found in the matching
+// bootstrap registry, with any extra path segments or query string preserved.
+func (c *Client) Query(pathOrURL string) (result []byte, err error) {
+	pathOrURL = strings.TrimSpace(pathOrURL)
+	if pathOrURL == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	if strings.HasPrefix(pathOrURL, "https://") || strings.HasPrefix(pathOrURL, "http://") {
+		return c.doQuery(pathOrURL)
+	}
+
+	path := strings.TrimPrefix(pathOrURL, "/")
+	server, err := c.getPathServer(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", path, err)
+	}
+
+	return c.doQuery(server + path)
+}
+
+// getPathServer determines the RDAP server for a path from its object type and target
+func (c *Client) getPathServer(path string) (string, error) {
+	objectType, target, _ := strings.Cut(path, "/")
+	// Strip any query string from the lookup target
+	target, _, _ = strings.Cut(target, "?")
+
+	switch objectType {
+	case "domain", "nameserver":
+		return c.getHostServer(ObjectType(objectType), strings.TrimSuffix(strings.ToLower(target), "."))
+	case "ip":
+		prefix, err := parseIPQuery(target)
+		if err != nil {
+			return "", err
+		}
+		return c.getIPServer(prefix)
+	case "autnum":
+		asn, err := strconv.ParseUint(target, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid ASN: %s", target)
+		}
+		return c.getASNServer(uint32(asn))
+	case "entity":
+		return c.getEntityServer(target)
+	default:
+		return "", fmt.Errorf("cannot route path without an absolute URL: %s", path)
+	}
+}
+
+// objectURL returns the URL of an RDAP object under a server base URL, with
+// each path segment escaped, so a query target can't add path segments or a
+// query string to the request
+func objectURL(server, objectType string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(server)
+	b.WriteString(objectType)
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(escapeSegment(segment))
+	}
+	return b.String()
+}
+
+// templateURL returns the URL of a server URL template for a domain, escaping
+// the domain for the path or the query string, wherever the placeholder is
+func templateURL(template, domain string) string {
+	escaped := escapeSegment(domain)
+	if i := strings.Index(template, "?"); i >= 0 && i < strings.Index(template, domainPlaceholder) {
+		escaped = url.QueryEscape(domain)
+	}
+	return strings.ReplaceAll(template, domainPlaceholder, escaped)
+}
+
+// escapeSegment escapes a path segment, including the dot segments servers
+// would resolve to a parent path
+func escapeSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.ReplaceAll(segment, ".", "%2E")
+	}
+	return url.PathEscape(segment)
+}
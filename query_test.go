@@ -0,0 +1,106 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQueryAbsoluteURL(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/rdap+json;charset=UTF-8" {
+			t.Errorf("Expected RDAP Accept header, got %s", r.Header.Get("Accept"))
+		}
+		if r.URL.Path != "/help" {
+			t.Errorf("Expected path /help, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"rdapConformance":["rdap_level_0"]}`))
+	}))
+	defer mockServer.Close()
+
+	client := NewClient()
+	result, err := client.Query(mockServer.URL + "/help")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.Contains(string(result), "rdap_level_0") {
+		t.Errorf("Expected help response, got: %s", result)
+	}
+}
+
+func TestQueryPathRouting(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "query": r.URL.RawQuery})
+	}))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"com"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetBootstrapURL(bootstrapServer.URL)
+
+	result, err := client.Query("/domain/example.com?jscard=1")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !strings.Contains(string(result), `"path":"/domain/example.com"`) || !strings.Contains(string(result), "jscard=1") {
+		t.Errorf("Expected path and query string to be preserved, got: %s", result)
+	}
+
+	_, err = client.Query("help")
+	if err == nil || !strings.Contains(err.Error(), "cannot route path") {
+		t.Errorf("Expected error for unroutable path, got: %v", err)
+	}
+
+	if _, err := client.Query(""); err == nil {
+		t.Error("Expected error for empty query")
+	}
+}
+
+func TestObjectURL(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{objectURL("https://rdap.example/", "domain", "example.com"), "https://rdap.example/domain/example.com"},
+		{objectURL("https://rdap.example/", "domain", "a/../b?c=d#e.com"), "https://rdap.example/domain/a%2F..%2Fb%3Fc=d%23e.com"},
+		{objectURL("https://rdap.example/", "entity", ".."), "https://rdap.example/entity/%2E%2E"},
+		{objectURL("https://rdap.example/", "ip", "2001:db8::", "32"), "https://rdap.example/ip/2001:db8::/32"},
+		{templateURL("https://rdap.example/v1/{domain}", "a/b.com"), "https://rdap.example/v1/a%2Fb.com"},
+		{templateURL("https://rdap.example/lookup?name={domain}", "a&b=c.com"), "https://rdap.example/lookup?name=a%26b%3Dc.com"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("Expected %s, got %s", test.want, test.got)
+		}
+	}
+}
+
+func TestQueryTargetEscaped(t *testing.T) {
+	var requestURI string
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+
+	if _, err := client.RDAP("evil;admin,x=1%.com"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if requestURI != "/domain/evil%3Badmin%2Cx=1%25.com" {
+		t.Errorf("Expected the domain escaped as one path segment, got %s", requestURI)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces the queries sent to RDAP servers
+type RateLimiter interface {
+	// Wait blocks until a query may be sent to the given host, or returns
+	// the error of ctx if it is done first
+	Wait(ctx context.Context, host string) error
+}
+
+// RateLimit is a token bucket rate: QPS queries per second on average, with
+// bursts of up to Burst queries
+type RateLimit struct {
+	QPS   float64
+	Burst int
+}
+
+// DefaultRateLimits are conservative limits for registries known to throttle
+// or ban bulk clients. They are estimates kept below the observed thresholds,
+// not limits published by the registries.
+var DefaultRateLimits = map[string]RateLimit{
+	"rdap.verisign.com": {QPS: 10, Burst: 20},
+	"rdap.db.ripe.net":  {QPS: 5, Burst: 10},
+	"rdap.arin.net":     {QPS: 5, Burst: 10},
+	"rdap.apnic.net":    {QPS: 5, Burst: 10},
+	"rdap.afrinic.net":  {QPS: 2, Burst: 5},
+	"rdap.lacnic.net":   {QPS: 1, Burst: 5},
+}
+
+// HostRateLimiter is a RateLimiter keeping a token bucket per host
+type HostRateLimiter struct {
+	mu      sync.Mutex
+	limit   RateLimit
+	limits  map[string]RateLimit
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket holds the tokens left for a host and when they were counted
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewHostRateLimiter returns a rate limiter allowing qps queries per second
+// with bursts of burst queries to every host, except the hosts of
+// DefaultRateLimits which keep their own limit. A zero qps leaves other
+// hosts unlimited.
+func NewHostRateLimiter(qps float64, burst int) *HostRateLimiter {
+	limits := make(map[string]RateLimit, len(DefaultRateLimits))
+	for host, limit := range DefaultRateLimits {
+		limits[host] = limit
+	}
+	return &HostRateLimiter{
+		limit:   RateLimit{QPS: qps, Burst: burst},
+		limits:  limits,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// SetHostLimit sets the limit of one host, replacing its default. A zero qps
+// leaves the host unlimited.
+func (l *HostRateLimiter) SetHostLimit(host string, qps float64, burst int) *HostRateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	host = strings.ToLower(host)
+	l.limits[host] = RateLimit{QPS: qps, Burst: burst}
+	delete(l.buckets, host)
+	return l
+}
+
+// Wait blocks until a query may be sent to the given host
+func (l *HostRateLimiter) Wait(ctx context.Context, host string) error {
+	wait := l.reserve(strings.ToLower(host), time.Now())
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket of the host and returns how long to
+// wait for it to be available
+func (l *HostRateLimiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[host]
+	if !ok {
+		limit, ok := l.limits[host]
+		if !ok {
+			limit = l.limit
+		}
+		if limit.QPS <= 0 {
+			return 0
+		}
+		limit.Burst = max(limit.Burst, 1)
+		bucket = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		l.buckets[host] = bucket
+	}
+	if bucket.limit.QPS <= 0 {
+		return 0
+	}
+
+	// Refill for the time elapsed, then take a token, possibly ahead of time
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = min(bucket.tokens+elapsed*bucket.limit.QPS, float64(bucket.limit.Burst))
+		bucket.last = now
+	}
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.limit.QPS * float64(time.Second))
+}
+
+// SetRateLimiter sets the limiter pacing the queries sent to RDAP servers,
+// such as a HostRateLimiter. A nil limiter, the default, sends queries
+// without delay.
+func (c *Client) SetRateLimiter(limiter RateLimiter) *Client {
+	c.rateLimiter = limiter
+	return c
+}
+
+// SetMaxConcurrent limits the number of RDAP queries the client sends at the
+// same time, across all goroutines using it. Queries over the limit wait for
+// a slot. Zero or less, the default, sets no limit.
+func (c *Client) SetMaxConcurrent(n int) *Client {
+	if n <= 0 {
+		c.requestSlots = nil
+		return c
+	}
+	c.requestSlots = make(chan struct{}, n)
+	return c
+}
+
+// acquireSlot waits for a free slot of the concurrency limit and returns the
+// function releasing it
+func (c *Client) acquireSlot(ctx context.Context) (func(), error) {
+	if c.requestSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.requestSlots <- struct{}{}:
+		return func() { <-c.requestSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
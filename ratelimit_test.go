@@ -0,0 +1,143 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostRateLimiterReserve(t *testing.T) {
+	limiter := NewHostRateLimiter(10, 2)
+	now := time.Now()
+
+	for i, want := range []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond} {
+		if got := limiter.reserve("rdap.example.com", now); got != want {
+			t.Errorf("reserve #%d = %v, want %v", i, got, want)
+		}
+	}
+
+	// The bucket refills at qps up to the burst
+	if got := limiter.reserve("rdap.example.com", now.Add(time.Second)); got != 0 {
+		t.Errorf("Expected a refilled bucket, got wait %v", got)
+	}
+	// Hosts have their own bucket
+	if got := limiter.reserve("rdap.other.example", now); got != 0 {
+		t.Errorf("Expected another host not to wait, got %v", got)
+	}
+}
+
+func TestHostRateLimiterLimits(t *testing.T) {
+	now := time.Now()
+	limiter := NewHostRateLimiter(0, 0).SetHostLimit("RDAP.Example.com", 1, 1)
+
+	for range 10 {
+		if got := limiter.reserve("rdap.unlimited.example", now); got != 0 {
+			t.Fatalf("Expected zero qps to leave the host unlimited, got wait %v", got)
+		}
+	}
+
+	limiter.reserve("rdap.example.com", now)
+	if got := limiter.reserve("rdap.example.com", now); got != time.Second {
+		t.Errorf("Expected host limit of 1 qps, got wait %v", got)
+	}
+
+	// Registries of DefaultRateLimits keep their default limit
+	burst := DefaultRateLimits["rdap.lacnic.net"].Burst
+	for range burst {
+		limiter.reserve("rdap.lacnic.net", now)
+	}
+	if got := limiter.reserve("rdap.lacnic.net", now); got <= 0 {
+		t.Errorf("Expected default limit past the burst, got wait %v", got)
+	}
+}
+
+func TestHostRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewHostRateLimiter(0.001, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := limiter.Wait(ctx, "rdap.example.com"); err != nil {
+		t.Fatalf("Expected the burst token right away, got %v", err)
+	}
+	if err := limiter.Wait(ctx, "rdap.example.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// hostRecorder is a RateLimiter recording the hosts it was asked for
+type hostRecorder struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (r *hostRecorder) Wait(ctx context.Context, host string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts = append(r.hosts, host)
+	return nil
+}
+
+func TestSetRateLimiter(t *testing.T) {
+	recorder := &hostRecorder{}
+	client, _ := newCountingDomainClient(t, http.StatusOK)
+	client.SetRateLimiter(recorder).SetDisableCache(true)
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if len(recorder.hosts) != 1 || recorder.hosts[0] != "127.0.0.1" {
+		t.Errorf("Expected one wait for the RDAP server host, got %v", recorder.hosts)
+	}
+}
+
+func TestSetMaxConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak int
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}).SetMaxConcurrent(2).SetDisableCache(true)
+
+	var wg sync.WaitGroup
+	for _, domain := range []string{"a.com", "b.com", "c.com", "d.com", "e.com", "f.com"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.RDAP(domain); err != nil {
+				t.Errorf("RDAP(%s) failed: %v", domain, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent queries, got %d", peak)
+	}
+}
+
+func TestAcquireSlotCancelled(t *testing.T) {
+	client := NewClient().SetMaxConcurrent(1)
+	release, err := client.acquireSlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquireSlot failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.acquireSlot(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled while the only slot is taken, got %v", err)
+	}
+}
@@ -19,24 +19,44 @@
 package rdap
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	// defaultRDAPBootstrapURL is the IANA RDAP bootstrap URL
 	defaultRDAPBootstrapURL = "https://data.iana.org/rdap/dns.json"
+	// defaultIPv4BootstrapURL is the IANA RDAP bootstrap URL for IPv4 address space
+	defaultIPv4BootstrapURL = "https://data.iana.org/rdap/ipv4.json"
+	// defaultIPv6BootstrapURL is the IANA RDAP bootstrap URL for IPv6 address space
+	defaultIPv6BootstrapURL = "https://data.iana.org/rdap/ipv6.json"
+	// defaultASNBootstrapURL is the IANA RDAP bootstrap URL for autonomous system numbers
+	defaultASNBootstrapURL = "https://data.iana.org/rdap/asn.json"
+	// defaultObjectTagsBootstrapURL is the IANA RDAP bootstrap URL for RFC 8521 object tags
+	defaultObjectTagsBootstrapURL = "https://data.iana.org/rdap/object-tags.json"
 	// defaultTimeout is query default timeout
 	defaultTimeout = 30 * time.Second
-	// bootstrapCacheDuration is how long to cache the bootstrap data
-	bootstrapCacheDuration = 24 * time.Hour
+	// defaultBootstrapCacheTTL is how long to cache the bootstrap data by default
+	defaultBootstrapCacheTTL = 24 * time.Hour
+	// inlineBootstrapURL is the bootstrap URL standing for content set with SetBootstrapData
+	inlineBootstrapURL = "inline:bootstrap"
+	// domainPlaceholder marks where the domain goes in a server URL template
+	domainPlaceholder = "{domain}"
 )
 
+// defaultServerOverrides holds the RDAP servers used for TLDs regardless of the bootstrap data
+var defaultServerOverrides = map[string]string{
+	// .ch is missing from older bootstrap snapshots
+	"ch": "https://rdap.nic.ch/",
+}
+
 // DefaultClient is default RDAP client
 var DefaultClient = NewClient()
 
@@ -45,21 +65,77 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// RDAPBootstrap represents the IANA RDAP bootstrap file structure
-type RDAPBootstrap struct {
-	Description string       `json:"description"`
-	Publication string       `json:"publication"`
-	Services    [][][]string `json:"services"`
-	Version     string       `json:"version"`
-}
-
-// Client is RDAP client
+// Client is RDAP client.
+//
+// A Client is safe for concurrent use by multiple goroutines once it is
+// configured: queries, the bootstrap cache, the background refresher and
+// RefreshBootstrap may run concurrently. The bootstrap source setters
+// (SetBootstrapURL, SetBootstrapURLs, SetBootstrapFile, SetBootstrapData and
+// SetBootstrapReader) may be called at any time; other setters must be called
+// before the Client is shared.
 type Client struct {
 	httpClient         HTTPClient
+	middlewares        []Middleware
+	chain              HTTPClient
 	bootstrapURL       string
-	serverMap          map[string]string
+	bootstrapMirrors   []string
+	bootstrapData      []byte
+	bootstrapDataErr   error
+	mirrorHealth       map[string]*MirrorStatus
+	healthMu           sync.Mutex
+	serverHealth       map[string]*ServerStatus
+	breakerThreshold   int
+	breakerCooldown    time.Duration
+	probeInterval      time.Duration
+	ipv4BootstrapURL   string
+	ipv6BootstrapURL   string
+	asnBootstrapURL    string
+	tagsBootstrapURL   string
+	serverOverrides    map[string]string
+	noRDAPTLDs         map[string]bool
+	bootstrapMu        sync.Mutex
+	bootstrapCache     map[string]*bootstrapEntry
+	bootstrapCacheTTL  time.Duration
+	revalidating       map[string]bool
+	loading            map[string]*bootstrapCall
+	refresher          *bootstrapRefresher
 	disableCache       bool
 	cacheBootstrapOnly bool
+	cache              Cache
+	cacheTTL           time.Duration
+	tldCacheTTLs       map[string]time.Duration
+	cacheTTLPolicy     CacheTTLPolicy
+	cacheNamespace     string
+	compressCache      bool
+	negativeCacheTTL   time.Duration
+	maxStale           time.Duration
+	stats              cacheCounters
+	queries            queryGroup
+	embeddedFallback   bool
+	fallbackAggregator string
+	fallbackChains     map[string][]FallbackStep
+	hooks              Hooks
+	provider           BootstrapProvider
+	serverSelector     ServerSelector
+	serverPreferences  map[string][]string
+	allowInsecure      bool
+	verifyDomainName   bool
+	serverPolicy       *ServerPolicy
+	sharedTransport    bool
+	offline            bool
+	rateLimitWait      time.Duration
+	retryBudget        *retryBudget
+	rateLimiter        RateLimiter
+	requestSlots       chan struct{}
+	maxRedirects       int
+	redirectHTTPSOnly  bool
+	redirectPolicy     RedirectPolicy
+	nameserverLimit    int
+	resolver           *net.Resolver
+	dialContext        DialContextFunc
+	ipPreference       IPPreference
+	maxResponseSize    int64
+	suffixList         PublicSuffixList
 }
 
 // RDAP do the RDAP query and returns RDAP information
@@ -70,19 +146,40 @@ func RDAP(domain string) (result []byte, err error) {
 // NewClient returns new RDAP client
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		httpClient:         newHTTPClient(NewTransport()),
 		bootstrapURL:       defaultRDAPBootstrapURL,
-		serverMap:          make(map[string]string),
+		ipv4BootstrapURL:   defaultIPv4BootstrapURL,
+		ipv6BootstrapURL:   defaultIPv6BootstrapURL,
+		asnBootstrapURL:    defaultASNBootstrapURL,
+		tagsBootstrapURL:   defaultObjectTagsBootstrapURL,
+		serverOverrides:    copyServerOverrides(defaultServerOverrides),
+		noRDAPTLDs:         make(map[string]bool),
+		bootstrapCache:     make(map[string]*bootstrapEntry),
+		bootstrapCacheTTL:  defaultBootstrapCacheTTL,
+		revalidating:       make(map[string]bool),
+		loading:            make(map[string]*bootstrapCall),
+		mirrorHealth:       make(map[string]*MirrorStatus),
+		serverHealth:       make(map[string]*ServerStatus),
 		disableCache:       false,
 		cacheBootstrapOnly: false,
+		cache:              NewLRUCache(defaultCacheEntries).SetMaxBytes(defaultCacheBytes),
+		cacheTTL:           defaultResponseCacheTTL,
+		negativeCacheTTL:   defaultNegativeCacheTTL,
+		embeddedFallback:   true,
+		suffixList:         ICANNPublicSuffixList,
+		maxRedirects:       defaultMaxRedirects,
+		maxResponseSize:    defaultMaxResponseSize,
 	}
 }
 
-// SetHTTPClient sets the HTTP client
+// SetHTTPClient sets the HTTP client. The resolver, dialer, IP preference
+// and server policy already set are applied to a copy of its *http.Transport,
+// leaving the client given here untouched.
 func (c *Client) SetHTTPClient(client HTTPClient) *Client {
 	c.httpClient = client
+	c.sharedTransport = true
+	c.buildChain()
+	c.reapplyDialer()
 	return c
 }
 
@@ -96,17 +193,63 @@ func (c *Client) SetTimeout(timeout time.Duration) *Client {
 
 // SetBootstrapURL sets the bootstrap URL
 func (c *Client) SetBootstrapURL(url string) *Client {
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
 	c.bootstrapURL = url
+	c.bootstrapMirrors = nil
+	return c
+}
+
+// SetIPv4BootstrapURL sets the IPv4 address space bootstrap URL
+func (c *Client) SetIPv4BootstrapURL(url string) *Client {
+	c.ipv4BootstrapURL = url
+	return c
+}
+
+// SetIPv6BootstrapURL sets the IPv6 address space bootstrap URL
+func (c *Client) SetIPv6BootstrapURL(url string) *Client {
+	c.ipv6BootstrapURL = url
+	return c
+}
+
+// SetASNBootstrapURL sets the autonomous system number bootstrap URL
+func (c *Client) SetASNBootstrapURL(url string) *Client {
+	c.asnBootstrapURL = url
+	return c
+}
+
+// SetObjectTagsBootstrapURL sets the RFC 8521 object tags bootstrap URL
+func (c *Client) SetObjectTagsBootstrapURL(url string) *Client {
+	c.tagsBootstrapURL = url
 	return c
 }
 
-// SetDisableCache disables caching for Lambda environments
+// SetDisableCache disables caching for Lambda environments: every query
+// fetches the bootstrap file and the domain response again
 func (c *Client) SetDisableCache(disabled bool) *Client {
 	c.disableCache = disabled
 	return c
 }
 
-// SetCacheBootstrapOnly enables caching only for bootstrap data, not domain queries
+// SetBootstrapCacheTTL sets how long cached bootstrap data is used before it
+// is revalidated. A zero or negative TTL never expires the cached copy
+func (c *Client) SetBootstrapCacheTTL(ttl time.Duration) *Client {
+	c.bootstrapCacheTTL = ttl
+	return c
+}
+
+// SetOffline puts the client in offline mode, where it never touches the
+// network: the domain bootstrap comes from the embedded snapshot or a local
+// file or data, and responses are served from the cache only, even when
+// stale. Lookups that need the network fail with ErrOffline
+func (c *Client) SetOffline(offline bool) *Client {
+	c.offline = offline
+	return c
+}
+
+// SetCacheBootstrapOnly enables caching only for bootstrap data, not domain
+// queries, which then always reach the registry. SetDisableCache(true) takes
+// precedence
 func (c *Client) SetCacheBootstrapOnly(enabled bool) *Client {
 	c.cacheBootstrapOnly = enabled
 	return c
@@ -114,179 +257,372 @@ func (c *Client) SetCacheBootstrapOnly(enabled bool) *Client {
 
 // SetBootstrapFile sets the path to a local bootstrap file
 func (c *Client) SetBootstrapFile(filepath string) *Client {
-	c.bootstrapURL = "file://" + filepath
+	return c.SetBootstrapURL("file://" + filepath)
+}
+
+// SetBootstrapData sets the domain bootstrap content, in the IANA dns.json
+// format, instead of fetching it from a URL
+func (c *Client) SetBootstrapData(data []byte) *Client {
+	return c.setBootstrapData(data, nil)
+}
+
+// SetBootstrapReader sets the domain bootstrap content read from r, as
+// SetBootstrapData does. A read error is returned by the first lookup.
+func (c *Client) SetBootstrapReader(r io.Reader) *Client {
+	data, err := io.ReadAll(r)
+	return c.setBootstrapData(data, err)
+}
+
+// setBootstrapData sets the domain bootstrap content and the error reading it
+func (c *Client) setBootstrapData(data []byte, err error) *Client {
+	c.bootstrapMu.Lock()
+	defer c.bootstrapMu.Unlock()
+	c.bootstrapURL = inlineBootstrapURL
+	c.bootstrapMirrors = nil
+	c.bootstrapData = append([]byte(nil), data...)
+	c.bootstrapDataErr = err
+	delete(c.bootstrapCache, inlineBootstrapURL)
 	return c
 }
 
-// RDAPRaw performs RDAP query for the given domain and returns raw JSON
-func (c *Client) RDAP(domain string) (result []byte, err error) {
-	// Normalize domain
-	domain = strings.ToLower(strings.TrimSpace(domain))
-	if domain == "" {
-		return nil, fmt.Errorf("domain cannot be empty")
+// SetEmbeddedFallback sets whether domain lookups fall back to the embedded
+// dns.json snapshot when the bootstrap data cannot be fetched (enabled by default)
+func (c *Client) SetEmbeddedFallback(enabled bool) *Client {
+	c.embeddedFallback = enabled
+	return c
+}
+
+// SetServerOverride sets the RDAP server used for a TLD instead of the one
+// from the bootstrap data. The server is either a base URL, to which
+// "domain/<domain>" is appended, or a URL template containing "{domain}".
+// An empty server removes the override.
+func (c *Client) SetServerOverride(tld, server string) *Client {
+	tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+	if server == "" {
+		delete(c.serverOverrides, tld)
+		return c
 	}
+	c.serverOverrides[tld] = server
+	return c
+}
 
-	// Get the appropriate RDAP server for this domain
-	server, err := c.getRDAPServer(domain)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", domain, err)
+// SetServerOverrides sets the RDAP servers used for several TLDs, as SetServerOverride does
+func (c *Client) SetServerOverrides(overrides map[string]string) *Client {
+	for tld, server := range overrides {
+		c.SetServerOverride(tld, server)
 	}
+	return c
+}
 
-	// Perform the RDAP query
-	return c.queryRDAP(domain, server)
+// SetNoRDAPTLDs sets TLDs known to have no RDAP service. Lookups for them
+// fail with ErrNoRDAPService without loading the bootstrap data.
+func (c *Client) SetNoRDAPTLDs(tlds ...string) *Client {
+	c.noRDAPTLDs = make(map[string]bool, len(tlds))
+	for _, tld := range tlds {
+		c.noRDAPTLDs[strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))] = true
+	}
+	return c
 }
 
-// getRDAPServer determines the appropriate RDAP server for a domain
-func (c *Client) getRDAPServer(domain string) (string, error) {
-	// Extract TLD from domain
-	tld := getTLD(domain)
+// SupportsRDAP reports whether the TLD has an RDAP service, either from a
+// server override or from the bootstrap data
+func (c *Client) SupportsRDAP(tld string) (bool, error) {
+	tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
 	if tld == "" {
-		return "", fmt.Errorf("invalid domain: %s", domain)
+		return false, fmt.Errorf("tld cannot be empty")
 	}
-
-	// Special case for .ch domains
-	if tld == "ch" {
-		return "https://rdap.nic.ch/domain/" + domain, nil
+	if _, ok := c.serverOverrides[tld]; ok {
+		return true, nil
 	}
 
-	// Get bootstrap data
-	bootstrap, err := c.getBootstrapData()
+	_, err := c.getTLDServer(tld)
+	if errors.Is(err, ErrNoRDAPService) {
+		return false, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get bootstrap data: %w", err)
+		return false, err
 	}
+	return true, nil
+}
 
-	// Find the appropriate server for this TLD
-	server, err := c.findServerForTLD(tld, bootstrap)
+// RDAPRaw performs RDAP query for the given domain and returns raw JSON
+func (c *Client) RDAP(domain string, opts ...RequestOption) (result []byte, err error) {
+	resp, err := c.RDAPResponse(domain, opts...)
 	if err != nil {
-		return "", fmt.Errorf("no RDAP server found for TLD %s: %w", tld, err)
+		return nil, err
 	}
-
-	return server, nil
+	return resp.Body, nil
 }
 
-// getBootstrapData fetches the IANA RDAP bootstrap data
-func (c *Client) getBootstrapData() (*RDAPBootstrap, error) {
-	var body []byte
-	var err error
-
-	// Check if we're reading from a local file
-	if strings.HasPrefix(c.bootstrapURL, "file://") {
-		filepath := strings.TrimPrefix(c.bootstrapURL, "file://")
-		body, err = os.ReadFile(filepath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read bootstrap file %s: %w", filepath, err)
+// RDAPResponse performs RDAP query for the given domain and returns the
+// response with its query metadata. On a non-success HTTP status the
+// response is returned along with the error.
+func (c *Client) RDAPResponse(domain string, opts ...RequestOption) (*Response, error) {
+	// Normalize domain
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return nil, fmt.Errorf("%w: domain cannot be empty", ErrInvalidDomain)
+	}
+	domain = c.registrableDomain(domain)
+
+	// Every spelling of the domain shares one cache entry, and concurrent
+	// lookups of the same domain share one request
+	key := domainCacheKey(domain)
+	options := newRequestOptions(opts)
+	ctx, cancel := options.context()
+	defer cancel()
+	query := func() (*Response, error) {
+		trace := &queryTrace{}
+		resp, err := c.queryDomain(withQueryTrace(ctx, trace), domain, options)
+		if resp != nil {
+			resp.Domain = domain
 		}
-	} else {
-		// Fetch from URL
-		req, err := http.NewRequest("GET", c.bootstrapURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+		return resp, queryError(domain, resp, err, trace)
+	}
+	lookup := func() (*Response, error) {
+		check := func(resp *Response) error {
+			return c.checkDomainAnswer(domain, resp)
 		}
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch bootstrap data: %w", err)
+		resp, err := c.cachedQuery(ctx, key, options, query, check)
+		if resp != nil {
+			resp.Domain = domain
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("bootstrap request failed with status: %d", resp.StatusCode)
+		if err == nil && resp.Cached && c.verifyDomainName {
+			err = c.verifyCachedDomainName(key, domain, resp)
 		}
+		return resp, queryError(domain, resp, err, nil)
+	}
+	if options.skipsCache() {
+		return query()
+	}
+	if options.bounded() {
+		// Don't share a lookup bounded by its own deadline or context
+		return lookup()
+	}
 
-		body, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read bootstrap response: %w", err)
-		}
+	flight := key
+	if options.bypassesCache() {
+		// Don't wait on lookups that may be served from the cache
+		flight = "fresh " + key
 	}
+	return c.queries.do(flight, lookup)
+}
 
-	var bootstrap RDAPBootstrap
-	if err := json.Unmarshal(body, &bootstrap); err != nil {
-		return nil, fmt.Errorf("failed to parse bootstrap JSON: %w", err)
+// queryDomain queries the RDAP servers of the given normalized domain, failing
+// over to the next server registered for the TLD when one gives no answer.
+// Answers are checked before anyone caches them: an empty answer is turned
+// into a 404, and a response naming another domain fails when the client
+// verifies domain names.
+func (c *Client) queryDomain(ctx context.Context, domain string, options requestOptions) (*Response, error) {
+	resp, err := c.queryDomainServers(ctx, domain, options)
+	if err == nil {
+		err = c.checkDomainAnswer(domain, resp)
 	}
+	return resp, err
+}
 
-	return &bootstrap, nil
+// checkDomainAnswer checks a successful answer to a domain query, turning an
+// empty answer into a 404
+func (c *Client) checkDomainAnswer(domain string, resp *Response) error {
+	if isEmptyAnswer(resp.Body) {
+		// Some registries answer 200 for domains they don't have
+		resp.StatusCode = http.StatusNotFound
+		return resp.statusError()
+	}
+	if c.verifyDomainName {
+		return verifyDomainName(domain, resp.Body)
+	}
+	return nil
 }
 
-// findServerForTLD finds the appropriate RDAP server for a given TLD
-func (c *Client) findServerForTLD(tld string, bootstrap *RDAPBootstrap) (string, error) {
-	for _, service := range bootstrap.Services {
-		if len(service) != 2 {
-			continue
+// queryDomainServers sends a domain query to the server given for the lookup,
+// the fallback chain of the TLD or its registry servers
+func (c *Client) queryDomainServers(ctx context.Context, domain string, options requestOptions) (*Response, error) {
+	// A server given for the lookup is queried as is
+	if options.server != "" {
+		return c.queryRDAPResponse(ctx, domain, options.server, options.header)
+	}
+
+	if chain, ok := c.fallbackChain(domain); ok {
+		return c.queryChain(ctx, domain, options, chain)
+	}
+
+	resp, err := c.queryRegistry(ctx, domain, options)
+	if c.shouldFallback(err) && ctx.Err() == nil && c.allowRetry() {
+		c.onRetry(RetryInfo{Context: ctx, Reason: RetryFallback, Query: domain, Next: c.fallbackAggregator, Err: err})
+		resp, err = c.queryRDAPResponse(ctx, domain, c.fallbackAggregator, options.header)
+		if resp != nil {
+			resp.Source = SourceAggregator
 		}
+	}
+	return resp, err
+}
+
+// queryRegistry queries the registry RDAP servers of the given normalized domain
+func (c *Client) queryRegistry(ctx context.Context, domain string, options requestOptions) (*Response, error) {
+	// Get the appropriate RDAP servers for this domain
+	servers, err := c.getRDAPServers(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", domain, err)
+	}
 
-		tlds := service[0]
-		servers := service[1]
-
-		for _, serviceTLD := range tlds {
-			if serviceTLD == tld && len(servers) > 0 {
-				// Use the first server in the list
-				server := servers[0]
-				// Ensure the URL ends with a slash
-				if !strings.HasSuffix(server, "/") {
-					server += "/"
-				}
-				return server, nil
+	// Perform the RDAP query
+	var resp *Response
+	for i, server := range servers {
+		if i > 0 {
+			if !c.allowRetry() {
+				break
 			}
+			c.onRetry(RetryInfo{Context: ctx, Reason: RetryFailover, Query: domain, Next: server, Err: err})
+		}
+		c.onServerSelected(ServerSelectionInfo{Context: ctx, Domain: domain, Server: server, Candidates: servers, Attempt: i + 1})
+		resp, err = c.queryRDAPResponse(ctx, domain, server, options.header)
+		if !isQueryFailure(err) || errors.Is(err, ErrOffline) || ctx.Err() != nil {
+			break
 		}
 	}
+	if resp != nil {
+		resp.Source = SourceRegistry
+	}
+	return resp, err
+}
 
-	return "", fmt.Errorf("no server found for TLD: %s", tld)
+// getRDAPServer determines the appropriate RDAP server for a domain
+func (c *Client) getRDAPServer(domain string) (string, error) {
+	servers, err := c.getRDAPServers(context.Background(), domain)
+	if err != nil {
+		return "", err
+	}
+	return servers[0], nil
 }
 
-// queryRDAPBytes performs the actual RDAP query and returns raw bytes
-func (c *Client) queryRDAP(domain, server string) ([]byte, error) {
-	// For .ch domains, the server URL already includes the full path
-	if strings.Contains(server, "rdap.nic.ch") {
-		req, err := http.NewRequest("GET", server, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Accept", "application/rdap+json;charset=UTF-8")
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("RDAP query failed: %w", err)
-		}
-		defer resp.Body.Close()
+// getRDAPServers determines the RDAP servers of a domain, in the order they should be tried
+func (c *Client) getRDAPServers(ctx context.Context, domain string) ([]string, error) {
+	// Extract TLD from domain
+	tld := getTLD(domain)
+	if tld == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDomain, domain)
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read RDAP response: %w", err)
-		}
+	// A URL template override is used as is
+	if server, ok := c.serverOverrides[tld]; ok && isURLTemplate(server) {
+		return []string{server}, nil
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("RDAP query failed with status %d: %s", resp.StatusCode, string(body))
-		}
+	return c.getHostServers(ctx, ObjectDomain, domain)
+}
+
+// normalizeDomain returns the lowercase A-label form of a domain name without
+// its trailing dot, so "Example.COM." and "bücher.de" are queried and cached
+// as "example.com" and "xn--bcher-kva.de". Names that are not valid IDNs are
+// only lowercased. Input pasted from a URL or an address is reduced to its
+// host, so "https://user@Example.COM.:443/path" is "example.com" too.
+func normalizeDomain(domain string) string {
+	domain = strings.TrimSpace(domain)
+	if _, rest, ok := strings.Cut(domain, "://"); ok {
+		domain = rest
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	if i := strings.LastIndex(domain, "@"); i >= 0 {
+		domain = domain[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	if ascii, err := ToASCII(domain); err == nil {
+		return ascii
+	}
+	return strings.ToLower(domain)
+}
 
-		return body, nil
+// getTLDServer determines the RDAP base URL serving a TLD
+func (c *Client) getTLDServer(tld string) (string, error) {
+	servers, err := c.getTLDServers(context.Background(), tld)
+	if err != nil {
+		return "", err
 	}
+	return servers[0], nil
+}
 
-	// For other domains, construct the query URL
-	queryURL := server + "domain/" + domain
+// getTLDServers determines the RDAP base URLs serving a TLD, in order of
+// preference, servers that failed recently last
+func (c *Client) getTLDServers(ctx context.Context, tld string) ([]string, error) {
+	// Overrides take precedence over the bootstrap data
+	if server, ok := c.serverOverrides[tld]; ok && !isURLTemplate(server) {
+		return []string{normalizeServer(server)}, nil
+	}
+	if c.noRDAPTLDs[tld] {
+		return nil, fmt.Errorf("TLD %s is listed as without RDAP: %w", tld, ErrNoRDAPService)
+	}
 
-	req, err := http.NewRequest("GET", queryURL, nil)
+	// Get bootstrap data
+	bootstrap, err := c.getDNSBootstrap(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+	}
+
+	// Find the appropriate servers for this TLD, in order of preference
+	servers := c.selectServers(tld, bootstrap)
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("%w %s: %w", ErrNoServerForTLD, tld, ErrNoRDAPService)
 	}
-	req.Header.Set("Accept", "application/rdap+json;charset=UTF-8")
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.httpClient.Do(req)
+
+	return c.orderServers(servers), nil
+}
+
+// queryRDAPBytes performs the actual RDAP query and returns raw bytes
+func (c *Client) queryRDAP(domain, server string) ([]byte, error) {
+	resp, err := c.queryRDAPResponse(context.Background(), domain, server, nil)
 	if err != nil {
-		return nil, fmt.Errorf("RDAP query failed: %w", err)
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// queryRDAPResponse performs the actual RDAP query with the given additional
+// headers and returns the response with its metadata
+func (c *Client) queryRDAPResponse(ctx context.Context, domain, server string, header http.Header) (*Response, error) {
+	// A URL template already includes the full path
+	if isURLTemplate(server) {
+		return c.doRequestWithHeader(ctx, server, templateURL(server, domain), header)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// For base URLs, construct the query URL
+	return c.doRequestWithHeader(ctx, server, objectURL(server, "domain", domain), header)
+}
+
+// doQuery sends an RDAP request to the given URL and returns the raw response body
+func (c *Client) doQuery(queryURL string) ([]byte, error) {
+	resp, err := c.doRequest("", queryURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read RDAP response: %w", err)
+		return nil, err
 	}
+	return resp.Body, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("RDAP query failed with status %d: %s", resp.StatusCode, string(body))
+// normalizeServer ensures the server base URL ends with a slash
+func normalizeServer(server string) string {
+	if !strings.HasSuffix(server, "/") {
+		server += "/"
 	}
+	return server
+}
 
-	return body, nil
+// isURLTemplate reports whether the server is a URL template rather than a base URL
+func isURLTemplate(server string) bool {
+	return strings.Contains(server, domainPlaceholder)
+}
+
+// copyServerOverrides returns a copy of the given server overrides
+func copyServerOverrides(overrides map[string]string) map[string]string {
+	result := make(map[string]string, len(overrides))
+	for tld, server := range overrides {
+		result[tld] = server
+	}
+	return result
 }
 
 // getTLD extracts the TLD from a domain
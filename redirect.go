@@ -0,0 +1,165 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// defaultMaxRedirects is the number of redirects followed by default
+const defaultMaxRedirects = 10
+
+// SetMaxRedirects sets how many redirects a query follows, 10 by default.
+// Thin registries often redirect to the registrar RDAP server with 301, 302,
+// 303, 307 or 308; every redirect is re-issued with the RDAP headers. A
+// query redirected more often fails with ErrTooManyRedirects, and one
+// redirected back to a URL it already visited fails with ErrRedirectLoop.
+// Zero or less returns redirect responses as they are, with a *StatusError.
+func (c *Client) SetMaxRedirects(n int) *Client {
+	c.maxRedirects = max(n, 0)
+	return c
+}
+
+// SetRedirectHTTPSOnly refuses redirects to anything but https URLs, even on
+// loopback hosts or when insecure servers are allowed. Such redirects fail
+// with ErrInsecureServer.
+func (c *Client) SetRedirectHTTPSOnly(enabled bool) *Client {
+	c.redirectHTTPSOnly = enabled
+	return c
+}
+
+// RedirectPolicy decides whether a query answered at from may follow a
+// redirect to the target URL; a non-nil error denies the redirect
+type RedirectPolicy func(from, to *url.URL) error
+
+// SetRedirectPolicy sets the policy checked before following each redirect,
+// after the built-in safeguards. Denied redirects fail with an error matching
+// both ErrRedirectDenied and the error of the policy. A nil policy, the
+// default, allows every target.
+func (c *Client) SetRedirectPolicy(policy RedirectPolicy) *Client {
+	c.redirectPolicy = policy
+	return c
+}
+
+// AllowRedirectHosts returns a policy allowing redirects to the given hosts
+// only. A host starting with a dot, such as ".example.com", allows every
+// subdomain of example.com.
+func AllowRedirectHosts(hosts ...string) RedirectPolicy {
+	return func(from, to *url.URL) error {
+		host := strings.ToLower(to.Hostname())
+		for _, allowed := range hosts {
+			allowed = strings.ToLower(allowed)
+			if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("host %s is not allowed", host)
+	}
+}
+
+// SameHostRedirects is a policy only allowing redirects on the host that answered
+var SameHostRedirects RedirectPolicy = func(from, to *url.URL) error {
+	if !strings.EqualFold(from.Hostname(), to.Hostname()) {
+		return fmt.Errorf("host %s differs from %s", to.Hostname(), from.Hostname())
+	}
+	return nil
+}
+
+// isRedirectStatus reports whether the status is a redirect to follow
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// nextRedirect returns the URL a response redirects the query to, or nil
+// when the response is final. visited lists the URLs of the query so far,
+// the one that answered last.
+func (c *Client) nextRedirect(queryURL string, resp *http.Response, visited []string) (*url.URL, error) {
+	if c.maxRedirects == 0 || !isRedirectStatus(resp.StatusCode) {
+		return nil, nil
+	}
+	next, err := resp.Location()
+	if err != nil {
+		// A redirect without a usable Location is returned as is
+		return nil, nil
+	}
+
+	target := next.String()
+	if len(visited) > c.maxRedirects {
+		return nil, fmt.Errorf("RDAP query to %s stopped after %d redirects: %w", queryURL, c.maxRedirects, ErrTooManyRedirects)
+	}
+	if slices.Contains(visited, target) {
+		return nil, fmt.Errorf("RDAP query to %s redirected back to %s: %w", queryURL, target, ErrRedirectLoop)
+	}
+	if c.redirectHTTPSOnly && !strings.EqualFold(next.Scheme, "https") {
+		return nil, fmt.Errorf("RDAP query to %s not redirected: refusing non-https target %s: %w", queryURL, target, ErrInsecureServer)
+	}
+	if err := c.checkServerURL(target); err != nil {
+		return nil, err
+	}
+	if c.redirectPolicy != nil {
+		if err := c.redirectPolicy(resp.Request.URL, next); err != nil {
+			return nil, fmt.Errorf("RDAP query to %s not redirected to %s: %w: %w", queryURL, target, ErrRedirectDenied, err)
+		}
+	}
+	return next, nil
+}
+
+// doFollowingRedirects sends a request outside of RDAP queries, such as a
+// bootstrap download, following up to defaultMaxRedirects redirects with the
+// same headers, as the default HTTP client leaves redirects to this package.
+// Every target is checked against the server policy and SetRedirectHTTPSOnly
+// before it is requested.
+func (c *Client) doFollowingRedirects(req *http.Request) (*http.Response, error) {
+	for range defaultMaxRedirects {
+		resp, err := c.do(req)
+		if err != nil || !isRedirectStatus(resp.StatusCode) {
+			return resp, err
+		}
+		next, err := resp.Location()
+		if err != nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		target := next.String()
+		if c.redirectHTTPSOnly && !strings.EqualFold(next.Scheme, "https") {
+			return nil, fmt.Errorf("request to %s not redirected: refusing non-https target %s: %w", req.URL, target, ErrInsecureServer)
+		}
+		if err := c.checkPolicy(target); err != nil {
+			return nil, fmt.Errorf("request to %s not redirected: %w", req.URL, err)
+		}
+
+		header := req.Header
+		req, err = http.NewRequestWithContext(req.Context(), req.Method, target, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header = header.Clone()
+	}
+	return nil, fmt.Errorf("request to %s stopped after %d redirects: %w", req.URL, defaultMaxRedirects, ErrTooManyRedirects)
+}
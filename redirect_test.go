@@ -0,0 +1,249 @@
+package rdap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newRedirectClient returns a mock domain client and the base URL of its RDAP server
+func newRedirectClient(t *testing.T, handler http.HandlerFunc) (*Client, string) {
+	t.Helper()
+
+	client := newMockDomainClient(t, handler)
+	server, err := client.getRDAPServer("example.com")
+	if err != nil {
+		t.Fatalf("getRDAPServer failed: %v", err)
+	}
+	return client, server
+}
+
+func TestRedirectReissuesRDAPHeaders(t *testing.T) {
+	client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/domain/example.com" {
+			http.Redirect(w, r, "/registrar/domain/example.com", http.StatusTemporaryRedirect)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Accept"), "application/rdap+json") {
+			http.Error(w, "unexpected accept header", http.StatusNotAcceptable)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	resp, err := client.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if resp.URL != server+"registrar/domain/example.com" {
+		t.Errorf("Unexpected final URL %s", resp.URL)
+	}
+	if len(resp.Redirects) != 1 || resp.Redirects[0] != server+"domain/example.com" {
+		t.Errorf("Unexpected redirect chain: %v", resp.Redirects)
+	}
+}
+
+func TestRedirectDropsCredentialsAcrossHosts(t *testing.T) {
+	var leaked atomic.Int32
+	registrar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" || r.Header.Get("X-Api-Key") != "" {
+			leaked.Add(1)
+		}
+		if !strings.HasPrefix(r.Header.Get("Accept"), "application/rdap+json") {
+			http.Error(w, "unexpected accept header", http.StatusNotAcceptable)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}))
+	defer registrar.Close()
+
+	client, _ := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			http.Error(w, "missing credentials", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/domain/example.com" {
+			// A redirect on the same host keeps the credentials
+			http.Redirect(w, r, "/moved/example.com", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, registrar.URL+"/domain/example.com", http.StatusFound)
+	})
+
+	resp, err := client.RDAPResponse("example.com", WithHeader("Authorization", "Bearer secret"), WithHeader("X-Api-Key", "secret"))
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if !strings.HasPrefix(resp.URL, registrar.URL) || len(resp.Redirects) != 2 {
+		t.Errorf("Expected the query to end at the registrar, got %s after %v", resp.URL, resp.Redirects)
+	}
+	if got := leaked.Load(); got != 0 {
+		t.Errorf("Expected the registrar not to receive the credentials, got %d requests with them", got)
+	}
+}
+
+func TestTooManyRedirects(t *testing.T) {
+	var hits atomic.Int32
+	client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, fmt.Sprintf("/hop%d", hits.Add(1)), http.StatusFound)
+	})
+	client.SetMaxRedirects(3)
+
+	if _, err := client.doRequest(server, server+"start"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("Expected ErrTooManyRedirects, got %v", err)
+	}
+	if got := hits.Load(); got != 4 {
+		t.Errorf("Expected 3 redirects followed, got %d requests", got)
+	}
+}
+
+func TestRedirectLoop(t *testing.T) {
+	client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/a" {
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+			return
+		}
+		http.Redirect(w, r, "/a", http.StatusPermanentRedirect)
+	})
+
+	if _, err := client.doRequest(server, server+"a"); !errors.Is(err, ErrRedirectLoop) {
+		t.Errorf("Expected ErrRedirectLoop, got %v", err)
+	}
+}
+
+func TestRedirectsDisabled(t *testing.T) {
+	client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusSeeOther)
+	})
+	client.SetMaxRedirects(0)
+
+	resp, err := client.doRequest(server, server+"a")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusSeeOther {
+		t.Fatalf("Expected 303 status error, got %v", err)
+	}
+	if resp.Header.Get("Location") != "/b" {
+		t.Errorf("Expected the redirect response, got %+v", resp)
+	}
+}
+
+func TestRedirectInsecureTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		configure func(*Client)
+	}{
+		{"plain http host", "http://rdap.example/domain/example.com", func(*Client) {}},
+		{"https only", "/b", func(c *Client) { c.SetRedirectHTTPSOnly(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tt.target, http.StatusFound)
+			})
+			tt.configure(client)
+
+			if _, err := client.doRequest(server, server+"a"); !errors.Is(err, ErrInsecureServer) {
+				t.Errorf("Expected ErrInsecureServer, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBootstrapRedirect(t *testing.T) {
+	bootstrap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old.json" {
+			http.Redirect(w, r, "/dns.json", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte(`{"services": [[["com"], ["https://rdap.example.com/"]]]}`))
+	}))
+	defer bootstrap.Close()
+
+	client := NewClient().SetEmbeddedFallback(false).SetBootstrapURL(bootstrap.URL + "/old.json")
+	if server, err := client.getTLDServer("com"); err != nil || server != "https://rdap.example.com/" {
+		t.Errorf("Expected redirected bootstrap to be loaded, got %q, %v", server, err)
+	}
+}
+
+func TestBootstrapRedirectServerPolicy(t *testing.T) {
+	var insecure atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		insecure.Add(1)
+		w.Write([]byte(`{"services": [[["com"], ["https://rdap.example.com/"]]]}`))
+	}))
+	defer target.Close()
+	bootstrap := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/dns.json", http.StatusMovedPermanently)
+	}))
+	defer bootstrap.Close()
+
+	client := NewClient().SetEmbeddedFallback(false).SetTransport(bootstrap.Client().Transport).
+		SetBootstrapURL(bootstrap.URL + "/dns.json").
+		SetServerPolicy(ServerPolicy{Schemes: []string{"https"}})
+	if _, err := client.getTLDServer("com"); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected ErrServerDenied for a redirect to http, got %v", err)
+	}
+	if got := insecure.Load(); got != 0 {
+		t.Errorf("Expected the http target not to be fetched, got %d requests", got)
+	}
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	var hits atomic.Int32
+	client, server := newRedirectClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "https://rdap.registrar.example/domain/example.com", http.StatusFound)
+	})
+	client.SetRedirectPolicy(AllowRedirectHosts(".trusted.example"))
+
+	_, err := client.doRequest(server, server+"a")
+	if !errors.Is(err, ErrRedirectDenied) {
+		t.Fatalf("Expected ErrRedirectDenied, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "rdap.registrar.example is not allowed") {
+		t.Errorf("Expected the policy error in %q", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("Expected no request to the denied target, got %d requests", got)
+	}
+}
+
+func TestAllowRedirectHosts(t *testing.T) {
+	policy := AllowRedirectHosts("rdap.example.com", ".registrar.example")
+	from, _ := url.Parse("https://rdap.example.com/")
+
+	tests := map[string]bool{
+		"https://rdap.example.com/domain/a.com":       true,
+		"https://RDAP.Example.com/domain/a.com":       true,
+		"https://rdap.registrar.example/domain/a.com": true,
+		"https://registrar.example/domain/a.com":      false,
+		"https://evilregistrar.example/domain/a.com":  false,
+		"https://other.example.com/domain/a.com":      false,
+	}
+	for target, allowed := range tests {
+		to, _ := url.Parse(target)
+		if err := policy(from, to); (err == nil) != allowed {
+			t.Errorf("policy(%s) = %v, want allowed %v", target, err, allowed)
+		}
+	}
+}
+
+func TestSameHostRedirects(t *testing.T) {
+	from, _ := url.Parse("https://rdap.example.com/domain/a.com")
+	same, _ := url.Parse("https://rdap.example.com/v2/domain/a.com")
+	other, _ := url.Parse("https://rdap.other.example/domain/a.com")
+
+	if err := SameHostRedirects(from, same); err != nil {
+		t.Errorf("Expected same host to be allowed, got %v", err)
+	}
+	if err := SameHostRedirects(from, other); err == nil {
+		t.Error("Expected another host to be denied")
+	}
+}
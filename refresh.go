@@ -0,0 +1,242 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// refreshJitter is the fraction of the refresh interval randomly added or
+// removed so that fleets of clients do not refresh in lockstep
+const refreshJitter = 0.1
+
+// bootstrapRefresher is a running background bootstrap refresh loop
+type bootstrapRefresher struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartBootstrapRefresher starts a goroutine that refreshes the cached
+// bootstrap data every interval, with jitter, so long-running services keep
+// it warm. Every cached bootstrap file is refreshed, and the domain bootstrap
+// is loaded when it is not cached yet. A running refresher is replaced. Call Close to stop it.
+func (c *Client) StartBootstrapRefresher(interval time.Duration) *Client {
+	c.stopRefresher()
+	if interval <= 0 {
+		return c
+	}
+
+	refresher := &bootstrapRefresher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	c.bootstrapMu.Lock()
+	c.refresher = refresher
+	c.bootstrapMu.Unlock()
+
+	go c.runBootstrapRefresher(refresher, interval)
+	return c
+}
+
+// Close stops the background bootstrap refresher, if any
+func (c *Client) Close() error {
+	c.stopRefresher()
+	return nil
+}
+
+// stopRefresher stops the running refresher and waits for it to exit
+func (c *Client) stopRefresher() {
+	c.bootstrapMu.Lock()
+	refresher := c.refresher
+	c.refresher = nil
+	c.bootstrapMu.Unlock()
+
+	if refresher != nil {
+		close(refresher.stop)
+		<-refresher.done
+	}
+}
+
+// runBootstrapRefresher refreshes the bootstrap data until stopped
+func (c *Client) runBootstrapRefresher(refresher *bootstrapRefresher, interval time.Duration) {
+	defer close(refresher.done)
+
+	timer := time.NewTimer(jitter(interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-refresher.stop:
+			return
+		case <-timer.C:
+			c.refreshCachedBootstraps()
+			timer.Reset(jitter(interval))
+		}
+	}
+}
+
+// refreshCachedBootstraps revalidates every cached bootstrap file, and loads
+// the domain bootstrap from the first mirror that answers when no mirror has
+// it cached. Failures keep the cached copy for the next attempt.
+func (c *Client) refreshCachedBootstraps() {
+	if c.disableCache {
+		return
+	}
+
+	c.bootstrapMu.Lock()
+	var urls []string
+	domainCached := false
+	for url := range c.bootstrapCache {
+		urls = append(urls, url)
+		domainCached = domainCached || slices.Contains(c.mirrorURLs(), url)
+	}
+	c.bootstrapMu.Unlock()
+
+	for _, url := range urls {
+		c.refreshBootstrap(url)
+	}
+	if !domainCached {
+		c.getBootstrapDataContext(context.Background())
+	}
+}
+
+// refreshBootstrap revalidates the bootstrap file at the given URL and
+// replaces the cached copy
+func (c *Client) refreshBootstrap(url string) (*RDAPBootstrap, error) {
+	return c.refreshBootstrapContext(context.Background(), url)
+}
+
+// refreshBootstrapContext is refreshBootstrap giving up when ctx is done
+func (c *Client) refreshBootstrapContext(ctx context.Context, url string) (*RDAPBootstrap, error) {
+	c.bootstrapMu.Lock()
+	cached := c.bootstrapCache[url]
+	c.bootstrapMu.Unlock()
+
+	fetched, err := c.fetchBootstrapEntry(ctx, url, cached)
+	if err != nil {
+		return nil, err
+	}
+	c.storeBootstrap(url, fetched)
+
+	return fetched.data, nil
+}
+
+// storeBootstrap replaces the cached copy of the bootstrap file at the given URL
+func (c *Client) storeBootstrap(url string, entry *bootstrapEntry) {
+	c.bootstrapMu.Lock()
+	c.bootstrapCache[url] = entry
+	c.bootstrapMu.Unlock()
+}
+
+// RefreshBootstrap forces a full re-fetch of the domain bootstrap data and of
+// every other cached registry, and swaps them in once fetched. It returns the
+// publication date of the new domain bootstrap data, zero when it has none.
+// On failure the cached data is kept. Nothing is cached when caching is
+// disabled.
+func (c *Client) RefreshBootstrap(ctx context.Context) (time.Time, error) {
+	var (
+		bootstrap *bootstrapEntry
+		mirror    string
+		err       error
+	)
+	for _, url := range c.orderedMirrors() {
+		bootstrap, err = c.fetchBootstrapEntry(ctx, url, nil)
+		if ctx.Err() != nil {
+			// A cancelled refresh says nothing about the mirror
+			return time.Time{}, fmt.Errorf("failed to refresh bootstrap data: %w", err)
+		}
+		c.recordMirror(url, err)
+		if err == nil {
+			mirror = url
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to refresh bootstrap data: %w", err)
+	}
+
+	c.bootstrapMu.Lock()
+	var others []string
+	for url := range c.bootstrapCache {
+		if !slices.Contains(c.mirrorURLs(), url) {
+			others = append(others, url)
+		}
+	}
+	c.bootstrapMu.Unlock()
+
+	fetched := map[string]*bootstrapEntry{mirror: bootstrap}
+	for _, url := range others {
+		entry, err := c.fetchBootstrapEntry(ctx, url, nil)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to refresh bootstrap %s: %w", url, err)
+		}
+		fetched[url] = entry
+	}
+
+	if !c.disableCache {
+		c.bootstrapMu.Lock()
+		for url, entry := range fetched {
+			c.bootstrapCache[url] = entry
+		}
+		c.bootstrapMu.Unlock()
+	}
+
+	if bootstrap.data.Publication == "" {
+		return time.Time{}, nil
+	}
+	return bootstrap.data.PublicationTime()
+}
+
+// Preload fetches and caches the five bootstrap registries up front, so
+// services fail fast at startup and the first queries do not wait on them
+func (c *Client) Preload(ctx context.Context) error {
+	if _, err := c.RefreshBootstrap(ctx); err != nil {
+		return err
+	}
+
+	for _, url := range []string{c.ipv4BootstrapURL, c.ipv6BootstrapURL, c.asnBootstrapURL, c.tagsBootstrapURL} {
+		c.bootstrapMu.Lock()
+		_, ok := c.bootstrapCache[url]
+		c.bootstrapMu.Unlock()
+		if ok {
+			continue
+		}
+
+		entry, err := c.fetchBootstrapEntry(ctx, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to preload bootstrap %s: %w", url, err)
+		}
+		c.storeBootstrap(url, entry)
+	}
+
+	return nil
+}
+
+// jitter returns the interval randomly shifted by up to refreshJitter of its length
+func jitter(interval time.Duration) time.Duration {
+	spread := int64(float64(interval) * refreshJitter)
+	if spread <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int64N(2*spread+1)-spread)
+}
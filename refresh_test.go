@@ -0,0 +1,194 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBootstrapRefresher(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server).StartBootstrapRefresher(10 * time.Millisecond)
+	defer client.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hits.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if hits.Load() < 3 {
+		t.Fatalf("Expected repeated bootstrap refreshes, got %d", hits.Load())
+	}
+	client.bootstrapMu.Lock()
+	_, ok := client.bootstrapCache[server.URL+"/dns.json"]
+	client.bootstrapMu.Unlock()
+	if !ok {
+		t.Error("Expected refreshed bootstrap to be cached")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	stopped := hits.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := hits.Load(); got != stopped {
+		t.Errorf("Expected no refresh after Close, got %d more", got-stopped)
+	}
+}
+
+func TestRefreshCachedBootstraps(t *testing.T) {
+	var primaryHits, mirrorHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorHits.Add(1)
+		w.Write([]byte(`{"services": [[["com"], ["https://rdap.example/"]]]}`))
+	}))
+	defer mirror.Close()
+	registries, registryHits := newBootstrapServer(t)
+
+	client := NewClient().SetEmbeddedFallback(false).
+		SetBootstrapURLs([]string{primary.URL, mirror.URL}).
+		SetIPv4BootstrapURL(registries.URL + "/ipv4.json")
+
+	// A cold client loads the domain bootstrap through its mirrors
+	client.refreshCachedBootstraps()
+	if primaryHits.Load() != 1 || mirrorHits.Load() != 1 {
+		t.Fatalf("Expected the domain bootstrap to be loaded from the mirror, got %d and %d requests", primaryHits.Load(), mirrorHits.Load())
+	}
+	if _, err := client.loadBootstrap(registries.URL + "/ipv4.json"); err != nil {
+		t.Fatalf("Loading the IPv4 bootstrap failed: %v", err)
+	}
+
+	// Every cached file is refreshed, and the failing primary is left alone
+	client.refreshCachedBootstraps()
+	if primaryHits.Load() != 1 || mirrorHits.Load() != 2 {
+		t.Errorf("Expected the cached mirror copy to be refreshed, got %d and %d requests", primaryHits.Load(), mirrorHits.Load())
+	}
+	if got := registryHits.Load(); got != 2 {
+		t.Errorf("Expected the cached IPv4 bootstrap to be refreshed, got %d requests", got)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	interval := time.Second
+	for i := 0; i < 100; i++ {
+		got := jitter(interval)
+		if got < 900*time.Millisecond || got > 1100*time.Millisecond {
+			t.Fatalf("jitter(%v) = %v, outside the 10%% spread", interval, got)
+		}
+	}
+	if got := jitter(time.Nanosecond); got != time.Nanosecond {
+		t.Errorf("Expected tiny interval to be kept, got %v", got)
+	}
+}
+
+func TestRefreshBootstrap(t *testing.T) {
+	var version atomic.Int32
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// Conditional headers must not be sent on a forced refresh
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("Expected forced refresh to be unconditional")
+		}
+		n := version.Add(1)
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, n))
+		fmt.Fprintf(w, `{"publication": "2025-06-0%dT00:00:00Z", "services": [[["com"], ["https://rdap%d.example/"]]]}`, n, n)
+	}))
+	defer server.Close()
+
+	client := NewClient().SetBootstrapURL(server.URL)
+	if server, _ := client.getTLDServer("com"); server != "https://rdap1.example/" {
+		t.Fatalf("Unexpected initial server %s", server)
+	}
+
+	published, err := client.RefreshBootstrap(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshBootstrap failed: %v", err)
+	}
+	if !published.Equal(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected publication date %v", published)
+	}
+	if server, _ := client.getTLDServer("com"); server != "https://rdap2.example/" {
+		t.Errorf("Expected refreshed server, got %s", server)
+	}
+
+	fail.Store(true)
+	if _, err := client.RefreshBootstrap(context.Background()); err == nil {
+		t.Error("Expected error from failing refresh")
+	}
+	if server, _ := client.getTLDServer("com"); server != "https://rdap2.example/" {
+		t.Errorf("Expected cached data to be kept after a failed refresh, got %s", server)
+	}
+}
+
+func TestRefreshBootstrapCanceled(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.RefreshBootstrap(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if got := hits.Load(); got != 0 {
+		t.Errorf("Expected no request with a canceled context, got %d", got)
+	}
+	if mirrors := client.BootstrapMirrors(); mirrors[0].Failures != 0 {
+		t.Errorf("Expected a canceled refresh not to count as a mirror failure, got %+v", mirrors[0])
+	}
+}
+
+func TestRefreshBootstrapCacheDisabled(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	client := newBootstrapClient(server).SetDisableCache(true)
+
+	if _, err := client.RefreshBootstrap(context.Background()); err != nil {
+		t.Fatalf("RefreshBootstrap failed: %v", err)
+	}
+	client.bootstrapMu.Lock()
+	cached := len(client.bootstrapCache)
+	client.bootstrapMu.Unlock()
+	if cached != 0 {
+		t.Errorf("Expected nothing to be cached with caching disabled, got %d entries", cached)
+	}
+}
+
+func TestPreload(t *testing.T) {
+	server, hits := newBootstrapServer(t)
+	client := newBootstrapClient(server)
+
+	if err := client.Preload(context.Background()); err != nil {
+		t.Fatalf("Preload failed: %v", err)
+	}
+	if got := hits.Load(); got != 5 {
+		t.Errorf("Expected the five registries to be fetched, got %d requests", got)
+	}
+
+	if _, err := client.LoadBootstrap(); err != nil {
+		t.Fatalf("LoadBootstrap failed: %v", err)
+	}
+	if got := hits.Load(); got != 5 {
+		t.Errorf("Expected queries to use the preloaded data, got %d requests", got)
+	}
+}
+
+func TestPreloadFailure(t *testing.T) {
+	server, _ := newBootstrapServer(t)
+	client := newBootstrapClient(server).SetObjectTagsBootstrapURL(server.URL + "/missing.json")
+
+	if err := client.Preload(context.Background()); err == nil {
+		t.Error("Expected Preload to fail on an unavailable registry")
+	}
+}
@@ -0,0 +1,357 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Response is an RDAP response with the metadata of the query that produced it
+type Response struct {
+	// Body is the raw response body
+	Body []byte
+	// Domain is the normalized domain a domain lookup queried, after
+	// reducing URLs to their host, converting IDNs and applying the public
+	// suffix list. It is empty for other queries.
+	Domain string
+	// Server is the RDAP base URL selected for the query
+	Server string
+	// URL is the URL that answered, after any redirects
+	URL string
+	// StatusCode is the HTTP status code of the final response
+	StatusCode int
+	// Header holds the HTTP headers of the final response
+	Header http.Header
+	// Redirects lists the URLs that redirected the query, in request order
+	Redirects []string
+	// Duration is the time spent sending the request and reading the body
+	Duration time.Duration
+	// Source is the source of a domain response: SourceRegistry,
+	// SourceRegistrar, SourceAggregator or the one of a custom fallback
+	// step. It is empty for other queries and for WithServer lookups.
+	Source string
+	// Cached reports whether the body was served from the response cache,
+	// in which case only Body, Domain, StatusCode, URL, Source and Age are set
+	Cached bool
+	// Stale reports whether a cached response past its freshness lifetime
+	// was served because the query failed or the client is offline
+	Stale bool
+	// Age is the time since a cached response was fetched or revalidated
+	Age time.Duration
+}
+
+// doRequest sends an RDAP request to the given URL and returns the response
+// with its metadata. On a non-success status the response is returned along
+// with a *StatusError.
+func (c *Client) doRequest(server, queryURL string) (*Response, error) {
+	return c.doRequestWithHeader(context.Background(), server, queryURL, nil)
+}
+
+// doRequestWithHeader sends an RDAP request bound to ctx with additional
+// headers, such as the validators of a conditional request
+func (c *Client) doRequestWithHeader(ctx context.Context, server, queryURL string, header http.Header) (*Response, error) {
+	if c.offline {
+		return nil, fmt.Errorf("RDAP query to %s not sent: %w", queryURL, ErrOffline)
+	}
+	if err := c.checkServerURL(queryURL); err != nil {
+		return nil, err
+	}
+	if server != "" && !c.allowServer(server) {
+		return nil, fmt.Errorf("RDAP query to %s not sent: %w", queryURL, ErrCircuitOpen)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", queryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	for key, values := range rdapHeader() {
+		req.Header[key] = values
+	}
+
+	// Retry rate limited queries while the wait fits in the allowed time
+	deadline := time.Now().Add(c.rateLimitWait)
+	info := RequestInfo{Context: ctx, Server: server, URL: queryURL, Attempt: 1}
+	result, err := c.send(info, req)
+	for {
+		wait, ok := retryDelay(result, deadline)
+		if !ok || !c.allowRetry() {
+			break
+		}
+		c.onRetry(RetryInfo{Context: ctx, Reason: RetryRateLimited, Query: queryURL, Next: server, Wait: wait, Err: err})
+		if !sleepContext(ctx, wait) {
+			break
+		}
+		info.Attempt++
+		result, err = c.send(info, req)
+	}
+	if server != "" {
+		c.recordServer(ctx, server, result, err)
+	}
+	return result, err
+}
+
+// send sends one attempt of a prepared RDAP request, running the request hooks
+func (c *Client) send(info RequestInfo, req *http.Request) (*Response, error) {
+	c.recordRequest()
+	traceRequest(info)
+	c.onRequest(info)
+	result, err := c.sendRequest(info.Server, info.URL, req)
+	c.onResponse(info, result, err)
+	return result, err
+}
+
+// rdapHeader returns the headers every RDAP request is sent with
+func rdapHeader() http.Header {
+	return http.Header{
+		"Accept":       {"application/rdap+json;charset=UTF-8"},
+		"Content-Type": {"application/json"},
+	}
+}
+
+// sendRequest sends a prepared RDAP request, following redirects, and reads its response
+func (c *Client) sendRequest(server, queryURL string, req *http.Request) (*Response, error) {
+	initial := req
+	var redirects []string
+	var duration time.Duration
+	for {
+		resp, body, elapsed, err := c.roundTrip(queryURL, req)
+		if err != nil {
+			return nil, err
+		}
+		duration += elapsed
+
+		// Redirects followed by a custom HTTP client come first
+		redirects = append(redirects, redirectChain(resp)...)
+		finalURL := req.URL.String()
+		if resp.Request != nil && resp.Request.URL != nil {
+			finalURL = resp.Request.URL.String()
+		}
+
+		next, err := c.nextRedirect(queryURL, resp, append(redirects, finalURL))
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			result := &Response{
+				Body:       body,
+				Server:     server,
+				URL:        finalURL,
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Redirects:  redirects,
+				Duration:   duration,
+			}
+			if err := nonRDAPError(result); err != nil {
+				return result, err
+			}
+			return result, result.statusError()
+		}
+
+		// Re-issue the query with the RDAP headers at the redirect target. As
+		// with net/http, credentials and the headers of the lookup only go to
+		// the host the query was sent to.
+		redirects = append(redirects, finalURL)
+		req, err = http.NewRequestWithContext(req.Context(), "GET", next.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if strings.EqualFold(next.Host, initial.URL.Host) {
+			req.Header = initial.Header.Clone()
+		} else {
+			req.Header = rdapHeader()
+		}
+	}
+}
+
+// roundTrip sends a single request within the rate and concurrency limits
+// and reads its response body
+func (c *Client) roundTrip(queryURL string, req *http.Request) (*http.Response, []byte, time.Duration, error) {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, nil, 0, fmt.Errorf("RDAP query to %s not sent: %w", queryURL, err)
+		}
+	}
+	release, err := c.acquireSlot(req.Context())
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("RDAP query to %s not sent: %w", queryURL, err)
+	}
+	defer release()
+
+	start := time.Now()
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("RDAP query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBodyContext(req.Context(), resp.Body, c.maxResponseSize)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to read RDAP response from %s: %w", req.URL, err)
+	}
+	if resp.Request == nil {
+		resp.Request = req
+	}
+	return resp, body, time.Since(start), nil
+}
+
+// defaultMaxResponseSize is the largest response body read by default
+const defaultMaxResponseSize = 16 << 20
+
+// SetMaxResponseSize sets the largest response body the client reads, 16 MiB
+// by default, so a misbehaving server can't make it buffer unbounded data.
+// Larger RDAP responses and bootstrap files fail with ErrResponseTooLarge.
+// Zero or less sets no limit.
+func (c *Client) SetMaxResponseSize(bytes int64) *Client {
+	c.maxResponseSize = bytes
+	return c
+}
+
+// readBody reads a response body of at most limit bytes, or of any size when
+// limit is zero or less
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("body exceeds %d bytes: %w", limit, ErrResponseTooLarge)
+	}
+	return body, nil
+}
+
+// readBodyContext reads a response body like readBody, closing it when ctx
+// is done so that a read blocked on a stalled transfer is aborted and the
+// connection freed, whatever the HTTP client or transport
+func readBodyContext(ctx context.Context, body io.ReadCloser, limit int64) ([]byte, error) {
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+
+	data, err := readBody(contextReader{ctx: ctx, r: body}, limit)
+	if err != nil && ctx.Err() != nil {
+		// Report the cancellation rather than the read on the closed body
+		return nil, ctx.Err()
+	}
+	return data, err
+}
+
+// statusError returns a *StatusError for a non-success response, or nil
+func (r *Response) statusError() error {
+	if !isSuccess(r.StatusCode) {
+		statusErr := &StatusError{StatusCode: r.StatusCode, Body: string(r.Body)}
+		if r.StatusCode == http.StatusTooManyRequests || r.StatusCode == http.StatusServiceUnavailable {
+			statusErr.RetryAfter, _ = parseRetryAfter(r.Header.Get("Retry-After"), time.Now())
+		}
+		return statusErr
+	}
+	return nil
+}
+
+// isSuccess reports whether a status is a 2xx success, as proxies in front of
+// some registries answer 203 Non-Authoritative Information or other 2xx
+// statuses besides 200
+func isSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// maxSnippetSize bounds the snippet of a non-RDAP page quoted in errors
+const maxSnippetSize = 200
+
+// nonRDAPError returns an ErrNonRDAPResponse error when a successful answer is
+// an HTML page, detected from its Content-Type or its first byte
+func nonRDAPError(resp *Response) error {
+	if !isSuccess(resp.StatusCode) {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body := bytes.TrimSpace(resp.Body)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" && !bytes.HasPrefix(body, []byte("<")) {
+		return nil
+	}
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+	}
+	return fmt.Errorf("%w from %s (%s): %s", ErrNonRDAPResponse, resp.URL, mediaType, pageSnippet(body))
+}
+
+// pageSnippet returns the title of an HTML page, or the start of its text
+func pageSnippet(page []byte) string {
+	text := string(page)
+	lower := strings.ToLower(text)
+	if start := strings.Index(lower, "<title>"); start >= 0 {
+		if end := strings.Index(lower[start:], "</title>"); end >= 0 {
+			text = text[start+len("<title>") : start+end]
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > maxSnippetSize {
+		text = strings.ToValidUTF8(text[:maxSnippetSize], "") + "..."
+	}
+	return text
+}
+
+// isEmptyAnswer reports whether the body of a 200 answer to a domain query is
+// an RDAP error object or an object without any member of a domain, which
+// some registries send for domains they don't have
+func isEmptyAnswer(body []byte) bool {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(body, &object) != nil {
+		return false
+	}
+	if _, ok := object["objectClassName"]; ok {
+		return false
+	}
+	if _, ok := object["errorCode"]; ok {
+		return true
+	}
+	for member := range object {
+		switch member {
+		case "rdapConformance", "notices", "lang":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// redirectChain returns the URLs of the requests that were redirected before
+// the final response, oldest first
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		if prev := req.Response.Request; prev != nil && prev.URL != nil {
+			chain = append([]string{prev.URL.String()}, chain...)
+		}
+	}
+	return chain
+}
@@ -0,0 +1,283 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRDAPResponse(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+
+	resp, err := client.RDAPResponse("Example.COM")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Body), `"ldhName": "example.com"`) {
+		t.Errorf("Unexpected body: %s", resp.Body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/rdap+json" {
+		t.Errorf("Expected RDAP content type, got %q", got)
+	}
+	if !strings.HasSuffix(resp.URL, "/domain/example.com") || resp.Server == "" || !strings.HasPrefix(resp.URL, resp.Server) {
+		t.Errorf("Unexpected server %q and URL %q", resp.Server, resp.URL)
+	}
+	if len(resp.Redirects) != 0 {
+		t.Errorf("Expected no redirects, got %v", resp.Redirects)
+	}
+	if resp.Duration <= 0 {
+		t.Errorf("Expected positive duration, got %v", resp.Duration)
+	}
+}
+
+func TestDoRequestRedirects(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	server, err := client.getRDAPServer("example.com")
+	if err != nil {
+		t.Fatalf("getRDAPServer failed: %v", err)
+	}
+
+	resp, err := client.doRequest(server, server+"a")
+	if err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if len(resp.Redirects) != 2 || resp.Redirects[0] != server+"a" || resp.Redirects[1] != server+"b" {
+		t.Errorf("Unexpected redirect chain: %v", resp.Redirects)
+	}
+	if resp.URL != server+"c" {
+		t.Errorf("Expected final URL %q, got %q", server+"c", resp.URL)
+	}
+}
+
+func TestRDAPResponseNotFound(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	resp, err := client.RDAPResponse("example.com")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected response with status 404, got %+v", resp)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 2048)))
+	}).SetMaxResponseSize(1024)
+
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+
+	// A body of exactly the limit is accepted, and zero disables the limit
+	client.SetMaxResponseSize(2048).SetDisableCache(true)
+	if _, err := client.RDAP("example.com"); errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected a body at the limit to be read, got %v", err)
+	}
+	client.SetMaxResponseSize(0)
+	if _, err := client.RDAP("example.com"); errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected no limit, got %v", err)
+	}
+}
+
+func TestMaxResponseSizeBootstrap(t *testing.T) {
+	bootstrapServer, _ := newBootstrapServer(t)
+	client := NewClient().
+		SetEmbeddedFallback(false).
+		SetBootstrapURL(bootstrapServer.URL + "/dns.json").
+		SetMaxResponseSize(16)
+
+	if _, err := client.getTLDServer("com"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge for the bootstrap file, got %v", err)
+	}
+}
+
+func TestBodyReadCancelled(t *testing.T) {
+	// The body stalls after its first bytes, and its client ignores the context
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	client := NewClient().
+		SetServerOverride("com", "https://rdap.example/").
+		SetHTTPClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+			go writer.Write([]byte(`{"objectClassName": `))
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: reader, Request: r}, nil
+		}))
+
+	start := time.Now()
+	_, err := client.RDAP("example.com", WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the read to stop at the timeout, took %v", elapsed)
+	}
+
+	// The body was closed, releasing the transfer
+	if _, err := writer.Write([]byte("{}")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Expected the body to be closed, got %v", err)
+	}
+}
+
+func TestIsEmptyAnswer(t *testing.T) {
+	tests := []struct {
+		body  string
+		empty bool
+	}{
+		{`{"errorCode": 404, "title": "Not Found"}`, true},
+		{`{}`, true},
+		{`{"rdapConformance": ["rdap_level_0"], "notices": [{"title": "Terms"}]}`, true},
+		{`{"objectClassName": "domain", "ldhName": "example.com"}`, false},
+		{`{"ldhName": "example.com"}`, false},
+		{`not json`, false},
+	}
+	for _, test := range tests {
+		if got := isEmptyAnswer([]byte(test.body)); got != test.empty {
+			t.Errorf("isEmptyAnswer(%s) = %v, expected %v", test.body, got, test.empty)
+		}
+	}
+}
+
+func TestEmptyAnswerNotFound(t *testing.T) {
+	client := newMockDomainClient(t, serveJSON(`{"rdapConformance": ["rdap_level_0"], "errorCode": 404, "title": "Not Found"}`)).
+		SetNegativeCacheTTL(time.Minute)
+
+	resp, err := client.RDAPResponse("missing.com")
+	if !errors.Is(err, ErrDomainNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrDomainNotFound, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the answer translated to a 404, got %d", resp.StatusCode)
+	}
+
+	// The translated answer is negatively cached
+	resp, err = client.RDAPResponse("missing.com")
+	if !errors.Is(err, ErrDomainNotFound) || !resp.Cached {
+		t.Errorf("Expected a cached ErrDomainNotFound, got %+v, %v", resp, err)
+	}
+}
+
+func TestNonRDAPResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"html content type", "text/html; charset=utf-8", "<html><head><title>Down for\n maintenance</title></head><body>Back soon</body></html>"},
+		{"sniffed", "", "  <!DOCTYPE html><html><head><title>Down for maintenance</title></head></html>"},
+		{"mislabeled", "application/rdap+json", "<html><title>Down for maintenance</title></html>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header()["Content-Type"] = []string{test.contentType}
+				w.Write([]byte(test.body))
+			})
+
+			_, err := client.RDAP("example.com")
+			if !errors.Is(err, ErrNonRDAPResponse) {
+				t.Fatalf("Expected ErrNonRDAPResponse, got %v", err)
+			}
+			if !strings.HasSuffix(err.Error(), ": Down for maintenance") {
+				t.Errorf("Expected the page title in the error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPageSnippet(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("blocked ", 100) + "</body></html>"
+	if snippet := pageSnippet([]byte(page)); len(snippet) != maxSnippetSize+len("...") || !strings.HasPrefix(snippet, "<html><body>blocked") {
+		t.Errorf("Unexpected snippet: %q", snippet)
+	}
+}
+
+func TestSuccessStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusPartialContent} {
+		client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+		})
+
+		resp, err := client.RDAPResponse("example.com")
+		if err != nil {
+			t.Fatalf("Expected status %d to succeed, got %v", status, err)
+		}
+		if resp.StatusCode != status {
+			t.Errorf("Expected status %d, got %d", status, resp.StatusCode)
+		}
+
+		// The cached copy is a success, not a negative hit
+		if _, err := client.RDAPResponse("example.com"); err != nil {
+			t.Errorf("Expected the cached copy of status %d to succeed, got %v", status, err)
+		}
+		if stats := client.CacheStats(); stats.Hits != 1 || stats.NegativeHits != 0 {
+			t.Errorf("Unexpected cache stats for status %d: %+v", status, stats)
+		}
+	}
+}
+
+func TestNonSuccessStatuses(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusMultipleChoices, nil},
+		{http.StatusForbidden, nil},
+	}
+	for _, test := range tests {
+		client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+		})
+
+		_, err := client.RDAP("example.com")
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != test.status {
+			t.Errorf("Expected a *StatusError with status %d, got %v", test.status, err)
+		}
+		if test.sentinel != nil && !errors.Is(err, test.sentinel) {
+			t.Errorf("Expected status %d to match %v, got %v", test.status, test.sentinel, err)
+		}
+	}
+}
+
+func TestResponseDomain(t *testing.T) {
+	var path string
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	})
+
+	for _, input := range []string{"https://www.Example.COM./path", "example.com:443"} {
+		resp, err := client.RDAPResponse(input)
+		if err != nil {
+			t.Fatalf("Query of %q failed: %v", input, err)
+		}
+		if resp.Domain != "example.com" {
+			t.Errorf("Expected %q to query example.com, got %q", input, resp.Domain)
+		}
+	}
+	if path != "/domain/example.com" {
+		t.Errorf("Unexpected query path: %s", path)
+	}
+}
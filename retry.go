@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRetryAfter is the wait before retrying a rate limited query whose
+// response has no Retry-After header
+const defaultRetryAfter = time.Second
+
+// SetRateLimitRetry makes the client wait and retry a query answered with
+// HTTP 429 as long as the retry can be sent within maxWait of the first
+// attempt, as asked by the Retry-After header. Zero, the default, returns the
+// rate limit error right away. Either way, a query that stays rate limited
+// fails with a *StatusError matching ErrRateLimited and carrying RetryAfter.
+func (c *Client) SetRateLimitRetry(maxWait time.Duration) *Client {
+	c.rateLimitWait = max(maxWait, 0)
+	return c
+}
+
+// retryDelay returns how long to wait before retrying a rate limited
+// response, or false when it is not rate limited or the retry would be sent
+// after the deadline
+func retryDelay(resp *Response, deadline time.Time) (time.Duration, bool) {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		wait = defaultRetryAfter
+	}
+	if time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+	return wait, true
+}
+
+// sleepContext waits for the given duration and reports whether it elapsed
+// before ctx was done
+func sleepContext(ctx context.Context, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, given either as a
+// number of seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
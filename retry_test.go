@@ -0,0 +1,88 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"-5", 0, true},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := client.RDAP("example.com")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.RetryAfter != 30*time.Second {
+		t.Errorf("Expected RetryAfter of 30s, got %+v", statusErr)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("Expected rate limit not to match ErrNotFound")
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if queries.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}).SetRateLimitRetry(time.Second)
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("Expected 2 queries, got %d", got)
+	}
+}
+
+func TestRateLimitRetryPastDeadline(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}).SetRateLimitRetry(time.Second)
+
+	start := time.Now()
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond || queries.Load() != 1 {
+		t.Errorf("Expected no retry past the deadline, got %d queries", queries.Load())
+	}
+}
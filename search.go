@@ -0,0 +1,322 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ducksify/gordap/types"
+)
+
+// DomainSearchResults is the result of a domain search
+type DomainSearchResults struct {
+	Domains []Domain `json:"domainSearchResults"`
+	Notices []Notice `json:"notices,omitempty"`
+	// Truncated reports whether the server signalled an incomplete result set
+	Truncated bool `json:"-"`
+}
+
+// NameserverSearchResults is the result of a nameserver search
+type NameserverSearchResults struct {
+	Nameservers []Nameserver `json:"nameserverSearchResults"`
+	Notices     []Notice     `json:"notices,omitempty"`
+	// Truncated reports whether the server signalled an incomplete result set
+	Truncated bool `json:"-"`
+}
+
+// EntitySearchResults is the result of an entity search
+type EntitySearchResults struct {
+	Entities []Entity `json:"entitySearchResults"`
+	Notices  []Notice `json:"notices,omitempty"`
+	// Truncated reports whether the server signalled an incomplete result set
+	Truncated bool `json:"-"`
+}
+
+// SearchDomains searches for domains matching the given pattern, e.g. "exampl*.com".
+// The search is sent to the RDAP server of the pattern's TLD.
+func (c *Client) SearchDomains(pattern string) (*DomainSearchResults, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+
+	tld := getTLD(pattern)
+	if tld == "" || strings.Contains(tld, "*") {
+		return nil, fmt.Errorf("search pattern must include a TLD: %s", pattern)
+	}
+
+	server, err := c.getTLDServer(tld)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDAP server for %s: %w", pattern, err)
+	}
+
+	return c.searchDomains(server, url.Values{"name": {pattern}})
+}
+
+// searchDomains sends a domain search with the given parameters to an RDAP server
+func (c *Client) searchDomains(server string, params url.Values) (*DomainSearchResults, error) {
+	body, err := c.doQuery(normalizeServer(server) + "domains?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var results DomainSearchResults
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+	results.Truncated = isTruncated(results.Notices)
+
+	return &results, nil
+}
+
+// SearchNameservers searches the given RDAP server for nameservers matching
+// a host name pattern, e.g. "ns*.example.com"
+func (c *Client) SearchNameservers(server, pattern string) (*NameserverSearchResults, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+
+	return c.searchNameservers(server, url.Values{"name": {pattern}})
+}
+
+// searchNameservers sends a nameserver search with the given parameters to an RDAP server
+func (c *Client) searchNameservers(server string, params url.Values) (*NameserverSearchResults, error) {
+	body, err := c.doQuery(normalizeServer(server) + "nameservers?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var results NameserverSearchResults
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+	results.Truncated = isTruncated(results.Notices)
+
+	return &results, nil
+}
+
+// SearchEntities searches the given RDAP server for entities by full name
+// (byFN, "entities?fn=") or by handle (byHandle, "entities?handle="). Exactly
+// one of the two patterns must be set.
+func (c *Client) SearchEntities(server string, byFN, byHandle string) (*EntitySearchResults, error) {
+	byFN = strings.TrimSpace(byFN)
+	byHandle = strings.TrimSpace(byHandle)
+
+	params := url.Values{}
+	switch {
+	case byFN != "" && byHandle != "":
+		return nil, fmt.Errorf("only one of full name or handle can be searched")
+	case byFN != "":
+		params.Set("fn", byFN)
+	case byHandle != "":
+		params.Set("handle", byHandle)
+	default:
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+
+	body, err := c.doQuery(normalizeServer(server) + "entities?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var results EntitySearchResults
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+	results.Truncated = isTruncated(results.Notices)
+
+	return &results, nil
+}
+
+// ReverseSearchDomains performs an RFC 9536 reverse search on the given RDAP
+// server for domains related to entities matching a full name (byFN) or a
+// handle (byHandle). Exactly one of the two patterns must be set. The server's
+// help response is checked for the "reverse_search" conformance first.
+func (c *Client) ReverseSearchDomains(server string, byFN, byHandle string) (*DomainSearchResults, error) {
+	byFN = strings.TrimSpace(byFN)
+	byHandle = strings.TrimSpace(byHandle)
+
+	params := url.Values{}
+	switch {
+	case byFN != "" && byHandle != "":
+		return nil, fmt.Errorf("only one of full name or handle can be searched")
+	case byFN != "":
+		params.Set("fn", byFN)
+	case byHandle != "":
+		params.Set("handle", byHandle)
+	default:
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+
+	server = normalizeServer(server)
+	supported, err := c.supportsConformance(server, "reverse_search")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reverse search support: %w", err)
+	}
+	if !supported {
+		return nil, fmt.Errorf("server does not support reverse search: %s", server)
+	}
+
+	body, err := c.doQuery(server + "domains/reverse_search/entity?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var results DomainSearchResults
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+	results.Truncated = isTruncated(results.Notices)
+
+	return &results, nil
+}
+
+// supportsConformance reports whether the help response of an RDAP server
+// lists the given rdapConformance value
+func (c *Client) supportsConformance(server, extension string) (bool, error) {
+	body, err := c.doQuery(server + "help")
+	if err != nil {
+		return false, err
+	}
+
+	conformance, err := types.ParseConformance(body)
+	if err != nil {
+		return false, err
+	}
+
+	return conformance.HasExtension(extension), nil
+}
+
+// searchConcurrency is the number of servers NameserverByIP searches at once
+const searchConcurrency = 8
+
+// SetNameserverSearchLimit lets NameserverByIP search up to n servers of the
+// domain bootstrap when it is given no servers. The search is sent to every
+// server, so the fan-out is off by default: zero or less requires explicit
+// servers.
+func (c *Client) SetNameserverSearchLimit(n int) *Client {
+	c.nameserverLimit = max(n, 0)
+	return c
+}
+
+// NameserverByIP searches for nameserver objects using the given IP address.
+// The "nameservers?ip=" search is sent to each of the given RDAP base URLs, or
+// to the first servers listed in the domain bootstrap up to the limit set with
+// SetNameserverSearchLimit when none are given, at most searchConcurrency
+// servers at a time and within the client's rate and concurrency limits.
+// Servers that do not support the search are skipped; an error is only
+// returned when no server answered. A nameserver found by several servers is
+// returned once.
+func (c *Client) NameserverByIP(ip string, servers ...string) ([]Nameserver, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+
+	if len(servers) == 0 {
+		if c.nameserverLimit == 0 {
+			return nil, fmt.Errorf("no RDAP servers given for nameserver search of %s", addr)
+		}
+		bootstrap, err := c.getDNSBootstrap(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBootstrapUnavailable, err)
+		}
+		servers = bootstrapServers(bootstrap)
+		servers = servers[:min(len(servers), c.nameserverLimit)]
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		results  []Nameserver
+		seen     = make(map[string]bool)
+		answered int
+		lastErr  error
+	)
+
+	params := url.Values{"ip": {addr.String()}}
+	slots := make(chan struct{}, searchConcurrency)
+	for _, server := range servers {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response, err := c.searchNameservers(server, params)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			answered++
+			for _, nameserver := range response.Nameservers {
+				name := strings.ToLower(strings.TrimSuffix(nameserver.LDHName, "."))
+				if name != "" && seen[name] {
+					continue
+				}
+				seen[name] = true
+				results = append(results, nameserver)
+			}
+		}(server)
+	}
+	wg.Wait()
+
+	if answered == 0 && lastErr != nil {
+		return nil, fmt.Errorf("no RDAP server answered nameserver search for %s: %w", addr, lastErr)
+	}
+
+	return results, nil
+}
+
+// bootstrapServers returns the distinct first-choice servers listed in a bootstrap
+func bootstrapServers(bootstrap *RDAPBootstrap) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, service := range bootstrap.Services {
+		if len(service) != 2 || len(service[1]) == 0 {
+			continue
+		}
+		server := normalizeServer(service[1][0])
+		if !seen[server] {
+			seen[server] = true
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// isTruncated reports whether the notices include an RFC 9083 result set truncation notice
+func isTruncated(notices []Notice) bool {
+	for _, notice := range notices {
+		if strings.HasPrefix(strings.ToLower(notice.Type), "result set truncated") {
+			return true
+		}
+	}
+	return false
+}
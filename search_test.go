@@ -0,0 +1,349 @@
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNameserverByIP(t *testing.T) {
+	searchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nameservers" || r.URL.Query().Get("ip") != "192.0.2.53" {
+			t.Errorf("Unexpected search request %s", r.URL.String())
+		}
+		response := map[string]interface{}{
+			"nameserverSearchResults": []map[string]interface{}{
+				{"objectClassName": "nameserver", "ldhName": "ns1.example.com"},
+				{"objectClassName": "nameserver", "ldhName": "ns2.example.com"},
+			},
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer searchServer.Close()
+
+	unsupportedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer unsupportedServer.Close()
+
+	client := NewClient()
+
+	results, err := client.NameserverByIP("192.0.2.53", searchServer.URL, unsupportedServer.URL)
+	if err != nil {
+		t.Fatalf("NameserverByIP failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 nameservers, got %d", len(results))
+	}
+	if results[0].LDHName != "ns1.example.com" && results[1].LDHName != "ns1.example.com" {
+		t.Errorf("Expected ns1.example.com in results, got %+v", results)
+	}
+
+	_, err = client.NameserverByIP("192.0.2.53", unsupportedServer.URL)
+	if err == nil || !strings.Contains(err.Error(), "no RDAP server answered") {
+		t.Errorf("Expected error when no server answers, got: %v", err)
+	}
+
+	_, err = client.NameserverByIP("not-an-ip", searchServer.URL)
+	if err == nil || !strings.Contains(err.Error(), "invalid IP address") {
+		t.Errorf("Expected error about invalid IP, got: %v", err)
+	}
+}
+
+func TestNameserverByIPBootstrapConcurrency(t *testing.T) {
+	var searches, active, peak atomic.Int32
+	searchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches.Add(1)
+		if n := active.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer active.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"nameserverSearchResults": [{"objectClassName": "nameserver", "ldhName": "ns1.example.com"}]}`))
+	}))
+	defer searchServer.Close()
+
+	var services []string
+	for i := range 3 * searchConcurrency {
+		services = append(services, fmt.Sprintf(`[["tld%d"], ["%s/r%d/"]]`, i, searchServer.URL, i))
+	}
+	client := NewClient().SetEmbeddedFallback(false).
+		SetBootstrapData([]byte(`{"services": [` + strings.Join(services, ",") + `]}`))
+
+	// The bootstrap servers are only searched when allowed
+	if _, err := client.NameserverByIP("192.0.2.53"); err == nil || searches.Load() != 0 {
+		t.Fatalf("Expected an error without servers, got %v after %d searches", err, searches.Load())
+	}
+
+	results, err := client.SetNameserverSearchLimit(2 * searchConcurrency).NameserverByIP("192.0.2.53")
+	if err != nil {
+		t.Fatalf("NameserverByIP failed: %v", err)
+	}
+	if got := searches.Load(); got != 2*searchConcurrency {
+		t.Errorf("Expected %d bootstrap servers to be searched, got %d searches", 2*searchConcurrency, got)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected the nameserver found by every server once, got %d results", len(results))
+	}
+	if got := peak.Load(); got > searchConcurrency {
+		t.Errorf("Expected at most %d concurrent searches, got %d", searchConcurrency, got)
+	}
+}
+
+func TestNameserverByIPDuplicates(t *testing.T) {
+	first := httptest.NewServer(serveJSON(`{"nameserverSearchResults": [{"objectClassName": "nameserver", "ldhName": "ns1.example.com"}]}`))
+	defer first.Close()
+	second := httptest.NewServer(serveJSON(`{"nameserverSearchResults": [{"objectClassName": "nameserver", "ldhName": "NS1.example.com."}, {"objectClassName": "nameserver", "ldhName": "ns2.example.com"}]}`))
+	defer second.Close()
+
+	results, err := NewClient().NameserverByIP("192.0.2.53", first.URL, second.URL)
+	if err != nil {
+		t.Fatalf("NameserverByIP failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 distinct nameservers, got %+v", results)
+	}
+}
+func TestBootstrapServers(t *testing.T) {
+	bootstrap := &RDAPBootstrap{
+		Services: [][][]string{
+			{{"com", "net"}, {"https://rdap.verisign.com/com/v1/"}},
+			{{"org"}, {"https://rdap.pir.org/org/v1"}},
+			{{"edu"}, {"https://rdap.verisign.com/com/v1/"}},
+			{{"empty"}, {}},
+		},
+	}
+
+	servers := bootstrapServers(bootstrap)
+	if len(servers) != 2 {
+		t.Fatalf("Expected 2 distinct servers, got %v", servers)
+	}
+	if servers[1] != "https://rdap.pir.org/org/v1/" {
+		t.Errorf("Expected normalized server URL, got %s", servers[1])
+	}
+}
+
+func TestSearchDomains(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domains" || r.URL.Query().Get("name") != "exampl*.com" {
+			t.Errorf("Unexpected search request %s", r.URL.String())
+		}
+		response := map[string]interface{}{
+			"domainSearchResults": []map[string]interface{}{
+				{"objectClassName": "domain", "ldhName": "example.com"},
+				{"objectClassName": "domain", "ldhName": "examples.com"},
+			},
+			"notices": []map[string]interface{}{
+				{
+					"title":       "Search Policy",
+					"type":        "result set truncated due to excessive load",
+					"description": []string{"Only the first results are returned"},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bootstrap := RDAPBootstrap{
+			Services: [][][]string{
+				{
+					{"com"},
+					{mockServer.URL + "/"},
+				},
+			},
+			Version: "1.0",
+		}
+		json.NewEncoder(w).Encode(bootstrap)
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetBootstrapURL(bootstrapServer.URL)
+
+	results, err := client.SearchDomains("Exampl*.com")
+	if err != nil {
+		t.Fatalf("SearchDomains failed: %v", err)
+	}
+	if len(results.Domains) != 2 {
+		t.Fatalf("Expected 2 domains, got %d", len(results.Domains))
+	}
+	if results.Domains[0].LDHName != "example.com" {
+		t.Errorf("Expected example.com, got %s", results.Domains[0].LDHName)
+	}
+	if !results.Truncated {
+		t.Error("Expected result set to be reported as truncated")
+	}
+}
+
+func TestSearchDomainsInvalidPattern(t *testing.T) {
+	client := NewClient()
+
+	_, err := client.SearchDomains("")
+	if err == nil || !strings.Contains(err.Error(), "search pattern cannot be empty") {
+		t.Errorf("Expected error about empty pattern, got: %v", err)
+	}
+
+	_, err = client.SearchDomains("example.co*")
+	if err == nil || !strings.Contains(err.Error(), "search pattern must include a TLD") {
+		t.Errorf("Expected error about missing TLD, got: %v", err)
+	}
+}
+
+func TestIsTruncated(t *testing.T) {
+	if isTruncated([]Notice{{Title: "Terms of Service"}}) {
+		t.Error("Expected regular notice not to signal truncation")
+	}
+	if !isTruncated([]Notice{{Type: "Result Set Truncated Due To Authorization"}}) {
+		t.Error("Expected truncation notice to be detected case-insensitively")
+	}
+}
+
+func TestSearchNameservers(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nameservers" || r.URL.Query().Get("name") != "ns*.example.com" {
+			t.Errorf("Unexpected search request %s", r.URL.String())
+		}
+		response := map[string]interface{}{
+			"nameserverSearchResults": []map[string]interface{}{
+				{
+					"objectClassName": "nameserver",
+					"ldhName":         "ns1.example.com",
+					"ipAddresses":     map[string]interface{}{"v6": []string{"2001:db8::53"}},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client := NewClient()
+
+	results, err := client.SearchNameservers(mockServer.URL, "NS*.example.com")
+	if err != nil {
+		t.Fatalf("SearchNameservers failed: %v", err)
+	}
+	if len(results.Nameservers) != 1 {
+		t.Fatalf("Expected 1 nameserver, got %d", len(results.Nameservers))
+	}
+	ns := results.Nameservers[0]
+	if ns.LDHName != "ns1.example.com" || ns.IPAddresses == nil || len(ns.IPAddresses.V6) != 1 {
+		t.Errorf("Unexpected nameserver result: %+v", ns)
+	}
+	if results.Truncated {
+		t.Error("Expected result set not to be truncated")
+	}
+
+	if _, err := client.SearchNameservers(mockServer.URL, " "); err == nil {
+		t.Error("Expected error for empty pattern")
+	}
+}
+
+func TestSearchEntities(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/entities" {
+			t.Errorf("Expected path /entities, got %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("fn") != "Example*" && query.Get("handle") != "EX*-ARIN" {
+			t.Errorf("Unexpected search query %s", r.URL.RawQuery)
+		}
+		response := map[string]interface{}{
+			"entitySearchResults": []map[string]interface{}{
+				{
+					"objectClassName": "entity",
+					"handle":          "EX1-ARIN",
+					"roles":           []string{"registrant"},
+					"vcardArray": []interface{}{
+						"vcard",
+						[]interface{}{
+							[]interface{}{"version", map[string]interface{}{}, "text", "4.0"},
+							[]interface{}{"fn", map[string]interface{}{}, "text", "Example Inc."},
+						},
+					},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client := NewClient()
+
+	results, err := client.SearchEntities(mockServer.URL, "Example*", "")
+	if err != nil {
+		t.Fatalf("SearchEntities by fn failed: %v", err)
+	}
+	if len(results.Entities) != 1 || results.Entities[0].Handle != "EX1-ARIN" {
+		t.Fatalf("Unexpected entity results: %+v", results.Entities)
+	}
+	if len(results.Entities[0].Roles) != 1 || results.Entities[0].Roles[0] != "registrant" {
+		t.Errorf("Expected registrant role, got %v", results.Entities[0].Roles)
+	}
+
+	if _, err := client.SearchEntities(mockServer.URL, "", "EX*-ARIN"); err != nil {
+		t.Errorf("SearchEntities by handle failed: %v", err)
+	}
+
+	if _, err := client.SearchEntities(mockServer.URL, "Example*", "EX*-ARIN"); err == nil {
+		t.Error("Expected error when both patterns are set")
+	}
+	if _, err := client.SearchEntities(mockServer.URL, "", ""); err == nil {
+		t.Error("Expected error when no pattern is set")
+	}
+}
+
+func TestReverseSearchDomains(t *testing.T) {
+	conformance := []string{"rdap_level_0", "reverse_search"}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+		switch r.URL.Path {
+		case "/help":
+			json.NewEncoder(w).Encode(map[string]interface{}{"rdapConformance": conformance})
+		case "/domains/reverse_search/entity":
+			if r.URL.Query().Get("handle") != "CID-4005" {
+				t.Errorf("Unexpected reverse search query %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"rdapConformance": conformance,
+				"domainSearchResults": []map[string]interface{}{
+					{"objectClassName": "domain", "ldhName": "example.com"},
+				},
+			})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := NewClient()
+
+	results, err := client.ReverseSearchDomains(mockServer.URL, "", "CID-4005")
+	if err != nil {
+		t.Fatalf("ReverseSearchDomains failed: %v", err)
+	}
+	if len(results.Domains) != 1 || results.Domains[0].LDHName != "example.com" {
+		t.Errorf("Unexpected reverse search results: %+v", results.Domains)
+	}
+
+	// Servers not advertising the conformance must be rejected before searching
+	conformance = []string{"rdap_level_0"}
+	_, err = client.ReverseSearchDomains(mockServer.URL, "", "CID-4005")
+	if err == nil || !strings.Contains(err.Error(), "does not support reverse search") {
+		t.Errorf("Expected error about missing reverse search support, got: %v", err)
+	}
+
+	if _, err := client.ReverseSearchDomains(mockServer.URL, "", ""); err == nil {
+		t.Error("Expected error when no pattern is set")
+	}
+}
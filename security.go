@@ -0,0 +1,258 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// ServerPolicy restricts the hosts the client connects to, for services
+// where bootstrap URLs, overrides or redirects may be influenced by users
+type ServerPolicy struct {
+	// Schemes lists the URL schemes allowed, http and https when empty
+	Schemes []string
+	// DenyPrivateIPs refuses loopback, private, link-local, unspecified,
+	// multicast and other special-purpose addresses, including their NAT64
+	// and 6to4 forms. Every address dialed is checked, so a public name
+	// resolving to an internal host is refused too.
+	DenyPrivateIPs bool
+}
+
+// SetAllowInsecureServers sets whether RDAP queries may be sent over plain
+// http. By default http servers taken from the bootstrap data, overrides or
+// providers are refused, except on loopback hosts.
+func (c *Client) SetAllowInsecureServers(allow bool) *Client {
+	c.allowInsecure = allow
+	return c
+}
+
+// SetServerPolicy sets the policy every RDAP query, redirect target and
+// bootstrap download is checked against before dialing, on top of the
+// SetAllowInsecureServers check. With an *http.Transport every address
+// dialed is checked, and a function set with SetDialContext has the address
+// it connected to checked; the policy is kept when the transport or HTTP
+// client is replaced later. Other transports and HTTP clients can't be
+// checked when dialing, so the host of each request is resolved and refused
+// when any of its addresses is private. The zero policy removes the
+// restrictions.
+func (c *Client) SetServerPolicy(policy ServerPolicy) *Client {
+	if len(policy.Schemes) == 0 && !policy.DenyPrivateIPs {
+		c.serverPolicy = nil
+	} else {
+		policy.Schemes = slices.Clone(policy.Schemes)
+		c.serverPolicy = &policy
+	}
+	c.applyDialer()
+	return c
+}
+
+// checkServerURL returns ErrInsecureServer for a plain http URL unless
+// insecure servers are allowed or the host is a loopback address, and
+// ErrServerDenied for a URL refused by the server policy
+func (c *Client) checkServerURL(rawURL string) error {
+	if !c.allowInsecure && !isSecureURL(rawURL) {
+		return fmt.Errorf("RDAP query failed: refusing plain http server %s: %w", rawURL, ErrInsecureServer)
+	}
+	return c.checkPolicy(rawURL)
+}
+
+// checkPolicy returns ErrServerDenied when the scheme or the literal IP
+// address of the URL is refused by the server policy
+func (c *Client) checkPolicy(rawURL string) error {
+	policy := c.serverPolicy
+	if policy == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL %s: %w", rawURL, err)
+	}
+	schemes := policy.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !slices.ContainsFunc(schemes, func(scheme string) bool { return strings.EqualFold(scheme, u.Scheme) }) {
+		return fmt.Errorf("refusing %s: scheme %s not allowed: %w", rawURL, u.Scheme, ErrServerDenied)
+	}
+	if policy.DenyPrivateIPs {
+		host := u.Hostname()
+		if addr, err := netip.ParseAddr(host); err == nil && isPrivateAddr(addr) || strings.EqualFold(host, "localhost") {
+			return fmt.Errorf("refusing %s: private address: %w", rawURL, ErrServerDenied)
+		}
+	}
+	return nil
+}
+
+// reservedPrefixes are the special-purpose ranges refused besides the
+// loopback, private, link-local and multicast ones
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+var (
+	// nat64Prefix embeds IPv4 addresses in its last 32 bits (RFC 6052)
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	// sixToFourPrefix embeds IPv4 addresses in bits 16 to 48 (RFC 3056)
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// isPrivateAddr reports whether an address is not publicly routable,
+// including IPv4 addresses reached through NAT64 or 6to4
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if embedded, ok := embeddedIPv4(addr); ok && isPrivateAddr(embedded) {
+		return true
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsUnspecified() || addr.IsMulticast() {
+		return true
+	}
+	return slices.ContainsFunc(reservedPrefixes, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address refers to
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+// checkHostAddrs resolves the host of a request sent through a transport
+// whose dialer can't be checked and refuses it when the policy denies
+// private addresses and one of its addresses is private
+func (c *Client) checkHostAddrs(req *http.Request) error {
+	if c.serverPolicy == nil || !c.serverPolicy.DenyPrivateIPs || c.transport() != nil {
+		return nil
+	}
+
+	host := req.URL.Hostname()
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		resolver := c.resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver
+		}
+		addrs, err = resolver.LookupNetIP(req.Context(), "ip", host)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+	}
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			return fmt.Errorf("refusing %s: resolves to private address %s: %w", host, addr, ErrServerDenied)
+		}
+	}
+	return nil
+}
+
+// checkDialAddress returns ErrServerDenied for a dialed address refused by the policy
+func checkDialAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("refusing connection to %s: %w", address, ErrServerDenied)
+	}
+	if isPrivateAddr(addrPort.Addr()) {
+		return fmt.Errorf("refusing connection to private address %s: %w", address, ErrServerDenied)
+	}
+	return nil
+}
+
+// denyPrivateDial checks the addresses of a dialer before it connects
+func denyPrivateDial(dialer *net.Dialer) *net.Dialer {
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		return checkDialAddress(address)
+	}
+	return dialer
+}
+
+// denyPrivateConn wraps a dial function to close connections to refused addresses
+func denyPrivateConn(dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if tcp, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+			if err := checkDialAddress(tcp.AddrPort().String()); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		return conn, nil
+	}
+}
+
+// isSecureURL reports whether a query to the URL is not sent in plaintext
+// over the network: an https URL or an http URL to a loopback host
+func isSecureURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, "http") {
+		return true
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// secureServers drops plain http servers from a list when insecure servers
+// are not allowed, unless no other server is left
+func (c *Client) secureServers(servers []string) []string {
+	if c.allowInsecure {
+		return servers
+	}
+
+	var secure []string
+	for _, server := range servers {
+		if isSecureURL(server) {
+			secure = append(secure, server)
+		}
+	}
+	if len(secure) == 0 {
+		return servers
+	}
+	return secure
+}
@@ -0,0 +1,205 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestIsSecureURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		secure bool
+	}{
+		{"https://rdap.verisign.com/com/v1/", true},
+		{"HTTPS://rdap.example/", true},
+		{"http://rdap.example/", false},
+		{"HTTP://rdap.example/", false},
+		{"http://localhost:8080/", true},
+		{"http://127.0.0.1:8080/", true},
+		{"http://[::1]/", true},
+		{"http://10.0.0.1/", false},
+		{"://bad", false},
+	}
+	for _, test := range tests {
+		if got := isSecureURL(test.url); got != test.secure {
+			t.Errorf("isSecureURL(%s) = %v, expected %v", test.url, got, test.secure)
+		}
+	}
+}
+
+func TestInsecureServerRefused(t *testing.T) {
+	client := NewClient().SetServerOverride("example", "http://rdap.example/")
+
+	_, err := client.RDAP("domain.example")
+	if !errors.Is(err, ErrInsecureServer) {
+		t.Errorf("Expected ErrInsecureServer, got %v", err)
+	}
+
+	client.SetAllowInsecureServers(true).SetHTTPClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("network disabled")
+	}))
+	if _, err := client.RDAP("domain.example"); errors.Is(err, ErrInsecureServer) {
+		t.Errorf("Expected plain http to be allowed, got %v", err)
+	}
+}
+
+func TestSecureServersPreferred(t *testing.T) {
+	bootstrapServer := httptest.NewServer(serveJSON(`{"services": [
+		[["com"], ["http://rdap.example.com/", "https://rdap.example.com/"]],
+		[["net"], ["http://rdap.example.net/"]]
+	]}`))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetBootstrapURL(bootstrapServer.URL)
+	if server, _ := client.getTLDServer("com"); server != "https://rdap.example.com/" {
+		t.Errorf("Expected the https server to be chosen, got %s", server)
+	}
+
+	// With only a plain http server listed the query itself is refused
+	if _, err := client.RDAP("example.net"); !errors.Is(err, ErrInsecureServer) {
+		t.Errorf("Expected ErrInsecureServer, got %v", err)
+	}
+}
+
+func TestServerPolicySchemes(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	defer server.Close()
+	client := newFailoverClient(server).SetServerPolicy(ServerPolicy{Schemes: []string{"https"}})
+
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected ErrServerDenied, got %v", err)
+	}
+
+	// The zero policy lifts the restriction
+	if _, err := client.SetServerPolicy(ServerPolicy{}).RDAP("example.com"); err != nil {
+		t.Errorf("RDAP failed without a policy: %v", err)
+	}
+}
+
+func TestServerPolicyPrivateURL(t *testing.T) {
+	client := NewClient().SetServerPolicy(ServerPolicy{DenyPrivateIPs: true})
+	tests := []struct {
+		url    string
+		denied bool
+	}{
+		{"https://rdap.verisign.com/com/v1/", false},
+		{"https://8.8.8.8/", false},
+		{"https://localhost/", true},
+		{"https://127.0.0.1/", true},
+		{"https://10.1.2.3/", true},
+		{"https://169.254.169.254/latest/", true},
+		{"https://[::ffff:192.168.0.1]/", true},
+		{"https://[fe80::1]/", true},
+		{"https://0.0.0.0/", true},
+		{"https://0.1.2.3/", true},
+		{"https://100.64.1.1/", true},
+		{"https://100.128.0.1/", false},
+		{"https://192.0.0.8/", true},
+		{"https://198.18.0.1/", true},
+		{"https://[64:ff9b::a00:1]/", true},
+		{"https://[64:ff9b::808:808]/", false},
+		{"https://[64:ff9b:1::1]/", true},
+		{"https://[2002:c0a8:101::1]/", true},
+		{"https://[2002:808:808::1]/", false},
+	}
+	for _, test := range tests {
+		if err := client.checkPolicy(test.url); errors.Is(err, ErrServerDenied) != test.denied {
+			t.Errorf("checkPolicy(%s) = %v, expected denied %v", test.url, err, test.denied)
+		}
+	}
+
+	// Bootstrap downloads are checked as well
+	bootstrapServer := httptest.NewServer(serveJSON(`{"services": []}`))
+	defer bootstrapServer.Close()
+	client.SetBootstrapURL(bootstrapServer.URL)
+	if _, err := client.getBootstrapData(); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected the bootstrap download to be denied, got %v", err)
+	}
+}
+
+func TestServerPolicyPrivateDial(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "domain.example"}`))
+	defer server.Close()
+
+	// A public name resolving to a private address is refused when dialing
+	client := NewClient().SetServerPolicy(ServerPolicy{DenyPrivateIPs: true})
+	if _, err := client.transport().DialContext(context.Background(), "tcp", server.Listener.Addr().String()); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected the default dialer to refuse a private address, got %v", err)
+	}
+
+	client.SetAllowInsecureServers(true).
+		SetServerOverride("example", "http://rdap.nic.example/").
+		SetDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+		})
+	if _, err := client.RDAP("domain.example"); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected a custom dialer connection to be refused, got %v", err)
+	}
+
+	if _, err := client.SetServerPolicy(ServerPolicy{}).RDAP("domain.example"); err != nil {
+		t.Errorf("RDAP failed without a policy: %v", err)
+	}
+}
+
+func TestServerPolicyKeptWithNewTransport(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	defer server.Close()
+
+	transport := NewTransport()
+	client := NewClient().SetServerPolicy(ServerPolicy{DenyPrivateIPs: true}).SetTransport(transport)
+	if _, err := client.transport().DialContext(context.Background(), "tcp", server.Listener.Addr().String()); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected the new transport to refuse a private address, got %v", err)
+	}
+	if client.transport() == transport || transport.DialContext == nil {
+		t.Error("Expected the policy to be applied to a copy of the transport")
+	}
+
+	httpClient := &http.Client{Transport: NewTransport()}
+	client.SetHTTPClient(httpClient)
+	if _, err := client.transport().DialContext(context.Background(), "tcp", server.Listener.Addr().String()); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected the new HTTP client to refuse a private address, got %v", err)
+	}
+	if client.httpClient == httpClient {
+		t.Error("Expected the policy to be applied to a copy of the HTTP client")
+	}
+}
+
+func TestServerPolicyOtherTransport(t *testing.T) {
+	server := httptest.NewServer(serveJSON(`{"objectClassName": "domain", "ldhName": "domain.example"}`))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	if addrs, err := net.DefaultResolver.LookupNetIP(context.Background(), "ip", host); err != nil || len(addrs) == 0 || !addrs[0].IsLoopback() {
+		t.Skip("host name does not resolve to a loopback address")
+	}
+
+	// The dialer of a transport other than *http.Transport can't be checked,
+	// so a name resolving to a loopback address is refused before sending
+	client := NewClient().SetAllowInsecureServers(true).
+		SetTransport(roundTripperFunc(http.DefaultTransport.RoundTrip)).
+		SetServerOverride("example", "http://"+host+":"+port+"/").
+		SetServerPolicy(ServerPolicy{DenyPrivateIPs: true})
+	if _, err := client.RDAP("domain.example"); !errors.Is(err, ErrServerDenied) {
+		t.Errorf("Expected the request to be refused, got %v", err)
+	}
+
+	if _, err := client.SetServerPolicy(ServerPolicy{}).RDAP("domain.example"); err != nil {
+		t.Errorf("RDAP failed without a policy: %v", err)
+	}
+}
+
+// httpClientFunc adapts a function to the HTTPClient interface
+type httpClientFunc func(*http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
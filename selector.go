@@ -0,0 +1,104 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"slices"
+	"strings"
+)
+
+// ServerSelector orders the RDAP base URLs registered for a TLD by
+// preference, most preferred first. The servers are given in bootstrap order.
+type ServerSelector func(tld string, servers []string) []string
+
+// SetServerSelector sets the selector choosing among the servers registered
+// for a TLD. A nil selector keeps the bootstrap order.
+func (c *Client) SetServerSelector(selector ServerSelector) *Client {
+	c.serverSelector = selector
+	return c
+}
+
+// SetServerPreference prefers, for a TLD, the servers whose base URL contains
+// one of the given patterns, in pattern order. It replaces any selector set
+// with SetServerSelector and keeps the preferences of other TLDs.
+func (c *Client) SetServerPreference(tld string, patterns ...string) *Client {
+	tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+	if c.serverPreferences == nil {
+		c.serverPreferences = make(map[string][]string)
+	}
+	if len(patterns) == 0 {
+		delete(c.serverPreferences, tld)
+	} else {
+		c.serverPreferences[tld] = patterns
+	}
+
+	preferences := c.serverPreferences
+	c.serverSelector = func(tld string, servers []string) []string {
+		if patterns, ok := preferences[tld]; ok {
+			return PreferServers(patterns...)(tld, servers)
+		}
+		return servers
+	}
+	return c
+}
+
+// PreferServers returns a selector putting first the servers whose base URL
+// contains one of the given patterns, in pattern order, and keeping the
+// bootstrap order otherwise
+func PreferServers(patterns ...string) ServerSelector {
+	return func(tld string, servers []string) []string {
+		rank := func(server string) int {
+			for i, pattern := range patterns {
+				if strings.Contains(server, pattern) {
+					return i
+				}
+			}
+			return len(patterns)
+		}
+
+		ordered := slices.Clone(servers)
+		slices.SortStableFunc(ordered, func(a, b string) int {
+			return rank(a) - rank(b)
+		})
+		return ordered
+	}
+}
+
+// PreferHTTPS is a selector putting https servers before plain http ones
+var PreferHTTPS = PreferServers("https://")
+
+// selectServers returns the servers registered for a TLD in the order they
+// should be tried
+func (c *Client) selectServers(tld string, bootstrap *RDAPBootstrap) []string {
+	servers := c.secureServers(bootstrap.ServersForTLD(tld))
+	if c.serverSelector == nil || len(servers) < 2 {
+		return servers
+	}
+
+	var selected []string
+	for _, server := range c.serverSelector(tld, slices.Clone(servers)) {
+		if server != "" {
+			selected = append(selected, normalizeServer(server))
+		}
+	}
+	if len(selected) == 0 {
+		return servers
+	}
+	return selected
+}
@@ -0,0 +1,60 @@
+package rdap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestServerSelector(t *testing.T) {
+	bootstrapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "1.0", "services": [
+			[["com"], ["http://rdap.example.com/", "https://rdap.example.com/", "https://rdap-v6.example.com"]],
+			[["net"], ["http://rdap.example.net/", "https://rdap.example.net/"]]
+		]}`))
+	}))
+	defer bootstrapServer.Close()
+
+	client := NewClient().SetBootstrapURL(bootstrapServer.URL).SetAllowInsecureServers(true)
+	if server, _ := client.getTLDServer("com"); server != "http://rdap.example.com/" {
+		t.Errorf("Expected bootstrap order without a selector, got %s", server)
+	}
+
+	client.SetServerSelector(PreferHTTPS)
+	if server, _ := client.getTLDServer("com"); server != "https://rdap.example.com/" {
+		t.Errorf("Expected https server, got %s", server)
+	}
+
+	var got []string
+	client.SetServerSelector(func(tld string, servers []string) []string {
+		got = servers
+		return []string{servers[len(servers)-1]}
+	})
+	if server, _ := client.getTLDServer("com"); server != "https://rdap-v6.example.com/" {
+		t.Errorf("Expected selector choice with trailing slash, got %s", server)
+	}
+	if len(got) != 3 {
+		t.Errorf("Expected selector to receive the 3 servers, got %v", got)
+	}
+
+	client.SetServerPreference("COM", "rdap-v6", "https://")
+	if server, _ := client.getTLDServer("com"); server != "https://rdap-v6.example.com/" {
+		t.Errorf("Expected preferred server for com, got %s", server)
+	}
+	if server, _ := client.getTLDServer("net"); server != "http://rdap.example.net/" {
+		t.Errorf("Expected bootstrap order for net, got %s", server)
+	}
+}
+
+func TestPreferServers(t *testing.T) {
+	servers := []string{"http://a.example/", "https://b.example/", "https://c.example/"}
+
+	ordered := PreferServers("c.example", "https://")("com", servers)
+	if !slices.Equal(ordered, []string{"https://c.example/", "https://b.example/", "http://a.example/"}) {
+		t.Errorf("Unexpected order: %v", ordered)
+	}
+	if servers[0] != "http://a.example/" {
+		t.Error("Expected input servers to be left untouched")
+	}
+}
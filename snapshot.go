@@ -0,0 +1,139 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// IterableCache is a Cache that can list its entries, as needed to export it
+// with ExportCache. LRUCache implements it.
+type IterableCache interface {
+	Cache
+	// Range calls fn with every unexpired entry and the time left before it
+	// expires, zero when it never does, until fn returns false
+	Range(fn func(key string, val []byte, ttl time.Duration) bool)
+}
+
+// snapshotEntry is a cache entry as written to a snapshot, one per line
+type snapshotEntry struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires,omitzero"`
+}
+
+// ExportCache writes the cached responses of the client's namespace to w as
+// JSON lines, so a warmed cache can be shipped to ephemeral environments
+// such as CI runners and loaded there with ImportCache. The cache must
+// implement IterableCache. Only the keys of the client's own namespace are
+// exported: the default namespace leaves out every namespaced entry, and a
+// namespace leaves out the default one and the namespaces nested under it.
+func (c *Client) ExportCache(w io.Writer) error {
+	cache, ok := c.cache.(IterableCache)
+	if !ok || c.responseCache() == nil {
+		return fmt.Errorf("cache does not support export")
+	}
+
+	prefix := ""
+	if c.cacheNamespace != "" {
+		prefix = c.cacheNamespace + "/"
+	}
+
+	now := time.Now()
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	var err error
+	cache.Range(func(key string, val []byte, ttl time.Duration) bool {
+		// Skip other namespaces and values the client did not store
+		key, ok := strings.CutPrefix(key, prefix)
+		if !ok || !isResponseCacheKey(key) {
+			return true
+		}
+		val, decodeErr := decodeCacheValue(val)
+		if decodeErr != nil || !json.Valid(val) {
+			return true
+		}
+
+		entry := snapshotEntry{Key: key, Value: val}
+		if ttl > 0 {
+			entry.Expires = now.Add(ttl)
+		}
+		err = encoder.Encode(entry)
+		return err == nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// isResponseCacheKey reports whether a key without namespace is one the
+// client stores responses under, such as "domain/example.com" or
+// "notfound/domain/example.com". Namespaced keys have more segments.
+func isResponseCacheKey(key string) bool {
+	kind, name, _ := strings.Cut(strings.TrimPrefix(key, notFoundKeyPrefix), "/")
+	switch kind {
+	case "domain", "registrar":
+		return name != "" && !strings.Contains(name, "/")
+	}
+	return false
+}
+
+// ImportCache loads a snapshot written by ExportCache into the cache, under
+// the client's namespace. Entries that expired since the export are skipped.
+func (c *Client) ImportCache(r io.Reader) error {
+	cache := c.responseCache()
+	if cache == nil {
+		return fmt.Errorf("cannot import cache: response caching is disabled")
+	}
+
+	scanner := bufio.NewScanner(r)
+	// Some RDAP responses are hundreds of KB
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var entry snapshotEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("failed to parse cache snapshot line %d: %w", line, err)
+		}
+
+		var ttl time.Duration
+		if !entry.Expires.IsZero() {
+			if ttl = time.Until(entry.Expires); ttl <= 0 {
+				continue
+			}
+		}
+		cache.Set(entry.Key, c.encodeCacheValue(entry.Value), ttl)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package rdap
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportCache(t *testing.T) {
+	source, _ := newCountingDomainClient(t, http.StatusOK)
+	source.SetCacheNamespace("ci")
+	if _, err := source.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	if err := source.ExportCache(&snapshot); err != nil {
+		t.Fatalf("ExportCache failed: %v", err)
+	}
+	if lines := strings.Count(snapshot.String(), "\n"); lines != 1 {
+		t.Fatalf("Expected one snapshot line, got %d: %s", lines, snapshot.String())
+	}
+	if !strings.Contains(snapshot.String(), `"key":"domain/example.com"`) {
+		t.Errorf("Expected key without namespace, got %s", snapshot.String())
+	}
+
+	target, queries := newCountingDomainClient(t, http.StatusOK)
+	if err := target.ImportCache(&snapshot); err != nil {
+		t.Fatalf("ImportCache failed: %v", err)
+	}
+	resp, err := target.RDAPResponse("example.com")
+	if err != nil {
+		t.Fatalf("RDAPResponse failed: %v", err)
+	}
+	if !resp.Cached || queries.Load() != 0 {
+		t.Errorf("Expected imported response to be served from the cache, got %+v after %d queries", resp, queries.Load())
+	}
+}
+
+func TestExportCacheNamespaces(t *testing.T) {
+	tests := []struct {
+		namespace string
+		domain    string
+	}{
+		{"", "default.com"},
+		{"ci", "ci.com"},
+		{"ci/nested", "nested.com"},
+	}
+
+	shared := NewLRUCache(100)
+	for _, tt := range tests {
+		client, _ := newCountingDomainClient(t, http.StatusOK)
+		client.SetCache(shared).SetCacheNamespace(tt.namespace)
+		if _, err := client.RDAP(tt.domain); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+
+	for _, tt := range tests {
+		client := NewClient().SetCache(shared).SetCacheNamespace(tt.namespace)
+		var snapshot bytes.Buffer
+		if err := client.ExportCache(&snapshot); err != nil {
+			t.Fatalf("ExportCache failed: %v", err)
+		}
+		if lines := strings.Count(snapshot.String(), "\n"); lines != 1 || !strings.Contains(snapshot.String(), `"key":"domain/`+tt.domain+`"`) {
+			t.Errorf("Expected namespace %q to export only %s, got %s", tt.namespace, tt.domain, snapshot.String())
+		}
+	}
+}
+
+func TestImportCacheSkipsExpired(t *testing.T) {
+	client, queries := newCountingDomainClient(t, http.StatusOK)
+	expired := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	snapshot := `{"key":"domain/example.com","value":{"body":"e30=","status":200},"expires":"` + expired + `"}` + "\n\n"
+
+	if err := client.ImportCache(strings.NewReader(snapshot)); err != nil {
+		t.Fatalf("ImportCache failed: %v", err)
+	}
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected expired entry to be skipped, got %d queries", got)
+	}
+}
+
+func TestImportCacheInvalid(t *testing.T) {
+	err := NewClient().ImportCache(strings.NewReader("{}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected parse error on line 2, got %v", err)
+	}
+}
+
+func TestExportCacheUnsupported(t *testing.T) {
+	client := NewClient().SetCache(newMapCache())
+	if err := client.ExportCache(&bytes.Buffer{}); err == nil {
+		t.Error("Expected error for a cache that cannot be listed")
+	}
+}
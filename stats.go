@@ -0,0 +1,96 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"sync/atomic"
+)
+
+// CacheStats holds the counters of the response cache
+type CacheStats struct {
+	// Hits counts lookups served from the cache, including revalidated entries
+	Hits uint64
+	// NegativeHits counts the hits that served a cached not found response
+	NegativeHits uint64
+	// Misses counts lookups that had to query an RDAP server
+	Misses uint64
+	// Stale counts lookups that found an entry past its freshness lifetime
+	Stale uint64
+	// Revalidations counts stale entries confirmed by a 304 Not Modified
+	Revalidations uint64
+	// StaleServed counts stale entries served because the query failed
+	StaleServed uint64
+	// Evictions counts entries dropped to make room, when the cache reports it
+	Evictions uint64
+	// Entries is the number of cached entries, when the cache reports it
+	Entries int
+	// Bytes is the memory used by cached entries, when the cache reports it
+	Bytes int64
+}
+
+// HitRatio returns the share of lookups served from the cache
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// cacheCounters counts response cache events
+type cacheCounters struct {
+	hits          atomic.Uint64
+	negativeHits  atomic.Uint64
+	misses        atomic.Uint64
+	stale         atomic.Uint64
+	revalidations atomic.Uint64
+	staleServed   atomic.Uint64
+}
+
+// CacheStats returns the response cache counters since the client was created.
+// Evictions, Entries and Bytes are reported by caches with Evictions(), Len()
+// and Size() methods, as the built-in LRUCache has
+func (c *Client) CacheStats() CacheStats {
+	stats := CacheStats{
+		Hits:          c.stats.hits.Load(),
+		NegativeHits:  c.stats.negativeHits.Load(),
+		Misses:        c.stats.misses.Load(),
+		Stale:         c.stats.stale.Load(),
+		Revalidations: c.stats.revalidations.Load(),
+		StaleServed:   c.stats.staleServed.Load(),
+	}
+	if cache, ok := c.cache.(interface{ Evictions() uint64 }); ok {
+		stats.Evictions = cache.Evictions()
+	}
+	if cache, ok := c.cache.(interface{ Len() int }); ok {
+		stats.Entries = cache.Len()
+	}
+	if cache, ok := c.cache.(interface{ Size() int64 }); ok {
+		stats.Bytes = cache.Size()
+	}
+	return stats
+}
+
+// recordHit counts a lookup served from the cache
+func (c *Client) recordHit(resp *Response) {
+	c.stats.hits.Add(1)
+	if !isSuccess(resp.StatusCode) {
+		c.stats.negativeHits.Add(1)
+	}
+}
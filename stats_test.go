@@ -0,0 +1,53 @@
+package rdap
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCacheStats(t *testing.T) {
+	var revalidate atomic.Bool
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/domain/missing.com":
+			http.NotFound(w, r)
+		case r.Header.Get("If-None-Match") != "":
+			w.WriteHeader(http.StatusNotModified)
+		case revalidate.Load():
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"1"`)
+			fallthrough
+		default:
+			w.Write([]byte(`{"objectClassName": "domain"}`))
+		}
+	}).SetCache(NewLRUCache(2))
+
+	queries := []string{"example.com", "example.com", "missing.com", "missing.com", "other.com"}
+	for _, domain := range queries {
+		client.RDAP(domain)
+	}
+	revalidate.Store(true)
+	client.RDAP("fresh.com")
+	client.RDAP("fresh.com")
+
+	stats := client.CacheStats()
+	if stats.Bytes <= 0 {
+		t.Errorf("Expected cache size to be reported, got %d", stats.Bytes)
+	}
+	stats.Bytes = 0
+	want := CacheStats{Hits: 3, NegativeHits: 1, Misses: 4, Stale: 1, Revalidations: 1, Evictions: 2, Entries: 2}
+	if stats != want {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+	if ratio := stats.HitRatio(); ratio != 3.0/7.0 {
+		t.Errorf("Expected hit ratio 3/7, got %v", ratio)
+	}
+}
+
+func TestCacheStatsEmpty(t *testing.T) {
+	stats := NewClient().CacheStats()
+	if stats != (CacheStats{}) || stats.HitRatio() != 0 {
+		t.Errorf("Expected zero stats, got %+v", stats)
+	}
+}
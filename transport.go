@@ -0,0 +1,173 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"net/http"
+	"time"
+)
+
+// Defaults of the transport of the default HTTP client
+const (
+	// defaultMaxIdleConns is the number of idle connections kept across all hosts
+	defaultMaxIdleConns = 100
+	// defaultMaxIdleConnsPerHost is the number of idle connections kept per
+	// host, above the net/http default of 2 so bulk runs reuse connections
+	defaultMaxIdleConnsPerHost = 16
+	// defaultIdleConnTimeout is how long an idle connection is kept
+	defaultIdleConnTimeout = 90 * time.Second
+	// defaultTLSHandshakeTimeout bounds the TLS handshake with an RDAP server
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	// defaultDialTimeout bounds the TCP connection to an RDAP server
+	defaultDialTimeout = 10 * time.Second
+)
+
+// NewTransport returns the tuned transport of the default HTTP client, with
+// HTTP/2 enabled and connection pools sized for bulk queries. It is a base
+// for custom transports, such as the fallback of an HTTP/3 transport.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           newDialer(nil).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          defaultMaxIdleConns,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// newHTTPClient returns the default HTTP client using the given transport
+func newHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
+		Timeout:   defaultTimeout,
+		Transport: transport,
+		// Redirects are followed by the client with its own safeguards
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// NewClientWithTransport creates a client with default settings sending its
+// queries through the given transport, which several clients can share, so
+// per-tenant clients of a service reuse one connection pool. The transport
+// setters of a client sharing a transport leave the shared one untouched and
+// give the client its own copy with the new setting.
+func NewClientWithTransport(transport http.RoundTripper) *Client {
+	return NewClient().SetTransport(transport)
+}
+
+// SetTransport sets the transport of the HTTP client, such as the HTTP/3
+// transport of the transport/http3 module, keeping the timeout and redirect
+// handling of the client. A custom HTTP client that is not an *http.Client
+// is replaced by the default one. The transport setters below only apply to
+// an *http.Transport, and change a copy of it rather than the transport
+// given here. The resolver, dialer, IP preference and server policy already
+// set are applied to the new transport.
+func (c *Client) SetTransport(transport http.RoundTripper) *Client {
+	if httpClient, ok := c.httpClient.(*http.Client); ok {
+		client := *httpClient
+		client.Transport = transport
+		c.httpClient = &client
+	} else {
+		c.httpClient = newHTTPClient(transport)
+	}
+	c.sharedTransport = true
+	c.buildChain()
+	c.reapplyDialer()
+	return c
+}
+
+// transport returns the transport of the default HTTP client, or nil when a
+// custom HTTP client or transport was set
+func (c *Client) transport() *http.Transport {
+	httpClient, ok := c.httpClient.(*http.Client)
+	if !ok {
+		return nil
+	}
+	transport, _ := httpClient.Transport.(*http.Transport)
+	return transport
+}
+
+// ownTransport returns the transport to adjust like transport, first
+// replacing a transport or HTTP client the caller owns with a copy of its own
+func (c *Client) ownTransport() *http.Transport {
+	transport := c.transport()
+	if transport != nil && c.sharedTransport {
+		transport = transport.Clone()
+		client := *c.httpClient.(*http.Client)
+		client.Transport = transport
+		c.httpClient = &client
+		c.sharedTransport = false
+		c.buildChain()
+	}
+	return transport
+}
+
+// SetMaxIdleConns sets the number of idle connections kept across all RDAP
+// hosts (default 100). Like the other transport setters, it only applies to
+// the default HTTP client, or a custom *http.Client with an *http.Transport.
+func (c *Client) SetMaxIdleConns(n int) *Client {
+	if transport := c.ownTransport(); transport != nil {
+		transport.MaxIdleConns = n
+	}
+	return c
+}
+
+// SetMaxIdleConnsPerHost sets the number of idle connections kept per RDAP host (default 16)
+func (c *Client) SetMaxIdleConnsPerHost(n int) *Client {
+	if transport := c.ownTransport(); transport != nil {
+		transport.MaxIdleConnsPerHost = n
+	}
+	return c
+}
+
+// SetMaxConnsPerHost limits the connections opened to an RDAP host, idle or
+// active. Zero, the default, sets no limit.
+func (c *Client) SetMaxConnsPerHost(n int) *Client {
+	if transport := c.ownTransport(); transport != nil {
+		transport.MaxConnsPerHost = n
+	}
+	return c
+}
+
+// SetIdleConnTimeout sets how long an idle connection is kept (default 90 seconds)
+func (c *Client) SetIdleConnTimeout(timeout time.Duration) *Client {
+	if transport := c.ownTransport(); transport != nil {
+		transport.IdleConnTimeout = timeout
+	}
+	return c
+}
+
+// SetTLSHandshakeTimeout sets the timeout of the TLS handshake with an RDAP server (default 10 seconds)
+func (c *Client) SetTLSHandshakeTimeout(timeout time.Duration) *Client {
+	if transport := c.ownTransport(); transport != nil {
+		transport.TLSHandshakeTimeout = timeout
+	}
+	return c
+}
+
+// CloseIdleConnections closes the idle connections of the HTTP client, if it supports it
+func (c *Client) CloseIdleConnections() {
+	if closer, ok := c.httpClient.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
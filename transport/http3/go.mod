@@ -0,0 +1,16 @@
+module github.com/ducksify/gordap/transport/http3
+
+go 1.24.3
+
+require (
+	github.com/ducksify/gordap v0.0.0-20261014110539-fef4b39742ce
+	github.com/quic-go/quic-go v0.59.0
+)
+
+require (
+	github.com/quic-go/qpack v0.6.0 // indirect
+	golang.org/x/crypto v0.42.0 // indirect
+	golang.org/x/net v0.44.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
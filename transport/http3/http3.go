@@ -0,0 +1,155 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// Package http3 implements an opt-in HTTP/3 transport for the gordap client,
+// falling back to HTTP/2 or HTTP/1.1 for registries that don't support it
+package http3
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+	"github.com/quic-go/quic-go"
+	quichttp3 "github.com/quic-go/quic-go/http3"
+)
+
+const (
+	// defaultHandshakeTimeout bounds the QUIC handshake, so networks
+	// blocking UDP fall back quickly
+	defaultHandshakeTimeout = 2 * time.Second
+	// defaultFallbackCooldown is how long a host that failed over HTTP/3 is
+	// queried with the fallback transport only
+	defaultFallbackCooldown = 10 * time.Minute
+)
+
+// Transport is an http.RoundTripper sending https requests over HTTP/3 and
+// retrying them with a fallback transport when HTTP/3 fails, such as when
+// the registry doesn't support it or UDP is blocked
+type Transport struct {
+	h3       *quichttp3.Transport
+	fallback http.RoundTripper
+	cooldown time.Duration
+
+	mu     sync.Mutex
+	broken map[string]time.Time
+}
+
+// New returns an HTTP/3 transport falling back to the given transport. A nil
+// fallback uses rdap.NewTransport, the tuned HTTP/2 transport of the client.
+func New(fallback http.RoundTripper) *Transport {
+	if fallback == nil {
+		fallback = rdap.NewTransport()
+	}
+	return &Transport{
+		h3: &quichttp3.Transport{
+			QUICConfig: &quic.Config{HandshakeIdleTimeout: defaultHandshakeTimeout},
+		},
+		fallback: fallback,
+		cooldown: defaultFallbackCooldown,
+		broken:   make(map[string]time.Time),
+	}
+}
+
+// SetTLSConfig sets the TLS configuration of HTTP/3 connections
+func (t *Transport) SetTLSConfig(config *tls.Config) *Transport {
+	t.h3.TLSClientConfig = config
+	return t
+}
+
+// SetHandshakeTimeout sets how long the QUIC handshake may take before the
+// request falls back (default 2 seconds)
+func (t *Transport) SetHandshakeTimeout(timeout time.Duration) *Transport {
+	t.h3.QUICConfig.HandshakeIdleTimeout = timeout
+	return t
+}
+
+// SetFallbackCooldown sets how long a host that failed over HTTP/3 is only
+// queried with the fallback transport (default 10 minutes)
+func (t *Transport) SetFallbackCooldown(cooldown time.Duration) *Transport {
+	t.cooldown = cooldown
+	return t
+}
+
+// RoundTrip sends the request over HTTP/3, or with the fallback transport for
+// plain http requests and hosts that failed over HTTP/3 recently
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Host)
+	if !strings.EqualFold(req.URL.Scheme, "https") || t.isBroken(host) {
+		return t.fallback.RoundTrip(req)
+	}
+
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	// A cancelled request or one whose body was consumed is not retried
+	if req.Context().Err() != nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	t.markBroken(host)
+	return t.fallback.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both transports
+func (t *Transport) CloseIdleConnections() {
+	t.h3.CloseIdleConnections()
+	if closer, ok := t.fallback.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// Close closes the HTTP/3 connections and the idle connections of the fallback
+func (t *Transport) Close() error {
+	if closer, ok := t.fallback.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+	return t.h3.Close()
+}
+
+// isBroken reports whether the host failed over HTTP/3 within the cooldown
+func (t *Transport) isBroken(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failed, ok := t.broken[host]
+	if !ok {
+		return false
+	}
+	if time.Since(failed) >= t.cooldown {
+		delete(t.broken, host)
+		return false
+	}
+	return true
+}
+
+// markBroken records that the host failed over HTTP/3
+func (t *Transport) markBroken(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.broken[host] = time.Now()
+}
@@ -0,0 +1,120 @@
+package http3
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+	quichttp3 "github.com/quic-go/quic-go/http3"
+)
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// newHTTP3Server serves the handler over HTTP/3 on the UDP port of a TLS test
+// server, and returns them with a transport trusting their certificate
+func newHTTP3Server(t *testing.T, handler http.Handler) (*httptest.Server, *Transport) {
+	t.Helper()
+
+	tlsServer := httptest.NewTLSServer(handler)
+	t.Cleanup(tlsServer.Close)
+
+	conn, err := net.ListenPacket("udp", tlsServer.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to listen on UDP: %v", err)
+	}
+	h3Server := &quichttp3.Server{
+		Handler:   handler,
+		TLSConfig: quichttp3.ConfigureTLSConfig(&tls.Config{Certificates: tlsServer.TLS.Certificates}),
+	}
+	go h3Server.Serve(conn)
+	t.Cleanup(func() {
+		h3Server.Close()
+		conn.Close()
+	})
+
+	clientTLS := tlsServer.Client().Transport.(*http.Transport).TLSClientConfig
+	fallback := rdap.NewTransport()
+	fallback.TLSClientConfig = clientTLS.Clone()
+	transport := New(fallback).SetTLSConfig(clientTLS.Clone())
+	t.Cleanup(func() { transport.Close() })
+	return tlsServer, transport
+}
+
+// protoHandler records the HTTP major version of the last request
+func protoHandler(proto *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proto.Store(int32(r.ProtoMajor))
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	}
+}
+
+func TestTransportHTTP3(t *testing.T) {
+	var proto atomic.Int32
+	server, transport := newHTTP3Server(t, protoHandler(&proto))
+
+	client := rdap.NewClient().
+		SetBootstrapData([]byte(`{"services": [[["com"], ["` + server.URL + `/"]]]}`)).
+		SetTransport(transport)
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if got := proto.Load(); got != 3 {
+		t.Errorf("Expected an HTTP/3 request, got HTTP/%d", got)
+	}
+}
+
+func TestTransportFallback(t *testing.T) {
+	var proto atomic.Int32
+	server := httptest.NewTLSServer(protoHandler(&proto))
+	defer server.Close()
+
+	clientTLS := server.Client().Transport.(*http.Transport).TLSClientConfig
+	fallback := rdap.NewTransport()
+	fallback.TLSClientConfig = clientTLS.Clone()
+	transport := New(fallback).SetTLSConfig(clientTLS.Clone()).SetHandshakeTimeout(200 * time.Millisecond)
+	defer transport.Close()
+
+	// The server has no HTTP/3 listener, so the request falls back
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL + "/domain/example.com")
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	resp.Body.Close()
+	if got := proto.Load(); got == 3 || got == 0 {
+		t.Errorf("Expected a fallback request, got HTTP/%d", got)
+	}
+
+	// The host is then queried with the fallback transport right away
+	start := time.Now()
+	resp, err = client.Get(server.URL + "/domain/example.com")
+	if err != nil {
+		t.Fatalf("Second request failed: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected HTTP/3 to be skipped during the cooldown, took %v", elapsed)
+	}
+}
+
+func TestTransportPlainHTTP(t *testing.T) {
+	var proto atomic.Int32
+	server := httptest.NewServer(protoHandler(&proto))
+	defer server.Close()
+
+	client := &http.Client{Transport: New(nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if got := proto.Load(); got != 1 {
+		t.Errorf("Expected plain http to use the fallback, got HTTP/%d", got)
+	}
+}
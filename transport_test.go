@@ -0,0 +1,139 @@
+package rdap
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDefaultTransport(t *testing.T) {
+	transport := NewClient().transport()
+	if transport == nil {
+		t.Fatal("Expected the default client to have an *http.Transport")
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("Expected HTTP/2 to be enabled")
+	}
+	if transport.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || transport.TLSHandshakeTimeout != defaultTLSHandshakeTimeout {
+		t.Errorf("Unexpected transport defaults: %+v", transport)
+	}
+}
+
+func TestTransportSetters(t *testing.T) {
+	client := NewClient().
+		SetMaxIdleConns(10).
+		SetMaxIdleConnsPerHost(4).
+		SetMaxConnsPerHost(8).
+		SetIdleConnTimeout(time.Minute).
+		SetTLSHandshakeTimeout(5 * time.Second)
+
+	transport := client.transport()
+	if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 4 || transport.MaxConnsPerHost != 8 {
+		t.Errorf("Unexpected pool sizes: %d, %d, %d", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+	if transport.IdleConnTimeout != time.Minute || transport.TLSHandshakeTimeout != 5*time.Second {
+		t.Errorf("Unexpected timeouts: %v, %v", transport.IdleConnTimeout, transport.TLSHandshakeTimeout)
+	}
+
+	// Custom HTTP clients are left alone
+	custom := NewClient().SetHTTPClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, nil
+	}))
+	if custom.SetMaxIdleConnsPerHost(4).transport() != nil {
+		t.Error("Expected no transport for a custom HTTP client")
+	}
+}
+
+func TestTransportHTTP2(t *testing.T) {
+	var proto atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto.Store(int32(r.ProtoMajor))
+		w.Write([]byte(`{}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient()
+	client.transport().TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	defer client.CloseIdleConnections()
+
+	if _, err := client.doRequest(server.URL+"/", server.URL+"/domain/example.com"); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if got := proto.Load(); got != 2 {
+		t.Errorf("Expected an HTTP/2 request, got HTTP/%d", got)
+	}
+}
+
+func TestSetTransport(t *testing.T) {
+	var calls atomic.Int32
+	roundTripper := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client := newMockDomainClient(t, serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	client.SetTransport(roundTripper)
+
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+	if calls.Load() == 0 {
+		t.Error("Expected queries to go through the transport")
+	}
+	if httpClient := client.httpClient.(*http.Client); httpClient.Timeout != defaultTimeout || httpClient.CheckRedirect == nil {
+		t.Error("Expected the client timeout and redirect handling to be kept")
+	}
+
+	// A custom HTTPClient is replaced by the default one
+	custom := NewClient().SetHTTPClient(httpClientFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, nil
+	})).SetTransport(roundTripper)
+	if _, ok := custom.httpClient.(*http.Client); !ok {
+		t.Error("Expected the default HTTP client")
+	}
+}
+
+func TestNewClientWithTransport(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(serveJSON(`{"objectClassName": "domain", "ldhName": "example.com"}`))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	shared := NewTransport()
+	defer shared.CloseIdleConnections()
+	tenants := []*Client{NewClientWithTransport(shared), NewClientWithTransport(shared)}
+	for _, client := range tenants {
+		client.SetAllowInsecureServers(true).SetServerOverride("com", server.URL)
+		if _, err := client.RDAP("example.com"); err != nil {
+			t.Fatalf("RDAP failed: %v", err)
+		}
+	}
+	if got := conns.Load(); got != 1 {
+		t.Errorf("Expected the clients to share one connection, got %d", got)
+	}
+
+	// A transport setter gives the client its own copy
+	tenants[1].SetMaxIdleConnsPerHost(2)
+	if own := tenants[1].transport(); own == shared || own.MaxIdleConnsPerHost != 2 {
+		t.Error("Expected the client to get its own transport")
+	}
+	if shared.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || tenants[0].transport() != shared {
+		t.Error("Expected the shared transport to be left untouched")
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
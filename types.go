@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"github.com/ducksify/gordap/types"
+)
+
+// The RDAP object model is defined in the types package and aliased here
+// for convenience
+type (
+	// Domain represents an RDAP domain object
+	Domain = types.Domain
+	// Entity represents an RDAP entity object
+	Entity = types.Entity
+	// Nameserver represents an RDAP nameserver object
+	Nameserver = types.Nameserver
+	// IPAddresses represents the addresses of a nameserver
+	IPAddresses = types.IPAddresses
+	// IPNetwork represents an RDAP IP network object
+	IPNetwork = types.IPNetwork
+	// Autnum represents an RDAP autonomous system number object
+	Autnum = types.Autnum
+	// Event represents an RDAP event
+	Event = types.Event
+	// Link represents an RDAP link
+	Link = types.Link
+	// Notice represents an RDAP notice
+	Notice = types.Notice
+	// Remark represents an RDAP remark
+	Remark = types.Remark
+	// PublicID represents an RDAP public identifier
+	PublicID = types.PublicID
+	// SecureDNS represents the DNSSEC information of a domain
+	SecureDNS = types.SecureDNS
+	// Registrar holds the registrar details of a domain
+	Registrar = types.Registrar
+	// Contact holds the contact details parsed from an entity's jCard
+	Contact = types.Contact
+	// Status is an RDAP status value
+	Status = types.Status
+	// Conformance is the rdapConformance array of an RDAP response
+	Conformance = types.Conformance
+)
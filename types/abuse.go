@@ -0,0 +1,52 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+// FindAbuseContact returns the contact of the first abuse entity that has an
+// email or phone, searching nested entities (such as the abuse entity under
+// a registrar) depth-first
+func FindAbuseContact(entities []Entity) (*Contact, bool) {
+	for i := range entities {
+		if entities[i].HasRole(RoleAbuse) {
+			if contact, err := entities[i].Contact(); err == nil && contact != nil &&
+				(len(contact.Emails) > 0 || len(contact.Phones) > 0) {
+				return contact, true
+			}
+		}
+		if contact, ok := FindAbuseContact(entities[i].Entities); ok {
+			return contact, true
+		}
+	}
+	return nil, false
+}
+
+// AbuseContact returns the abuse contact of the domain
+func (d *Domain) AbuseContact() (*Contact, bool) {
+	return FindAbuseContact(d.Entities)
+}
+
+// AbuseContact returns the abuse contact of the IP network
+func (n *IPNetwork) AbuseContact() (*Contact, bool) {
+	return FindAbuseContact(n.Entities)
+}
+
+// AbuseContact returns the abuse contact of the autnum
+func (a *Autnum) AbuseContact() (*Contact, bool) {
+	return FindAbuseContact(a.Entities)
+}
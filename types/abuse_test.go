@@ -0,0 +1,55 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDomainAbuseContact(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleRegistrarDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	contact, ok := domain.AbuseContact()
+	if !ok {
+		t.Fatal("Expected abuse contact nested under the registrar")
+	}
+	if contact.Emails[0] != "abusecomplaints@markmonitor.com" {
+		t.Errorf("Unexpected abuse email: %v", contact.Emails)
+	}
+}
+
+func TestIPNetworkAbuseContact(t *testing.T) {
+	network := IPNetwork{
+		Entities: []Entity{
+			{
+				Roles: []string{RoleAbuse},
+				// An abuse entity without any usable detail is skipped
+				VCardArray: []interface{}{"vcard", []interface{}{
+					[]interface{}{"fn", map[string]interface{}{}, "text", "Empty"},
+				}},
+			},
+			{
+				Roles: []string{RoleRegistrant},
+				Entities: []Entity{
+					{
+						Roles: []string{RoleAbuse},
+						VCardArray: []interface{}{"vcard", []interface{}{
+							[]interface{}{"email", map[string]interface{}{}, "text", "abuse@example.net"},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	contact, ok := network.AbuseContact()
+	if !ok || contact.Emails[0] != "abuse@example.net" {
+		t.Errorf("Expected nested abuse contact, got %+v", contact)
+	}
+
+	if _, ok := (&Autnum{}).AbuseContact(); ok {
+		t.Error("Expected no abuse contact for an autnum without entities")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Conformance is the rdapConformance array of an RDAP response
+type Conformance []string
+
+// ParseConformance extracts the rdapConformance array from any RDAP response
+func ParseConformance(data []byte) (Conformance, error) {
+	var response struct {
+		Conformance Conformance `json:"rdapConformance"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse rdapConformance: %w", err)
+	}
+	return response.Conformance, nil
+}
+
+// HasExtension reports whether the given extension identifier is listed, e.g. "rdap_level_0"
+func (c Conformance) HasExtension(extension string) bool {
+	for _, value := range c {
+		if strings.EqualFold(value, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExtensionPrefix reports whether any listed identifier starts with the
+// given prefix, e.g. "icann_rdap_response_profile" to match any profile version
+func (c Conformance) HasExtensionPrefix(prefix string) bool {
+	prefix = strings.ToLower(prefix)
+	for _, value := range c {
+		if strings.HasPrefix(strings.ToLower(value), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsReverseSearch reports whether the RFC 9536 reverse search extension is listed
+func (c Conformance) SupportsReverseSearch() bool {
+	return c.HasExtension("reverse_search")
+}
+
+// SupportsRedaction reports whether the RFC 9537 redaction extension is listed
+func (c Conformance) SupportsRedaction() bool {
+	return c.HasExtension("redacted")
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseConformance(t *testing.T) {
+	data := []byte(`{"rdapConformance": ["rdap_level_0", "icann_rdap_response_profile_1", "Redacted"], "objectClassName": "domain"}`)
+
+	conformance, err := ParseConformance(data)
+	if err != nil {
+		t.Fatalf("Failed to parse conformance: %v", err)
+	}
+
+	if !conformance.HasExtension("rdap_level_0") {
+		t.Error("Expected rdap_level_0 to be listed")
+	}
+	if conformance.HasExtension("cidr0") {
+		t.Error("Expected cidr0 not to be listed")
+	}
+	if !conformance.HasExtensionPrefix("icann_rdap_response_profile") {
+		t.Error("Expected response profile prefix to match")
+	}
+	if !conformance.SupportsRedaction() {
+		t.Error("Expected redaction support to be detected case-insensitively")
+	}
+	if conformance.SupportsReverseSearch() {
+		t.Error("Expected no reverse search support")
+	}
+
+	if _, err := ParseConformance([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestDomainConformance(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+	if !domain.Conformance.HasExtension("icann_rdap_technical_implementation_guide_1") {
+		t.Errorf("Expected domain conformance to be inspectable, got %v", domain.Conformance)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event actions registered in the IANA RDAP JSON values registry
+const (
+	EventRegistration             = "registration"
+	EventReregistration           = "reregistration"
+	EventLastChanged              = "last changed"
+	EventExpiration               = "expiration"
+	EventDeletion                 = "deletion"
+	EventReinstantiation          = "reinstantiation"
+	EventTransfer                 = "transfer"
+	EventLocked                   = "locked"
+	EventUnlocked                 = "unlocked"
+	EventLastRDAPUpdate           = "last update of RDAP database"
+	EventRegistrarExpiration      = "registrar expiration"
+	EventEnumValidationExpiration = "enum validation expiration"
+)
+
+// ErrEventNotFound is returned when an object has no event with the requested action
+var ErrEventNotFound = errors.New("event not found")
+
+// eventActionAliases maps the non-standard action names used by some
+// registries to their registered event action
+var eventActionAliases = map[string]string{
+	"registered":        EventRegistration,
+	"created":           EventRegistration,
+	"creation":          EventRegistration,
+	"registration date": EventRegistration,
+	"expiry":            EventExpiration,
+	"expires":           EventExpiration,
+	"expiration date":   EventExpiration,
+	"last update":       EventLastChanged,
+	"last updated":      EventLastChanged,
+	"last modified":     EventLastChanged,
+	"last changed date": EventLastChanged,
+}
+
+// eventDateLayouts lists the date formats registries actually use, tried in order
+var eventDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// NormalizeEventAction returns the registered event action for an action
+// name, folding case, separators and common registry-specific aliases
+func NormalizeEventAction(action string) string {
+	action = strings.ToLower(strings.TrimSpace(action))
+	action = strings.NewReplacer("_", " ", "-", " ").Replace(action)
+	action = strings.Join(strings.Fields(action), " ")
+
+	if alias, ok := eventActionAliases[action]; ok {
+		return alias
+	}
+	if action == strings.ToLower(EventLastRDAPUpdate) {
+		return EventLastRDAPUpdate
+	}
+	return action
+}
+
+// ParseEventDate parses an event date, accepting RFC 3339 as well as the
+// offsets without colon, missing time zones (taken as UTC) and date-only
+// values returned by some registries
+func ParseEventDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range eventDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event date: %q", value)
+}
+
+// Time returns the parsed event date
+func (e Event) Time() (time.Time, error) {
+	return ParseEventDate(e.EventDate)
+}
+
+// FindEvent returns the first event with the given action, compared after normalization
+func FindEvent(events []Event, action string) (*Event, bool) {
+	action = NormalizeEventAction(action)
+	for i := range events {
+		if NormalizeEventAction(events[i].EventAction) == action {
+			return &events[i], true
+		}
+	}
+	return nil, false
+}
+
+// eventTime returns the parsed date of the first event with the given action
+func eventTime(events []Event, action string) (time.Time, error) {
+	event, ok := FindEvent(events, action)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrEventNotFound, action)
+	}
+	return event.Time()
+}
+
+// CreationDate returns the date of the domain's registration event
+func (d *Domain) CreationDate() (time.Time, error) {
+	return eventTime(d.Events, EventRegistration)
+}
+
+// ExpirationDate returns the date of the domain's expiration event
+func (d *Domain) ExpirationDate() (time.Time, error) {
+	return eventTime(d.Events, EventExpiration)
+}
+
+// LastChanged returns the date of the domain's last changed event
+func (d *Domain) LastChanged() (time.Time, error) {
+	return eventTime(d.Events, EventLastChanged)
+}
+
+// Age returns the time elapsed at now since the domain was registered. When
+// the response lists several registration events the earliest parseable one
+// is used, and a later reregistration event restarts the age since the name
+// then changed hands. Events with unparseable dates are ignored.
+func (d *Domain) Age(now time.Time) (time.Duration, error) {
+	var registered, reregistered time.Time
+	var sawEvent bool
+	for _, event := range d.Events {
+		action := NormalizeEventAction(event.EventAction)
+		if action != EventRegistration && action != EventReregistration {
+			continue
+		}
+		sawEvent = true
+
+		t, err := event.Time()
+		if err != nil {
+			continue
+		}
+		switch action {
+		case EventRegistration:
+			if registered.IsZero() || t.Before(registered) {
+				registered = t
+			}
+		case EventReregistration:
+			if t.After(reregistered) {
+				reregistered = t
+			}
+		}
+	}
+
+	start := registered
+	if reregistered.After(start) {
+		start = reregistered
+	}
+	if start.IsZero() {
+		if sawEvent {
+			return 0, fmt.Errorf("no registration event with a valid date")
+		}
+		return 0, fmt.Errorf("%w: %s", ErrEventNotFound, EventRegistration)
+	}
+	if start.After(now) {
+		return 0, fmt.Errorf("registration date %s is in the future", start.Format(time.RFC3339))
+	}
+
+	return now.Sub(start), nil
+}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeEventAction(t *testing.T) {
+	tests := []struct {
+		action   string
+		expected string
+	}{
+		{"registration", EventRegistration},
+		{"Registration", EventRegistration},
+		{"created", EventRegistration},
+		{"expiry", EventExpiration},
+		{"last_changed", EventLastChanged},
+		{"Last-Changed", EventLastChanged},
+		{"last update of RDAP database", EventLastRDAPUpdate},
+		{" transfer ", EventTransfer},
+	}
+
+	for _, test := range tests {
+		if result := NormalizeEventAction(test.action); result != test.expected {
+			t.Errorf("NormalizeEventAction(%q) = %q, expected %q", test.action, result, test.expected)
+		}
+	}
+}
+
+func TestParseEventDate(t *testing.T) {
+	expected := time.Date(2024, 8, 13, 7, 1, 38, 0, time.UTC)
+
+	for _, value := range []string{
+		"2024-08-13T07:01:38Z",
+		"2024-08-13T09:01:38+02:00",
+		"2024-08-13T09:01:38+0200",
+		"2024-08-13T07:01:38.000Z",
+		"2024-08-13T07:01:38",
+		"2024-08-13 07:01:38",
+	} {
+		result, err := ParseEventDate(value)
+		if err != nil {
+			t.Errorf("ParseEventDate(%q) returned error: %v", value, err)
+			continue
+		}
+		if !result.Equal(expected) {
+			t.Errorf("ParseEventDate(%q) = %v, expected %v", value, result, expected)
+		}
+	}
+
+	if result, err := ParseEventDate("2024-08-13"); err != nil || !result.Equal(time.Date(2024, 8, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected date-only value to parse, got %v, %v", result, err)
+	}
+	if _, err := ParseEventDate("13/08/2024"); err == nil {
+		t.Error("Expected error for unsupported date format")
+	}
+}
+
+func TestDomainEventDates(t *testing.T) {
+	domain := Domain{
+		Events: []Event{
+			{EventAction: "registration", EventDate: "1995-08-14T04:00:00Z"},
+			{EventAction: "expiration", EventDate: "2025-08-13T04:00:00Z"},
+			{EventAction: "last changed", EventDate: "2024-08-14T07:01:38+00:00"},
+		},
+	}
+
+	created, err := domain.CreationDate()
+	if err != nil || created.Year() != 1995 {
+		t.Errorf("Unexpected creation date: %v, %v", created, err)
+	}
+	expires, err := domain.ExpirationDate()
+	if err != nil || expires.Year() != 2025 {
+		t.Errorf("Unexpected expiration date: %v, %v", expires, err)
+	}
+	changed, err := domain.LastChanged()
+	if err != nil || changed.Year() != 2024 {
+		t.Errorf("Unexpected last changed date: %v, %v", changed, err)
+	}
+
+	empty := Domain{}
+	if _, err := empty.ExpirationDate(); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound, got %v", err)
+	}
+}
+
+func TestDomainAge(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		events   []Event
+		expected time.Duration
+	}{
+		{
+			name:     "single registration",
+			events:   []Event{{EventAction: "registration", EventDate: "2024-12-31T00:00:00Z"}},
+			expected: 24 * time.Hour,
+		},
+		{
+			name: "earliest of several registrations",
+			events: []Event{
+				{EventAction: "registration", EventDate: "2024-12-31T00:00:00Z"},
+				{EventAction: "registration", EventDate: "2024-12-30T00:00:00Z"},
+			},
+			expected: 48 * time.Hour,
+		},
+		{
+			name: "reregistration restarts age",
+			events: []Event{
+				{EventAction: "registration", EventDate: "2000-01-01T00:00:00Z"},
+				{EventAction: "reregistration", EventDate: "2024-12-31T12:00:00Z"},
+			},
+			expected: 12 * time.Hour,
+		},
+		{
+			name: "invalid dates are skipped",
+			events: []Event{
+				{EventAction: "registration", EventDate: "unknown"},
+				{EventAction: "created", EventDate: "2024-12-31"},
+			},
+			expected: 24 * time.Hour,
+		},
+	}
+
+	for _, test := range tests {
+		domain := Domain{Events: test.events}
+		age, err := domain.Age(now)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if age != test.expected {
+			t.Errorf("%s: expected age %v, got %v", test.name, test.expected, age)
+		}
+	}
+}
+
+func TestDomainAgeErrors(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := (&Domain{}).Age(now); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound, got %v", err)
+	}
+
+	invalid := Domain{Events: []Event{{EventAction: "registration", EventDate: "n/a"}}}
+	if _, err := invalid.Age(now); err == nil || errors.Is(err, ErrEventNotFound) {
+		t.Errorf("Expected invalid date error, got %v", err)
+	}
+
+	future := Domain{Events: []Event{{EventAction: "registration", EventDate: "2026-01-01T00:00:00Z"}}}
+	if _, err := future.Age(now); err == nil {
+		t.Error("Expected error for registration date in the future")
+	}
+}
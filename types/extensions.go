@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// CIDR represents a cidr0 extension block, holding either a v4prefix or a v6prefix
+type CIDR struct {
+	V4Prefix string `json:"v4prefix,omitempty"`
+	V6Prefix string `json:"v6prefix,omitempty"`
+	Length   int    `json:"length"`
+}
+
+// Prefix returns the CIDR block as a netip.Prefix
+func (c CIDR) Prefix() (netip.Prefix, error) {
+	address := c.V4Prefix
+	if address == "" {
+		address = c.V6Prefix
+	}
+
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid cidr0 prefix: %s", address)
+	}
+	prefix, err := addr.Prefix(c.Length)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid cidr0 length %d for %s", c.Length, address)
+	}
+	return prefix, nil
+}
+
+// Prefixes returns the cidr0 blocks of the network, skipping malformed entries
+func (n *IPNetwork) Prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, cidr := range n.CIDRs {
+		if prefix, err := cidr.Prefix(); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
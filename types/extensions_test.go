@@ -0,0 +1,53 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const sampleIPNetwork = `{
+	"objectClassName": "ip network",
+	"handle": "NET-192-0-2-0-1",
+	"startAddress": "192.0.2.0",
+	"endAddress": "192.0.3.255",
+	"ipVersion": "v4",
+	"rdapConformance": ["rdap_level_0", "cidr0", "arin_originas0"],
+	"cidr0_cidrs": [
+		{"v4prefix": "192.0.2.0", "length": 24},
+		{"v4prefix": "192.0.3.0", "length": 24},
+		{"v6prefix": "2001:db8::", "length": 32},
+		{"v4prefix": "bogus", "length": 8}
+	],
+	"arin_originas0_originautnums": [64496, 4200000000]
+}`
+
+func TestIPNetworkExtensions(t *testing.T) {
+	var network IPNetwork
+	if err := json.Unmarshal([]byte(sampleIPNetwork), &network); err != nil {
+		t.Fatalf("Failed to unmarshal IP network: %v", err)
+	}
+
+	if len(network.CIDRs) != 4 {
+		t.Fatalf("Expected 4 cidr0 entries, got %d", len(network.CIDRs))
+	}
+	if len(network.OriginAutnums) != 2 || network.OriginAutnums[1] != 4200000000 {
+		t.Errorf("Unexpected origin autnums: %v", network.OriginAutnums)
+	}
+	if _, ok := network.Extra["cidr0_cidrs"]; ok {
+		t.Error("Extension members must not be duplicated in Extra")
+	}
+
+	prefixes := network.Prefixes()
+	if len(prefixes) != 3 {
+		t.Fatalf("Expected 3 valid prefixes, got %v", prefixes)
+	}
+	if prefixes[0].String() != "192.0.2.0/24" || prefixes[2].String() != "2001:db8::/32" {
+		t.Errorf("Unexpected prefixes: %v", prefixes)
+	}
+}
+
+func TestCIDRPrefixInvalidLength(t *testing.T) {
+	if _, err := (CIDR{V4Prefix: "192.0.2.0", Length: 33}).Prefix(); err == nil {
+		t.Error("Expected error for invalid prefix length")
+	}
+}
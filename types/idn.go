@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DisplayName returns the name of the domain for display: its unicodeName
+// when the server gave one, otherwise its ldhName converted to U-labels, so
+// "xn--bcher-kva.de" is shown as "bücher.de". Names that are not valid IDNs
+// are returned as is.
+func (d *Domain) DisplayName() string {
+	if d.UnicodeName != "" {
+		return strings.TrimSuffix(d.UnicodeName, ".")
+	}
+	name := strings.TrimSuffix(d.LDHName, ".")
+	if unicode, err := idna.Display.ToUnicode(name); err == nil {
+		return unicode
+	}
+	return name
+}
@@ -0,0 +1,20 @@
+package types
+
+import "testing"
+
+func TestDomainDisplayName(t *testing.T) {
+	tests := []struct {
+		domain Domain
+		want   string
+	}{
+		{Domain{LDHName: "xn--bcher-kva.de", UnicodeName: "bücher.de."}, "bücher.de"},
+		{Domain{LDHName: "XN--BCHER-KVA.DE."}, "bücher.de"},
+		{Domain{LDHName: "example.com"}, "example.com"},
+		{Domain{LDHName: "xn--zz.com"}, "xn--zz.com"},
+	}
+	for _, test := range tests {
+		if got := test.domain.DisplayName(); got != test.want {
+			t.Errorf("DisplayName() of %+v = %q, want %q", test.domain, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// knownFieldsCache caches the JSON member names of each struct type
+var knownFieldsCache sync.Map
+
+// Strings is a list of strings that also accepts a single JSON string,
+// as allowed for members such as a link's hreflang
+type Strings []string
+
+// UnmarshalJSON decodes either a JSON string or an array of strings
+func (s *Strings) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = Strings{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// unmarshalWithExtra decodes data into v and stores the members that v does
+// not declare into extra, so they survive a decode/encode round trip
+func unmarshalWithExtra(data []byte, v interface{}, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, name := range knownFields(reflect.TypeOf(v).Elem()) {
+		delete(raw, name)
+	}
+
+	*extra = nil
+	if len(raw) > 0 {
+		*extra = raw
+	}
+	return nil
+}
+
+// marshalWithExtra encodes v and merges back the preserved unknown members
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, ok := raw[name]; !ok {
+			raw[name] = value
+		}
+	}
+	return json.Marshal(raw)
+}
+
+// knownFields returns the JSON member names declared by a struct type
+func knownFields(t reflect.Type) []string {
+	if cached, ok := knownFieldsCache.Load(t); ok {
+		return cached.([]string)
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	knownFieldsCache.Store(t, names)
+	return names
+}
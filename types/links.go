@@ -0,0 +1,49 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"strings"
+)
+
+// rdapMediaType is the media type of RDAP responses
+const rdapMediaType = "application/rdap+json"
+
+// RelatedLink returns the "related" link to the same domain at another RDAP
+// server, such as the registrar server a thin registry refers to. A link of
+// the RDAP media type is preferred over an untyped link to a domain path.
+func (d *Domain) RelatedLink() (*Link, bool) {
+	var untyped *Link
+	for i := range d.Links {
+		link := &d.Links[i]
+		if !strings.EqualFold(link.Rel, "related") || link.Href == "" {
+			continue
+		}
+		mediaType, _, _ := strings.Cut(link.Type, ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case rdapMediaType:
+			return link, true
+		case "":
+			if untyped == nil && strings.Contains(strings.ToLower(link.Href), "/domain/") {
+				untyped = link
+			}
+		}
+	}
+	return untyped, untyped != nil
+}
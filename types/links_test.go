@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestRelatedLink(t *testing.T) {
+	domain := Domain{Links: []Link{
+		{Rel: "self", Href: "https://rdap.verisign.com/com/v1/domain/EXAMPLE.COM", Type: "application/rdap+json"},
+		{Rel: "related", Href: "https://registrar.example/whois"},
+		{Rel: "related", Href: "https://rdap.registrar.example/domain/example.com"},
+		{Rel: "Related", Href: "https://rdap.markmonitor.com/rdap/domain/EXAMPLE.COM", Type: "application/rdap+json; charset=utf-8"},
+	}}
+
+	link, ok := domain.RelatedLink()
+	if !ok || link.Href != "https://rdap.markmonitor.com/rdap/domain/EXAMPLE.COM" {
+		t.Errorf("Expected the RDAP related link, got %+v", link)
+	}
+
+	// Without a typed link, an untyped link to a domain path is used
+	domain.Links = domain.Links[:3]
+	if link, ok := domain.RelatedLink(); !ok || link.Href != "https://rdap.registrar.example/domain/example.com" {
+		t.Errorf("Expected the untyped domain link, got %+v", link)
+	}
+
+	domain.Links = domain.Links[:2]
+	if link, ok := domain.RelatedLink(); ok {
+		t.Errorf("Expected no related link, got %+v", link)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"strings"
+)
+
+// NameserverNames returns the lowercase LDH host names of the domain's
+// nameservers without trailing dots or duplicates, in response order
+func (d *Domain) NameserverNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ns := range d.Nameservers {
+		name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(ns.LDHName)), ".")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestDomainNameserverNames(t *testing.T) {
+	domain := Domain{
+		Nameservers: []Nameserver{
+			{LDHName: "A.IANA-SERVERS.NET"},
+			{LDHName: "b.iana-servers.net."},
+			{LDHName: "a.iana-servers.net"},
+			{UnicodeName: "ns.example"},
+		},
+	}
+
+	names := domain.NameserverNames()
+	if len(names) != 2 {
+		t.Fatalf("Expected 2 distinct nameservers, got %v", names)
+	}
+	if names[0] != "a.iana-servers.net" || names[1] != "b.iana-servers.net" {
+		t.Errorf("Unexpected nameserver names: %v", names)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"strings"
+)
+
+// Entity roles registered in the IANA RDAP JSON values registry
+const (
+	RoleRegistrant     = "registrant"
+	RoleTechnical      = "technical"
+	RoleAdministrative = "administrative"
+	RoleAbuse          = "abuse"
+	RoleBilling        = "billing"
+	RoleRegistrar      = "registrar"
+	RoleReseller       = "reseller"
+	RoleSponsor        = "sponsor"
+	RoleProxy          = "proxy"
+	RoleNOC            = "noc"
+)
+
+// ianaRegistrarIDType is the publicIds type carrying the IANA registrar ID
+const ianaRegistrarIDType = "IANA Registrar ID"
+
+// Registrar holds the registrar details of a domain
+type Registrar struct {
+	Handle string
+	Name   string
+	// IANAID is the registrar's IANA ID taken from its publicIds
+	IANAID string
+	// Abuse is the contact of the abuse entity nested under the registrar
+	Abuse *Contact
+	// Entity is the registrar entity the details were taken from
+	Entity *Entity
+}
+
+// HasRole reports whether the entity has the given role
+func (e *Entity) HasRole(role string) bool {
+	for _, r := range e.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindEntityByRole returns the first entity with the given role, searching
+// nested entities depth-first
+func FindEntityByRole(entities []Entity, role string) (*Entity, bool) {
+	for i := range entities {
+		if entities[i].HasRole(role) {
+			return &entities[i], true
+		}
+		if entity, ok := FindEntityByRole(entities[i].Entities, role); ok {
+			return entity, true
+		}
+	}
+	return nil, false
+}
+
+// Registrar returns the registrar of the domain, or false when no entity has
+// the registrar role
+func (d *Domain) Registrar() (*Registrar, bool) {
+	entity, ok := FindEntityByRole(d.Entities, RoleRegistrar)
+	if !ok {
+		return nil, false
+	}
+
+	registrar := &Registrar{
+		Handle: entity.Handle,
+		Entity: entity,
+	}
+	if contact, err := entity.Contact(); err == nil && contact != nil {
+		registrar.Name = contact.Name
+		if registrar.Name == "" {
+			registrar.Name = contact.Organization
+		}
+	}
+	for _, id := range entity.PublicIDs {
+		if strings.EqualFold(id.Type, ianaRegistrarIDType) {
+			registrar.IANAID = id.Identifier
+			break
+		}
+	}
+	if abuse, ok := FindEntityByRole(entity.Entities, RoleAbuse); ok {
+		if contact, err := abuse.Contact(); err == nil {
+			registrar.Abuse = contact
+		}
+	}
+
+	return registrar, true
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const sampleRegistrarDomain = `{
+	"objectClassName": "domain",
+	"ldhName": "example.com",
+	"entities": [
+		{
+			"objectClassName": "entity",
+			"handle": "REG-1",
+			"roles": ["registrant"]
+		},
+		{
+			"objectClassName": "entity",
+			"handle": "292",
+			"roles": ["Registrar"],
+			"publicIds": [{"type": "IANA Registrar ID", "identifier": "292"}],
+			"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "MarkMonitor Inc."]]],
+			"entities": [
+				{
+					"objectClassName": "entity",
+					"roles": ["abuse"],
+					"vcardArray": ["vcard", [
+						["version", {}, "text", "4.0"],
+						["fn", {}, "text", ""],
+						["tel", {"type": "voice"}, "uri", "tel:+1.2086851750"],
+						["email", {}, "text", "abusecomplaints@markmonitor.com"]
+					]]
+				}
+			]
+		}
+	]
+}`
+
+func TestDomainRegistrar(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleRegistrarDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	registrar, ok := domain.Registrar()
+	if !ok {
+		t.Fatal("Expected registrar to be found")
+	}
+	if registrar.Handle != "292" || registrar.Name != "MarkMonitor Inc." || registrar.IANAID != "292" {
+		t.Errorf("Unexpected registrar: %+v", registrar)
+	}
+	if registrar.Abuse == nil {
+		t.Fatal("Expected registrar abuse contact")
+	}
+	if len(registrar.Abuse.Emails) != 1 || registrar.Abuse.Emails[0] != "abusecomplaints@markmonitor.com" {
+		t.Errorf("Unexpected abuse emails: %v", registrar.Abuse.Emails)
+	}
+	if len(registrar.Abuse.Phones) != 1 || registrar.Abuse.Phones[0].Number != "+1.2086851750" {
+		t.Errorf("Unexpected abuse phones: %v", registrar.Abuse.Phones)
+	}
+}
+
+func TestDomainRegistrarMissing(t *testing.T) {
+	domain := Domain{Entities: []Entity{{Handle: "REG-1", Roles: []string{RoleRegistrant}}}}
+	if _, ok := domain.Registrar(); ok {
+		t.Error("Expected no registrar")
+	}
+}
+
+func TestFindEntityByRoleNested(t *testing.T) {
+	entities := []Entity{
+		{Handle: "A", Roles: []string{RoleRegistrar}, Entities: []Entity{
+			{Handle: "B", Roles: []string{RoleTechnical}},
+		}},
+	}
+
+	entity, ok := FindEntityByRole(entities, RoleTechnical)
+	if !ok || entity.Handle != "B" {
+		t.Errorf("Expected nested technical entity, got %+v", entity)
+	}
+	if _, ok := FindEntityByRole(entities, RoleAbuse); ok {
+		t.Error("Expected no abuse entity")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"strings"
+)
+
+// Status is an RDAP status value
+type Status string
+
+// Status values registered in the IANA RDAP JSON values registry
+const (
+	StatusValidated                = Status("validated")
+	StatusRenewProhibited          = Status("renew prohibited")
+	StatusUpdateProhibited         = Status("update prohibited")
+	StatusTransferProhibited       = Status("transfer prohibited")
+	StatusDeleteProhibited         = Status("delete prohibited")
+	StatusProxy                    = Status("proxy")
+	StatusPrivate                  = Status("private")
+	StatusRemoved                  = Status("removed")
+	StatusObscured                 = Status("obscured")
+	StatusAssociated               = Status("associated")
+	StatusActive                   = Status("active")
+	StatusInactive                 = Status("inactive")
+	StatusLocked                   = Status("locked")
+	StatusPendingCreate            = Status("pending create")
+	StatusPendingRenew             = Status("pending renew")
+	StatusPendingTransfer          = Status("pending transfer")
+	StatusPendingUpdate            = Status("pending update")
+	StatusPendingDelete            = Status("pending delete")
+	StatusAddPeriod                = Status("add period")
+	StatusAutoRenewPeriod          = Status("auto renew period")
+	StatusClientDeleteProhibited   = Status("client delete prohibited")
+	StatusClientHold               = Status("client hold")
+	StatusClientRenewProhibited    = Status("client renew prohibited")
+	StatusClientTransferProhibited = Status("client transfer prohibited")
+	StatusClientUpdateProhibited   = Status("client update prohibited")
+	StatusPendingRestore           = Status("pending restore")
+	StatusRedemptionPeriod         = Status("redemption period")
+	StatusRenewPeriod              = Status("renew period")
+	StatusServerDeleteProhibited   = Status("server delete prohibited")
+	StatusServerRenewProhibited    = Status("server renew prohibited")
+	StatusServerTransferProhibited = Status("server transfer prohibited")
+	StatusServerUpdateProhibited   = Status("server update prohibited")
+	StatusServerHold               = Status("server hold")
+	StatusTransferPeriod           = Status("transfer period")
+	StatusAdministrative           = Status("administrative")
+	StatusReserved                 = Status("reserved")
+)
+
+// eppStatuses maps RDAP status values to EPP status codes as defined by RFC 8056
+var eppStatuses = map[Status]string{
+	StatusAddPeriod:                "addPeriod",
+	StatusAutoRenewPeriod:          "autoRenewPeriod",
+	StatusInactive:                 "inactive",
+	StatusActive:                   "ok",
+	StatusPendingCreate:            "pendingCreate",
+	StatusPendingDelete:            "pendingDelete",
+	StatusPendingRenew:             "pendingRenew",
+	StatusPendingRestore:           "pendingRestore",
+	StatusPendingTransfer:          "pendingTransfer",
+	StatusPendingUpdate:            "pendingUpdate",
+	StatusRedemptionPeriod:         "redemptionPeriod",
+	StatusRenewPeriod:              "renewPeriod",
+	StatusServerDeleteProhibited:   "serverDeleteProhibited",
+	StatusServerHold:               "serverHold",
+	StatusServerRenewProhibited:    "serverRenewProhibited",
+	StatusServerTransferProhibited: "serverTransferProhibited",
+	StatusServerUpdateProhibited:   "serverUpdateProhibited",
+	StatusTransferPeriod:           "transferPeriod",
+	StatusClientDeleteProhibited:   "clientDeleteProhibited",
+	StatusClientHold:               "clientHold",
+	StatusClientRenewProhibited:    "clientRenewProhibited",
+	StatusClientTransferProhibited: "clientTransferProhibited",
+	StatusClientUpdateProhibited:   "clientUpdateProhibited",
+	StatusAssociated:               "linked",
+}
+
+// rdapStatuses maps EPP status codes back to RDAP status values
+var rdapStatuses = func() map[string]Status {
+	statuses := make(map[string]Status, len(eppStatuses))
+	for status, code := range eppStatuses {
+		statuses[strings.ToLower(code)] = status
+	}
+	return statuses
+}()
+
+// ParseStatus normalizes a status value as returned by a registry, folding
+// case and accepting EPP codes such as "clientTransferProhibited" that some
+// servers return instead of the RDAP value
+func ParseStatus(value string) Status {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if status, ok := rdapStatuses[value]; ok {
+		return status
+	}
+	return Status(strings.Join(strings.Fields(value), " "))
+}
+
+// StatusFromEPP returns the RDAP status value for an EPP status code
+func StatusFromEPP(code string) (Status, bool) {
+	status, ok := rdapStatuses[strings.ToLower(strings.TrimSpace(code))]
+	return status, ok
+}
+
+// EPP returns the EPP status code for the status, or false when the status
+// has no EPP equivalent
+func (s Status) EPP() (string, bool) {
+	code, ok := eppStatuses[ParseStatus(string(s))]
+	return code, ok
+}
+
+// Normalize returns the registered form of the status
+func (s Status) Normalize() Status {
+	return ParseStatus(string(s))
+}
+
+// String returns the status value
+func (s Status) String() string {
+	return string(s)
+}
+
+// HasStatus reports whether the list contains the given status, compared after normalization
+func HasStatus(statuses []Status, status Status) bool {
+	status = status.Normalize()
+	for _, s := range statuses {
+		if s.Normalize() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HasStatus reports whether the domain has the given status
+func (d *Domain) HasStatus(status Status) bool {
+	return HasStatus(d.Status, status)
+}
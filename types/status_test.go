@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Status
+	}{
+		{"client transfer prohibited", StatusClientTransferProhibited},
+		{"Client Transfer  Prohibited", StatusClientTransferProhibited},
+		{"clientTransferProhibited", StatusClientTransferProhibited},
+		{"ok", StatusActive},
+		{"linked", StatusAssociated},
+		{"redemption period", StatusRedemptionPeriod},
+		{"some registry status", Status("some registry status")},
+	}
+
+	for _, test := range tests {
+		if result := ParseStatus(test.value); result != test.expected {
+			t.Errorf("ParseStatus(%q) = %q, expected %q", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestStatusEPPMapping(t *testing.T) {
+	code, ok := StatusClientHold.EPP()
+	if !ok || code != "clientHold" {
+		t.Errorf("Expected EPP code clientHold, got %q (%v)", code, ok)
+	}
+	code, ok = StatusActive.EPP()
+	if !ok || code != "ok" {
+		t.Errorf("Expected EPP code ok, got %q (%v)", code, ok)
+	}
+	if _, ok := StatusValidated.EPP(); ok {
+		t.Error("Expected validated to have no EPP equivalent")
+	}
+
+	status, ok := StatusFromEPP("pendingDelete")
+	if !ok || status != StatusPendingDelete {
+		t.Errorf("Expected pending delete, got %q (%v)", status, ok)
+	}
+	if _, ok := StatusFromEPP("unknownStatus"); ok {
+		t.Error("Expected unknown EPP code not to map")
+	}
+}
+
+func TestDomainHasStatus(t *testing.T) {
+	var domain Domain
+	data := `{"objectClassName": "domain", "status": ["Client Hold", "serverDeleteProhibited"]}`
+	if err := json.Unmarshal([]byte(data), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	if !domain.HasStatus(StatusClientHold) || !domain.HasStatus(StatusServerDeleteProhibited) {
+		t.Errorf("Expected statuses to match after normalization, got %v", domain.Status)
+	}
+	if domain.HasStatus(StatusActive) {
+		t.Error("Expected domain not to be active")
+	}
+
+	switch domain.Status[0].Normalize() {
+	case StatusClientHold:
+	default:
+		t.Errorf("Expected switch on normalized status, got %q", domain.Status[0])
+	}
+}
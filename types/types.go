@@ -0,0 +1,411 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// Package types defines the RDAP object model of RFC 9083.
+//
+// Every object keeps the JSON members it does not declare in its Extra field,
+// so registry extensions are not lost when decoding and encoding responses.
+package types
+
+import (
+	"encoding/json"
+)
+
+// Domain represents an RDAP domain object
+type Domain struct {
+	ObjectClassName string       `json:"objectClassName"`
+	Handle          string       `json:"handle,omitempty"`
+	LDHName         string       `json:"ldhName,omitempty"`
+	UnicodeName     string       `json:"unicodeName,omitempty"`
+	Variants        []Variant    `json:"variants,omitempty"`
+	Nameservers     []Nameserver `json:"nameservers,omitempty"`
+	SecureDNS       *SecureDNS   `json:"secureDNS,omitempty"`
+	Entities        []Entity     `json:"entities,omitempty"`
+	Status          []Status     `json:"status,omitempty"`
+	PublicIDs       []PublicID   `json:"publicIds,omitempty"`
+	Remarks         []Remark     `json:"remarks,omitempty"`
+	Links           []Link       `json:"links,omitempty"`
+	Port43          string       `json:"port43,omitempty"`
+	Events          []Event      `json:"events,omitempty"`
+	Network         *IPNetwork   `json:"network,omitempty"`
+	Lang            string       `json:"lang,omitempty"`
+	Conformance     Conformance  `json:"rdapConformance,omitempty"`
+	Notices         []Notice     `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Entity represents an RDAP entity object
+type Entity struct {
+	ObjectClassName string        `json:"objectClassName"`
+	Handle          string        `json:"handle,omitempty"`
+	VCardArray      []interface{} `json:"vcardArray,omitempty"`
+	Roles           []string      `json:"roles,omitempty"`
+	PublicIDs       []PublicID    `json:"publicIds,omitempty"`
+	Entities        []Entity      `json:"entities,omitempty"`
+	Remarks         []Remark      `json:"remarks,omitempty"`
+	Links           []Link        `json:"links,omitempty"`
+	Events          []Event       `json:"events,omitempty"`
+	AsEventActor    []Event       `json:"asEventActor,omitempty"`
+	Status          []Status      `json:"status,omitempty"`
+	Port43          string        `json:"port43,omitempty"`
+	Networks        []IPNetwork   `json:"networks,omitempty"`
+	Autnums         []Autnum      `json:"autnums,omitempty"`
+	Lang            string        `json:"lang,omitempty"`
+	Conformance     Conformance   `json:"rdapConformance,omitempty"`
+	Notices         []Notice      `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Nameserver represents an RDAP nameserver object
+type Nameserver struct {
+	ObjectClassName string       `json:"objectClassName"`
+	Handle          string       `json:"handle,omitempty"`
+	LDHName         string       `json:"ldhName,omitempty"`
+	UnicodeName     string       `json:"unicodeName,omitempty"`
+	IPAddresses     *IPAddresses `json:"ipAddresses,omitempty"`
+	Entities        []Entity     `json:"entities,omitempty"`
+	Status          []Status     `json:"status,omitempty"`
+	Remarks         []Remark     `json:"remarks,omitempty"`
+	Links           []Link       `json:"links,omitempty"`
+	Port43          string       `json:"port43,omitempty"`
+	Events          []Event      `json:"events,omitempty"`
+	Lang            string       `json:"lang,omitempty"`
+	Conformance     Conformance  `json:"rdapConformance,omitempty"`
+	Notices         []Notice     `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// IPAddresses represents the addresses of a nameserver
+type IPAddresses struct {
+	V4 []string `json:"v4,omitempty"`
+	V6 []string `json:"v6,omitempty"`
+}
+
+// IPNetwork represents an RDAP IP network object
+type IPNetwork struct {
+	ObjectClassName string      `json:"objectClassName"`
+	Handle          string      `json:"handle,omitempty"`
+	StartAddress    string      `json:"startAddress,omitempty"`
+	EndAddress      string      `json:"endAddress,omitempty"`
+	IPVersion       string      `json:"ipVersion,omitempty"`
+	Name            string      `json:"name,omitempty"`
+	Type            string      `json:"type,omitempty"`
+	Country         string      `json:"country,omitempty"`
+	ParentHandle    string      `json:"parentHandle,omitempty"`
+	Status          []Status    `json:"status,omitempty"`
+	Entities        []Entity    `json:"entities,omitempty"`
+	Remarks         []Remark    `json:"remarks,omitempty"`
+	Links           []Link      `json:"links,omitempty"`
+	Port43          string      `json:"port43,omitempty"`
+	Events          []Event     `json:"events,omitempty"`
+	Lang            string      `json:"lang,omitempty"`
+	Conformance     Conformance `json:"rdapConformance,omitempty"`
+	Notices         []Notice    `json:"notices,omitempty"`
+
+	// CIDRs lists the network as CIDR blocks (cidr0 extension)
+	CIDRs []CIDR `json:"cidr0_cidrs,omitempty"`
+	// OriginAutnums lists the ASNs originating the network (arin_originas0 extension)
+	OriginAutnums []uint32 `json:"arin_originas0_originautnums,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Autnum represents an RDAP autonomous system number object
+type Autnum struct {
+	ObjectClassName string      `json:"objectClassName"`
+	Handle          string      `json:"handle,omitempty"`
+	StartAutnum     uint32      `json:"startAutnum,omitempty"`
+	EndAutnum       uint32      `json:"endAutnum,omitempty"`
+	Name            string      `json:"name,omitempty"`
+	Type            string      `json:"type,omitempty"`
+	Status          []Status    `json:"status,omitempty"`
+	Country         string      `json:"country,omitempty"`
+	Entities        []Entity    `json:"entities,omitempty"`
+	Remarks         []Remark    `json:"remarks,omitempty"`
+	Links           []Link      `json:"links,omitempty"`
+	Port43          string      `json:"port43,omitempty"`
+	Events          []Event     `json:"events,omitempty"`
+	Lang            string      `json:"lang,omitempty"`
+	Conformance     Conformance `json:"rdapConformance,omitempty"`
+	Notices         []Notice    `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SecureDNS represents the DNSSEC information of a domain
+type SecureDNS struct {
+	ZoneSigned       *bool     `json:"zoneSigned,omitempty"`
+	DelegationSigned *bool     `json:"delegationSigned,omitempty"`
+	MaxSigLife       int       `json:"maxSigLife,omitempty"`
+	DSData           []DSData  `json:"dsData,omitempty"`
+	KeyData          []KeyData `json:"keyData,omitempty"`
+}
+
+// DSData represents a DNSSEC delegation signer record
+type DSData struct {
+	KeyTag     int     `json:"keyTag"`
+	Algorithm  int     `json:"algorithm"`
+	Digest     string  `json:"digest"`
+	DigestType int     `json:"digestType"`
+	Events     []Event `json:"events,omitempty"`
+	Links      []Link  `json:"links,omitempty"`
+}
+
+// KeyData represents a DNSSEC DNSKEY record
+type KeyData struct {
+	Flags     int     `json:"flags"`
+	Protocol  int     `json:"protocol"`
+	PublicKey string  `json:"publicKey"`
+	Algorithm int     `json:"algorithm"`
+	Events    []Event `json:"events,omitempty"`
+	Links     []Link  `json:"links,omitempty"`
+}
+
+// IsSigned reports whether the delegation is DNSSEC signed, either explicitly
+// through delegationSigned or implicitly by listing DS or key records
+func (s *SecureDNS) IsSigned() bool {
+	if s == nil {
+		return false
+	}
+	if s.DelegationSigned != nil {
+		return *s.DelegationSigned
+	}
+	return len(s.DSData) > 0 || len(s.KeyData) > 0
+}
+
+// Variant represents an IDN variant of a domain
+type Variant struct {
+	Relation     []string      `json:"relation,omitempty"`
+	IDNTable     string        `json:"idnTable,omitempty"`
+	VariantNames []VariantName `json:"variantNames,omitempty"`
+}
+
+// VariantName represents a single variant name
+type VariantName struct {
+	LDHName     string `json:"ldhName,omitempty"`
+	UnicodeName string `json:"unicodeName,omitempty"`
+}
+
+// Event represents an RDAP event
+type Event struct {
+	EventAction string `json:"eventAction"`
+	EventActor  string `json:"eventActor,omitempty"`
+	EventDate   string `json:"eventDate,omitempty"`
+	Links       []Link `json:"links,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Link represents an RDAP link
+type Link struct {
+	Value    string  `json:"value,omitempty"`
+	Rel      string  `json:"rel,omitempty"`
+	Href     string  `json:"href"`
+	HrefLang Strings `json:"hreflang,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Media    string  `json:"media,omitempty"`
+	Type     string  `json:"type,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Notice represents an RDAP notice
+type Notice struct {
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Description []string `json:"description,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Remark represents an RDAP remark, which shares the structure of a notice
+type Remark = Notice
+
+// PublicID represents an RDAP public identifier
+type PublicID struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Error represents an RDAP error response
+type Error struct {
+	ErrorCode   int         `json:"errorCode"`
+	Title       string      `json:"title,omitempty"`
+	Description []string    `json:"description,omitempty"`
+	Conformance Conformance `json:"rdapConformance,omitempty"`
+	Notices     []Notice    `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Help represents an RDAP help response
+type Help struct {
+	Conformance Conformance `json:"rdapConformance,omitempty"`
+	Notices     []Notice    `json:"notices,omitempty"`
+
+	// Extra holds the members not declared above, keyed by member name
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a domain, preserving unknown members
+func (d *Domain) UnmarshalJSON(data []byte) error {
+	type domain Domain
+	return unmarshalWithExtra(data, (*domain)(d), &d.Extra)
+}
+
+// MarshalJSON encodes a domain, including preserved unknown members
+func (d Domain) MarshalJSON() ([]byte, error) {
+	type domain Domain
+	return marshalWithExtra(domain(d), d.Extra)
+}
+
+// UnmarshalJSON decodes an entity, preserving unknown members
+func (e *Entity) UnmarshalJSON(data []byte) error {
+	type entity Entity
+	return unmarshalWithExtra(data, (*entity)(e), &e.Extra)
+}
+
+// MarshalJSON encodes an entity, including preserved unknown members
+func (e Entity) MarshalJSON() ([]byte, error) {
+	type entity Entity
+	return marshalWithExtra(entity(e), e.Extra)
+}
+
+// UnmarshalJSON decodes a nameserver, preserving unknown members
+func (n *Nameserver) UnmarshalJSON(data []byte) error {
+	type nameserver Nameserver
+	return unmarshalWithExtra(data, (*nameserver)(n), &n.Extra)
+}
+
+// MarshalJSON encodes a nameserver, including preserved unknown members
+func (n Nameserver) MarshalJSON() ([]byte, error) {
+	type nameserver Nameserver
+	return marshalWithExtra(nameserver(n), n.Extra)
+}
+
+// UnmarshalJSON decodes an IP network, preserving unknown members
+func (n *IPNetwork) UnmarshalJSON(data []byte) error {
+	type ipNetwork IPNetwork
+	return unmarshalWithExtra(data, (*ipNetwork)(n), &n.Extra)
+}
+
+// MarshalJSON encodes an IP network, including preserved unknown members
+func (n IPNetwork) MarshalJSON() ([]byte, error) {
+	type ipNetwork IPNetwork
+	return marshalWithExtra(ipNetwork(n), n.Extra)
+}
+
+// UnmarshalJSON decodes an autnum, preserving unknown members
+func (a *Autnum) UnmarshalJSON(data []byte) error {
+	type autnum Autnum
+	return unmarshalWithExtra(data, (*autnum)(a), &a.Extra)
+}
+
+// MarshalJSON encodes an autnum, including preserved unknown members
+func (a Autnum) MarshalJSON() ([]byte, error) {
+	type autnum Autnum
+	return marshalWithExtra(autnum(a), a.Extra)
+}
+
+// UnmarshalJSON decodes an event, preserving unknown members
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	return unmarshalWithExtra(data, (*event)(e), &e.Extra)
+}
+
+// MarshalJSON encodes an event, including preserved unknown members
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	return marshalWithExtra(event(e), e.Extra)
+}
+
+// UnmarshalJSON decodes a link, preserving unknown members
+func (l *Link) UnmarshalJSON(data []byte) error {
+	type link Link
+	return unmarshalWithExtra(data, (*link)(l), &l.Extra)
+}
+
+// MarshalJSON encodes a link, including preserved unknown members
+func (l Link) MarshalJSON() ([]byte, error) {
+	type link Link
+	return marshalWithExtra(link(l), l.Extra)
+}
+
+// UnmarshalJSON decodes a notice, preserving unknown members
+func (n *Notice) UnmarshalJSON(data []byte) error {
+	type notice Notice
+	return unmarshalWithExtra(data, (*notice)(n), &n.Extra)
+}
+
+// MarshalJSON encodes a notice, including preserved unknown members
+func (n Notice) MarshalJSON() ([]byte, error) {
+	type notice Notice
+	return marshalWithExtra(notice(n), n.Extra)
+}
+
+// UnmarshalJSON decodes a public ID, preserving unknown members
+func (p *PublicID) UnmarshalJSON(data []byte) error {
+	type publicID PublicID
+	return unmarshalWithExtra(data, (*publicID)(p), &p.Extra)
+}
+
+// MarshalJSON encodes a public ID, including preserved unknown members
+func (p PublicID) MarshalJSON() ([]byte, error) {
+	type publicID PublicID
+	return marshalWithExtra(publicID(p), p.Extra)
+}
+
+// UnmarshalJSON decodes an error response, preserving unknown members
+func (e *Error) UnmarshalJSON(data []byte) error {
+	type rdapError Error
+	return unmarshalWithExtra(data, (*rdapError)(e), &e.Extra)
+}
+
+// MarshalJSON encodes an error response, including preserved unknown members
+func (e Error) MarshalJSON() ([]byte, error) {
+	type rdapError Error
+	return marshalWithExtra(rdapError(e), e.Extra)
+}
+
+// UnmarshalJSON decodes a help response, preserving unknown members
+func (h *Help) UnmarshalJSON(data []byte) error {
+	type help Help
+	return unmarshalWithExtra(data, (*help)(h), &h.Extra)
+}
+
+// MarshalJSON encodes a help response, including preserved unknown members
+func (h Help) MarshalJSON() ([]byte, error) {
+	type help Help
+	return marshalWithExtra(help(h), h.Extra)
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const sampleDomain = `{
+	"objectClassName": "domain",
+	"handle": "2336799_DOMAIN_COM-VRSN",
+	"ldhName": "EXAMPLE.COM",
+	"status": ["client delete prohibited"],
+	"events": [
+		{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"}
+	],
+	"links": [
+		{"value": "https://rdap.verisign.com/com/v1/domain/EXAMPLE.COM", "rel": "self", "href": "https://rdap.verisign.com/com/v1/domain/EXAMPLE.COM", "hreflang": "en", "x_linkExtra": true}
+	],
+	"entities": [
+		{
+			"objectClassName": "entity",
+			"handle": "376",
+			"roles": ["registrar"],
+			"publicIds": [{"type": "IANA Registrar ID", "identifier": "376"}]
+		}
+	],
+	"secureDNS": {
+		"delegationSigned": true,
+		"dsData": [{"keyTag": 370, "algorithm": 13, "digestType": 2, "digest": "BE74359954660069D5C63D200C39F5603827D7DD02B56F120EE9F3A86764247C"}]
+	},
+	"x_registryExtension": {"flag": true},
+	"rdapConformance": ["rdap_level_0", "icann_rdap_technical_implementation_guide_1"]
+}`
+
+func TestDomainUnmarshal(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	if domain.LDHName != "EXAMPLE.COM" {
+		t.Errorf("Expected ldhName EXAMPLE.COM, got %s", domain.LDHName)
+	}
+	if len(domain.Events) != 1 || domain.Events[0].EventAction != "registration" {
+		t.Errorf("Unexpected events: %+v", domain.Events)
+	}
+	if len(domain.Entities) != 1 || domain.Entities[0].PublicIDs[0].Identifier != "376" {
+		t.Errorf("Unexpected entities: %+v", domain.Entities)
+	}
+	if len(domain.Links) != 1 || len(domain.Links[0].HrefLang) != 1 || domain.Links[0].HrefLang[0] != "en" {
+		t.Errorf("Expected single hreflang to decode into a list, got %+v", domain.Links)
+	}
+	if len(domain.Conformance) != 2 {
+		t.Errorf("Expected 2 conformance values, got %v", domain.Conformance)
+	}
+}
+
+func TestUnknownFieldsPreserved(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	if _, ok := domain.Extra["x_registryExtension"]; !ok {
+		t.Errorf("Expected unknown member to be preserved in Extra, got %v", domain.Extra)
+	}
+	if _, ok := domain.Extra["ldhName"]; ok {
+		t.Error("Declared members must not be duplicated in Extra")
+	}
+	if _, ok := domain.Links[0].Extra["x_linkExtra"]; !ok {
+		t.Error("Expected unknown link member to be preserved")
+	}
+	if domain.Entities[0].Extra != nil {
+		t.Errorf("Expected no extra members on entity, got %v", domain.Entities[0].Extra)
+	}
+
+	data, err := json.Marshal(domain)
+	if err != nil {
+		t.Fatalf("Failed to marshal domain: %v", err)
+	}
+	if !strings.Contains(string(data), `"x_registryExtension":{"flag":true}`) {
+		t.Errorf("Expected unknown member in encoded output, got %s", data)
+	}
+	if !strings.Contains(string(data), `"x_linkExtra":true`) {
+		t.Errorf("Expected nested unknown member in encoded output, got %s", data)
+	}
+}
+
+func TestStringsUnmarshal(t *testing.T) {
+	var list Strings
+	if err := json.Unmarshal([]byte(`["en", "fr"]`), &list); err != nil {
+		t.Fatalf("Failed to unmarshal string list: %v", err)
+	}
+	if len(list) != 2 || list[1] != "fr" {
+		t.Errorf("Unexpected list: %v", list)
+	}
+
+	if err := json.Unmarshal([]byte(`42`), &list); err == nil {
+		t.Error("Expected error for non-string value")
+	}
+}
+
+func TestErrorUnmarshal(t *testing.T) {
+	var rdapError Error
+	data := `{"errorCode": 404, "title": "Not Found", "description": ["The domain was not found"]}`
+	if err := json.Unmarshal([]byte(data), &rdapError); err != nil {
+		t.Fatalf("Failed to unmarshal error: %v", err)
+	}
+	if rdapError.ErrorCode != 404 || rdapError.Title != "Not Found" {
+		t.Errorf("Unexpected error object: %+v", rdapError)
+	}
+}
+
+func TestSecureDNS(t *testing.T) {
+	var domain Domain
+	if err := json.Unmarshal([]byte(sampleDomain), &domain); err != nil {
+		t.Fatalf("Failed to unmarshal domain: %v", err)
+	}
+
+	if domain.SecureDNS == nil || len(domain.SecureDNS.DSData) != 1 {
+		t.Fatalf("Expected secureDNS with one DS record, got %+v", domain.SecureDNS)
+	}
+	if ds := domain.SecureDNS.DSData[0]; ds.KeyTag != 370 || ds.Algorithm != 13 || ds.DigestType != 2 {
+		t.Errorf("Unexpected DS record: %+v", ds)
+	}
+	if !domain.SecureDNS.IsSigned() {
+		t.Error("Expected delegation to be signed")
+	}
+
+	unsigned := false
+	tests := []struct {
+		secureDNS *SecureDNS
+		expected  bool
+	}{
+		{nil, false},
+		{&SecureDNS{}, false},
+		{&SecureDNS{DelegationSigned: &unsigned, DSData: []DSData{{KeyTag: 1}}}, false},
+		{&SecureDNS{KeyData: []KeyData{{Flags: 257}}}, true},
+	}
+	for _, test := range tests {
+		if result := test.secureDNS.IsSigned(); result != test.expected {
+			t.Errorf("IsSigned(%+v) = %v, expected %v", test.secureDNS, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Contact holds the contact details parsed from an entity's jCard (RFC 7095)
+type Contact struct {
+	Kind         string
+	Name         string
+	Organization string
+	Title        string
+	Role         string
+	Emails       []string
+	Phones       []Phone
+	Addresses    []Address
+	URLs         []string
+	Language     string
+}
+
+// Phone represents a telephone number with its vCard types such as "voice" or "fax"
+type Phone struct {
+	Number string
+	Types  []string
+}
+
+// Address represents a structured vCard ADR property
+type Address struct {
+	Types       []string
+	POBox       string
+	Extended    string
+	Street      []string
+	Locality    string
+	Region      string
+	PostalCode  string
+	Country     string
+	CountryCode string
+	// Label is the formatted address, set by registries that only provide text
+	Label string
+}
+
+// Contact parses the entity's vcardArray into a Contact, or returns nil when
+// the entity has no jCard
+func (e *Entity) Contact() (*Contact, error) {
+	if len(e.VCardArray) == 0 {
+		return nil, nil
+	}
+	return ParseVCard(e.VCardArray)
+}
+
+// ParseVCard parses a jCard array of the form ["vcard", [properties...]]
+func ParseVCard(vcardArray []interface{}) (*Contact, error) {
+	if len(vcardArray) != 2 {
+		return nil, fmt.Errorf("invalid jCard: expected 2 elements, got %d", len(vcardArray))
+	}
+	if tag, _ := vcardArray[0].(string); tag != "vcard" {
+		return nil, fmt.Errorf("invalid jCard: missing vcard tag")
+	}
+	properties, ok := vcardArray[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid jCard: properties must be an array")
+	}
+
+	contact := &Contact{}
+	for _, item := range properties {
+		property, ok := item.([]interface{})
+		if !ok || len(property) < 4 {
+			continue
+		}
+		name, _ := property[0].(string)
+		params, _ := property[1].(map[string]interface{})
+		values := property[3:]
+
+		switch strings.ToLower(name) {
+		case "kind":
+			contact.Kind = jCardText(values)
+		case "fn":
+			contact.Name = jCardText(values)
+		case "org":
+			contact.Organization = jCardText(values)
+		case "title":
+			contact.Title = jCardText(values)
+		case "role":
+			contact.Role = jCardText(values)
+		case "email":
+			if email := jCardText(values); email != "" {
+				contact.Emails = append(contact.Emails, email)
+			}
+		case "tel":
+			number := strings.TrimPrefix(jCardText(values), "tel:")
+			if number != "" {
+				contact.Phones = append(contact.Phones, Phone{
+					Number: number,
+					Types:  jCardParam(params, "type"),
+				})
+			}
+		case "adr":
+			contact.Addresses = append(contact.Addresses, parseAddress(params, values))
+		case "url":
+			if url := jCardText(values); url != "" {
+				contact.URLs = append(contact.URLs, url)
+			}
+		case "lang":
+			if contact.Language == "" {
+				contact.Language = jCardText(values)
+			}
+		}
+	}
+
+	return contact, nil
+}
+
+// parseAddress parses the seven structured components of an ADR value
+func parseAddress(params map[string]interface{}, values []interface{}) Address {
+	address := Address{
+		Types:       jCardParam(params, "type"),
+		CountryCode: strings.Join(jCardParam(params, "cc"), ""),
+		Label:       strings.Join(jCardParam(params, "label"), "\n"),
+	}
+
+	if len(values) == 0 {
+		return address
+	}
+	components, ok := values[0].([]interface{})
+	if !ok {
+		return address
+	}
+
+	component := func(i int) []string {
+		if i >= len(components) {
+			return nil
+		}
+		return jCardStrings(components[i])
+	}
+	address.POBox = strings.Join(component(0), " ")
+	address.Extended = strings.Join(component(1), " ")
+	address.Street = component(2)
+	address.Locality = strings.Join(component(3), " ")
+	address.Region = strings.Join(component(4), " ")
+	address.PostalCode = strings.Join(component(5), " ")
+	address.Country = strings.Join(component(6), " ")
+
+	return address
+}
+
+// jCardText joins a property value, which may be a single string or a
+// structured list such as an ORG with units
+func jCardText(values []interface{}) string {
+	var parts []string
+	for _, value := range values {
+		parts = append(parts, jCardStrings(value)...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// jCardParam returns a property parameter, which may be a string or a list of strings
+func jCardParam(params map[string]interface{}, name string) []string {
+	for key, value := range params {
+		if strings.EqualFold(key, name) {
+			return jCardStrings(value)
+		}
+	}
+	return nil
+}
+
+// jCardStrings flattens a jCard value into its non-empty strings
+func jCardStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, jCardStrings(item)...)
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const sampleEntity = `{
+	"objectClassName": "entity",
+	"handle": "XXXX",
+	"roles": ["registrant"],
+	"vcardArray": [
+		"vcard",
+		[
+			["version", {}, "text", "4.0"],
+			["kind", {}, "text", "individual"],
+			["fn", {}, "text", "Joe User"],
+			["org", {"type": "work"}, "text", ["Example Inc.", "Marketing"]],
+			["lang", {"pref": "1"}, "language-tag", "fr"],
+			["adr", {"type": "work", "cc": "CA"}, "text",
+				["", "Suite 1234", ["4321 Rue Somewhere", "Building 2"], "Quebec", "QC", "G1V 2M2", "Canada"]
+			],
+			["adr", {"label": "123 Maple Ave\nSuite 90001\nVancouver\nBC\n1239\n"}, "text",
+				["", "", "", "", "", "", ""]
+			],
+			["tel", {"type": ["work", "voice"], "pref": "1"}, "uri", "tel:+1-555-555-1234;ext=102"],
+			["tel", {"type": "work"}, "uri", "tel:+1-555-555-4321"],
+			["email", {"type": "work"}, "text", "joe.user@example.com"],
+			["url", {"type": "home"}, "uri", "https://example.org"]
+		]
+	]
+}`
+
+func TestEntityContact(t *testing.T) {
+	var entity Entity
+	if err := json.Unmarshal([]byte(sampleEntity), &entity); err != nil {
+		t.Fatalf("Failed to unmarshal entity: %v", err)
+	}
+
+	contact, err := entity.Contact()
+	if err != nil {
+		t.Fatalf("Failed to parse contact: %v", err)
+	}
+
+	if contact.Kind != "individual" || contact.Name != "Joe User" {
+		t.Errorf("Unexpected kind/name: %s/%s", contact.Kind, contact.Name)
+	}
+	if contact.Organization != "Example Inc. Marketing" {
+		t.Errorf("Expected organization with unit, got %q", contact.Organization)
+	}
+	if contact.Language != "fr" {
+		t.Errorf("Expected language fr, got %s", contact.Language)
+	}
+	if len(contact.Emails) != 1 || contact.Emails[0] != "joe.user@example.com" {
+		t.Errorf("Unexpected emails: %v", contact.Emails)
+	}
+	if len(contact.URLs) != 1 || contact.URLs[0] != "https://example.org" {
+		t.Errorf("Unexpected URLs: %v", contact.URLs)
+	}
+
+	if len(contact.Phones) != 2 {
+		t.Fatalf("Expected 2 phones, got %d", len(contact.Phones))
+	}
+	if contact.Phones[0].Number != "+1-555-555-1234;ext=102" {
+		t.Errorf("Expected tel: prefix to be stripped, got %s", contact.Phones[0].Number)
+	}
+	if len(contact.Phones[0].Types) != 2 || contact.Phones[0].Types[1] != "voice" {
+		t.Errorf("Unexpected phone types: %v", contact.Phones[0].Types)
+	}
+
+	if len(contact.Addresses) != 2 {
+		t.Fatalf("Expected 2 addresses, got %d", len(contact.Addresses))
+	}
+	adr := contact.Addresses[0]
+	if adr.Extended != "Suite 1234" || adr.Locality != "Quebec" || adr.Region != "QC" ||
+		adr.PostalCode != "G1V 2M2" || adr.Country != "Canada" || adr.CountryCode != "CA" {
+		t.Errorf("Unexpected structured address: %+v", adr)
+	}
+	if len(adr.Street) != 2 || adr.Street[1] != "Building 2" {
+		t.Errorf("Expected multi-line street, got %v", adr.Street)
+	}
+	if contact.Addresses[1].Label == "" || contact.Addresses[1].Locality != "" {
+		t.Errorf("Expected label-only address, got %+v", contact.Addresses[1])
+	}
+}
+
+func TestEntityContactMissingVCard(t *testing.T) {
+	entity := Entity{ObjectClassName: "entity"}
+	contact, err := entity.Contact()
+	if err != nil || contact != nil {
+		t.Errorf("Expected nil contact without error, got %+v, %v", contact, err)
+	}
+}
+
+func TestParseVCardInvalid(t *testing.T) {
+	tests := [][]interface{}{
+		{"vcard"},
+		{"notvcard", []interface{}{}},
+		{"vcard", "properties"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseVCard(test); err == nil {
+			t.Errorf("Expected error for invalid jCard %v", test)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SetVerifyDomainName sets whether domain responses must name the queried
+// domain, to catch misrouted answers or wrong cached copies from
+// misconfigured registries. When enabled, a response whose ldhName or
+// unicodeName normalizes to another domain fails with ErrDomainMismatch and
+// is not cached, and a cached copy failing the check is dropped. Responses
+// naming no domain are accepted.
+func (c *Client) SetVerifyDomainName(enabled bool) *Client {
+	c.verifyDomainName = enabled
+	return c
+}
+
+// verifyDomainName checks that a domain response names the queried
+// normalized domain. Bodies that don't parse are left to the callers.
+func verifyDomainName(domain string, body []byte) error {
+	var names struct {
+		LDHName     string `json:"ldhName"`
+		UnicodeName string `json:"unicodeName"`
+	}
+	if json.Unmarshal(body, &names) != nil {
+		return nil
+	}
+
+	for _, name := range []string{names.LDHName, names.UnicodeName} {
+		if name != "" && normalizeDomain(name) != domain {
+			return fmt.Errorf("%w: queried %s, got %s", ErrDomainMismatch, domain, name)
+		}
+	}
+	return nil
+}
+
+// verifyCachedDomainName checks a cached domain response, dropping it from the cache when it fails
+func (c *Client) verifyCachedDomainName(key, domain string, resp *Response) error {
+	err := verifyDomainName(domain, resp.Body)
+	if err != nil {
+		if cache := c.responseCache(); cache != nil {
+			cache.Delete(key)
+		}
+	}
+	return err
+}
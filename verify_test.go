@@ -0,0 +1,65 @@
+package rdap
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestVerifyDomainName(t *testing.T) {
+	tests := []struct {
+		body string
+		ok   bool
+	}{
+		{`{"objectClassName": "domain", "ldhName": "EXAMPLE.COM."}`, true},
+		{`{"objectClassName": "domain", "ldhName": "xn--bcher-kva.com", "unicodeName": "bücher.com"}`, false},
+		{`{"objectClassName": "domain", "ldhName": "other.com"}`, false},
+		{`{"objectClassName": "domain", "ldhName": "example.com", "unicodeName": "other.com"}`, false},
+		{`{"objectClassName": "domain"}`, true},
+		{`not json`, true},
+	}
+	for _, test := range tests {
+		err := verifyDomainName("example.com", []byte(test.body))
+		if (err == nil) != test.ok {
+			t.Errorf("verifyDomainName(%s) = %v, expected ok %v", test.body, err, test.ok)
+		}
+		if err != nil && !errors.Is(err, ErrDomainMismatch) {
+			t.Errorf("Expected ErrDomainMismatch, got %v", err)
+		}
+	}
+
+	if err := verifyDomainName("xn--bcher-kva.com", []byte(`{"ldhName": "XN--BCHER-KVA.COM", "unicodeName": "Bücher.com"}`)); err != nil {
+		t.Errorf("Expected the IDN to match, got %v", err)
+	}
+}
+
+func TestSetVerifyDomainName(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain", "ldhName": "other.com"}`))
+	})
+
+	// Disabled by default, the misrouted answer is cached
+	if _, err := client.RDAP("example.com"); err != nil {
+		t.Fatalf("Expected no check by default, got %v", err)
+	}
+
+	// Enabled, the wrong cached copy is caught and dropped
+	client.SetVerifyDomainName(true)
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("Expected ErrDomainMismatch from the cached copy, got %v", err)
+	}
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("Expected ErrDomainMismatch, got %v", err)
+	}
+	if queries.Load() != 2 {
+		t.Errorf("Expected the cached copy to be dropped, got %d queries", queries.Load())
+	}
+
+	// Mismatching answers are not cached
+	if _, err := client.RDAP("example.com"); !errors.Is(err, ErrDomainMismatch) || queries.Load() != 3 {
+		t.Errorf("Expected another query, got %d queries and %v", queries.Load(), err)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Warm fetches the given domains from their registries and stores the
+// responses in the cache, replacing cached copies, with at most concurrency
+// queries in flight. Like any lookup, the queries are paced by the client's
+// rate limiter and held to its SetMaxConcurrent limit. Not found answers are
+// cached as such; other failures are returned joined once every domain was
+// tried. Cancelling ctx stops starting new queries and aborts those in
+// flight, keeping the cached copies of their domains.
+func (c *Client) Warm(ctx context.Context, domains []string, concurrency int) error {
+	cache := c.responseCache()
+	if cache == nil {
+		return fmt.Errorf("cannot warm cache: response caching is disabled")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	slots := make(chan struct{}, concurrency)
+	seen := make(map[string]bool, len(domains))
+
+	for _, domain := range domains {
+		name := c.registrableDomain(normalizeDomain(domain))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if ctx.Err() == nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			wg.Wait()
+			return errors.Join(append(errs, err)...)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := c.warmDomain(ctx, cache, name); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to warm %s: %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// warmDomain queries a normalized domain and replaces its cached response.
+// The query is bound to ctx, so like other bounded lookups it is not shared.
+func (c *Client) warmDomain(ctx context.Context, cache Cache, domain string) error {
+	key := domainCacheKey(domain)
+	resp, err := c.queryDomain(ctx, domain, newRequestOptions(nil))
+	if errors.Is(err, ErrNotFound) {
+		cache.Delete(key)
+		c.storeResponse(key, resp, err)
+		return nil
+	}
+	if err == nil {
+		cache.Delete(notFoundKeyPrefix + key)
+		c.storeResponse(key, resp, err)
+	}
+	return err
+}
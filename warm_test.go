@@ -0,0 +1,152 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarm(t *testing.T) {
+	var queries, active, peak atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if n := active.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer active.Add(-1)
+		time.Sleep(10 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/domain/missing.com":
+			http.NotFound(w, r)
+		case "/domain/broken.com":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"objectClassName": "domain"}`))
+		}
+	})
+
+	domains := []string{"a.com", "b.com", "c.com", "A.com.", "missing.com", "broken.com"}
+	err := client.Warm(context.Background(), domains, 2)
+	if err == nil || !strings.Contains(err.Error(), "failed to warm broken.com") {
+		t.Errorf("Expected broken.com failure, got %v", err)
+	}
+	if got := queries.Load(); got != 5 {
+		t.Errorf("Expected 5 queries for the unique domains, got %d", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 concurrent queries, got %d", got)
+	}
+
+	for _, domain := range []string{"a.com", "b.com", "c.com"} {
+		if _, err := client.RDAP(domain); err != nil {
+			t.Fatalf("RDAP(%s) failed: %v", domain, err)
+		}
+	}
+	if _, err := client.RDAP("missing.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected cached not found, got %v", err)
+	}
+	if got := queries.Load(); got != 5 {
+		t.Errorf("Expected warmed lookups to be served from the cache, got %d queries", got)
+	}
+
+	// Warming again refreshes the cached copies
+	if err := client.Warm(context.Background(), []string{"a.com"}, 1); err != nil {
+		t.Fatalf("Warm failed: %v", err)
+	}
+	if got := queries.Load(); got != 6 {
+		t.Errorf("Expected Warm to fetch cached domains again, got %d queries", got)
+	}
+}
+
+func TestWarmCancelled(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.Warm(ctx, []string{"a.com", "b.com", "c.com"}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if got := queries.Load(); got != 0 {
+		t.Errorf("Expected no new queries after cancellation, got %d", got)
+	}
+}
+
+func TestWarmRateLimited(t *testing.T) {
+	var queries atomic.Int32
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+	// One query goes through, the next would wait for over 15 minutes
+	client.SetRateLimiter(NewHostRateLimiter(0.001, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := client.Warm(ctx, []string{"a.com", "b.com"}, 2)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the rate limited query to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Warm to return once ctx expired, took %v", elapsed)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("Expected 1 query within the rate limit, got %d", got)
+	}
+}
+
+func TestWarmCancelledInFlight(t *testing.T) {
+	var slow atomic.Bool
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"objectClassName": "domain"}`))
+	})
+	if _, err := client.RDAP("a.com"); err != nil {
+		t.Fatalf("RDAP failed: %v", err)
+	}
+
+	slow.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := client.Warm(ctx, []string{"a.com"}, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the in-flight query to be aborted, got %v", err)
+	}
+
+	// The aborted refresh keeps the cached copy
+	resp, err := client.RDAPResponse("a.com")
+	if err != nil || !resp.Cached {
+		t.Errorf("Expected the cached copy to be kept, got %+v, %v", resp, err)
+	}
+}
+
+func TestWarmCacheDisabled(t *testing.T) {
+	if err := NewClient().SetDisableCache(true).Warm(context.Background(), []string{"a.com"}, 1); err == nil {
+		t.Error("Expected error when caching is disabled")
+	}
+}
+
+func TestWarmEmptyAnswer(t *testing.T) {
+	client := newMockDomainClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"rdapConformance": ["rdap_level_0"], "notices": []}`))
+	})
+
+	if err := client.Warm(context.Background(), []string{"missing.com"}, 1); err != nil {
+		t.Fatalf("Expected an empty answer to warm as not found, got %v", err)
+	}
+	if _, err := client.RDAP("missing.com"); !errors.Is(err, ErrDomainNotFound) {
+		t.Errorf("Expected ErrDomainNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+package whois
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+	"github.com/ducksify/gordap/types"
+)
+
+// Source is the source recorded in the responses of the WHOIS fallback step
+const Source = "whois"
+
+// Record is a WHOIS response with the fields parsed from it. Fields are
+// matched on the labels most registries use; formats vary widely, so any of
+// them may be missing even when the raw text holds the information.
+type Record struct {
+	Domain string
+	// Server is the WHOIS server that answered
+	Server string
+	// Raw is the WHOIS response as received
+	Raw         string
+	Registrar   string
+	Created     time.Time
+	Updated     time.Time
+	Expires     time.Time
+	Nameservers []string
+	Status      []string
+	// NotFound reports whether the response says the domain is not registered
+	NotFound bool
+}
+
+// Labels of the parsed fields, lowercase
+var (
+	registrarLabels  = []string{"registrar", "registrar name", "sponsoring registrar", "registrar organization"}
+	createdLabels    = []string{"creation date", "created", "created on", "created date", "registered", "registered on", "registration date", "registration time", "domain registration date"}
+	updatedLabels    = []string{"updated date", "updated", "last updated", "last modified", "last-update", "changed", "modified"}
+	expiresLabels    = []string{"registry expiry date", "expiration date", "expiry date", "expires", "expires on", "expire", "paid-till", "registrar registration expiration date", "renewal date"}
+	nameserverLabels = []string{"name server", "name servers", "nserver", "nameserver", "nameservers"}
+	statusLabels     = []string{"domain status", "status", "state"}
+)
+
+// notFoundMarkers are the phrases registries answer unregistered domains with, lowercase
+var notFoundMarkers = []string{
+	"no match for",
+	"not found",
+	"no entries found",
+	"no data found",
+	"no object found",
+	"status: free",
+	"status: available",
+	"is available for registration",
+	"is free",
+}
+
+// dateLayouts are the date formats found in WHOIS responses
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"2006.01.02",
+	"02.01.2006",
+	"2006/01/02",
+	"January 2 2006",
+}
+
+// Parse extracts the registration data of a domain from a raw WHOIS
+// response on a best-effort basis
+func Parse(domain, raw string) *Record {
+	record := &Record{Domain: domain, Raw: raw}
+	lower := strings.ToLower(raw)
+	record.NotFound = slices.ContainsFunc(notFoundMarkers, func(marker string) bool {
+		return strings.Contains(lower, marker)
+	})
+
+	// A label without a value may list its values on the following lines,
+	// up to a blank line
+	var pending string
+	for line := range strings.Lines(raw) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			pending = ""
+			continue
+		}
+		if line[0] == '%' || line[0] == '#' || strings.HasPrefix(line, ">>>") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(value, "//") {
+			if pending != "" {
+				record.set(pending, line)
+			}
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			pending = key
+			continue
+		}
+		pending = ""
+		record.set(key, value)
+	}
+	return record
+}
+
+// set records the value of a labeled field
+func (r *Record) set(key, value string) {
+	switch {
+	case slices.Contains(registrarLabels, key):
+		if r.Registrar == "" {
+			r.Registrar = value
+		}
+	case slices.Contains(createdLabels, key):
+		setDate(&r.Created, value)
+	case slices.Contains(updatedLabels, key):
+		setDate(&r.Updated, value)
+	case slices.Contains(expiresLabels, key):
+		setDate(&r.Expires, value)
+	case slices.Contains(nameserverLabels, key):
+		if fields := strings.Fields(value); len(fields) > 0 {
+			name := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+			if !slices.Contains(r.Nameservers, name) {
+				r.Nameservers = append(r.Nameservers, name)
+			}
+		}
+	case slices.Contains(statusLabels, key):
+		// EPP statuses are often followed by the URL explaining them
+		if fields := strings.Fields(value); len(fields) > 0 && !slices.Contains(r.Status, fields[0]) {
+			r.Status = append(r.Status, fields[0])
+		}
+	}
+}
+
+// setDate sets a date that is not set yet from a WHOIS value
+func setDate(date *time.Time, value string) {
+	if !date.IsZero() {
+		return
+	}
+	if t, ok := parseDate(value); ok {
+		*date = t
+	}
+}
+
+// parseDate parses a WHOIS date, trying the whole value then its first field
+func parseDate(value string) (time.Time, bool) {
+	candidates := []string{value}
+	if fields := strings.Fields(value); len(fields) > 1 {
+		candidates = append(candidates, fields[0])
+	}
+	for _, candidate := range candidates {
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, candidate); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Registered reports whether the record describes a registered domain
+func (r *Record) Registered() bool {
+	return !r.NotFound || r.Registrar != "" || !r.Created.IsZero() || len(r.Nameservers) > 0
+}
+
+// RDAP converts the record to an RDAP domain object. A notice labels the
+// object as converted from WHOIS, and a remark holds the raw response.
+func (r *Record) RDAP() *rdap.Domain {
+	domain := &rdap.Domain{
+		ObjectClassName: "domain",
+		LDHName:         r.Domain,
+		Port43:          r.Server,
+		Notices: []rdap.Notice{{
+			Title:       "WHOIS fallback",
+			Description: []string{"This object was converted on a best-effort basis from the WHOIS response of " + r.Server + ", as the registry has no RDAP service."},
+		}},
+		Remarks: []rdap.Remark{{
+			Title:       "WHOIS response",
+			Description: strings.Split(strings.TrimRight(r.Raw, "\r\n"), "\n"),
+		}},
+	}
+
+	if unicode, err := rdap.ToUnicode(r.Domain); err == nil && unicode != r.Domain {
+		domain.UnicodeName = unicode
+	}
+	for _, name := range r.Nameservers {
+		domain.Nameservers = append(domain.Nameservers, rdap.Nameserver{ObjectClassName: "nameserver", LDHName: name})
+	}
+	for _, status := range r.Status {
+		domain.Status = append(domain.Status, rdap.Status(status))
+	}
+	for _, event := range []struct {
+		action string
+		date   time.Time
+	}{
+		{types.EventRegistration, r.Created},
+		{types.EventLastChanged, r.Updated},
+		{types.EventExpiration, r.Expires},
+	} {
+		if !event.date.IsZero() {
+			domain.Events = append(domain.Events, rdap.Event{EventAction: event.action, EventDate: event.date.Format(time.RFC3339)})
+		}
+	}
+	if r.Registrar != "" {
+		domain.Entities = append(domain.Entities, rdap.Entity{
+			ObjectClassName: "entity",
+			Roles:           []string{types.RoleRegistrar},
+			VCardArray: []interface{}{"vcard", []interface{}{
+				[]interface{}{"version", map[string]interface{}{}, "text", "4.0"},
+				[]interface{}{"fn", map[string]interface{}{}, "text", r.Registrar},
+			}},
+		})
+	}
+	return domain
+}
+
+// MarshalRDAP returns the JSON encoding of the RDAP domain object of the record
+func (r *Record) MarshalRDAP() ([]byte, error) {
+	return json.Marshal(r.RDAP())
+}
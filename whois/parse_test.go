@@ -0,0 +1,84 @@
+package whois
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ducksify/gordap/types"
+)
+
+const sampleResponse = `Domain Name: EXAMPLE.TK
+Registrar: Example Registrar, Inc.
+Creation Date: 1995-08-14T04:00:00Z
+Registry Expiry Date: 2026-08-13T04:00:00Z
+Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited
+Name Server: A.IANA-SERVERS.NET.
+Name Server: B.IANA-SERVERS.NET
+
+Name servers:
+    a.iana-servers.net
+    c.iana-servers.net
+
+>>> Last update of WHOIS database: 2025-01-01T00:00:00Z <<<
+`
+
+func TestParse(t *testing.T) {
+	record := Parse("example.tk", sampleResponse)
+
+	if record.Registrar != "Example Registrar, Inc." {
+		t.Errorf("Unexpected registrar: %q", record.Registrar)
+	}
+	if !record.Created.Equal(time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC)) || record.Expires.Year() != 2026 {
+		t.Errorf("Unexpected dates: %v, %v", record.Created, record.Expires)
+	}
+	if len(record.Status) != 1 || record.Status[0] != "clientTransferProhibited" {
+		t.Errorf("Unexpected status: %v", record.Status)
+	}
+	want := []string{"a.iana-servers.net", "b.iana-servers.net", "c.iana-servers.net"}
+	if len(record.Nameservers) != len(want) {
+		t.Fatalf("Expected nameservers %v, got %v", want, record.Nameservers)
+	}
+	for i, name := range want {
+		if record.Nameservers[i] != name {
+			t.Errorf("Expected nameservers %v, got %v", want, record.Nameservers)
+		}
+	}
+	if !record.Registered() {
+		t.Error("Expected a registered domain")
+	}
+}
+
+func TestParseDateFormats(t *testing.T) {
+	for _, value := range []string{"2020-03-04", "04-Mar-2020", "04.03.2020", "2020-03-04 12:00:00", "2020-03-04T12:00:00Z (UTC)"} {
+		date, ok := parseDate(value)
+		if !ok || date.Year() != 2020 || date.Month() != time.March || date.Day() != 4 {
+			t.Errorf("Failed to parse %q: %v", value, date)
+		}
+	}
+}
+
+func TestParseNotFound(t *testing.T) {
+	if Parse("example.tk", "No match for \"EXAMPLE.TK\".\n").Registered() {
+		t.Error("Expected an unregistered domain")
+	}
+}
+
+func TestRecordRDAP(t *testing.T) {
+	record := Parse("example.tk", sampleResponse)
+	record.Server = "whois.example"
+	domain := record.RDAP()
+
+	if domain.LDHName != "example.tk" || domain.Port43 != "whois.example" || len(domain.Notices) != 1 {
+		t.Errorf("Unexpected domain: %+v", domain)
+	}
+	if expiration, err := domain.ExpirationDate(); err != nil || expiration.Year() != 2026 {
+		t.Errorf("Unexpected expiration: %v, %v", expiration, err)
+	}
+	registrar, ok := domain.Registrar()
+	if !ok || registrar.Name != "Example Registrar, Inc." {
+		t.Errorf("Unexpected registrar: %+v", registrar)
+	}
+	if !domain.Entities[0].HasRole(types.RoleRegistrar) || len(domain.Remarks[0].Description) == 0 {
+		t.Errorf("Unexpected entities or remarks: %+v", domain)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2024 François "@Ducksify"
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain RDAP information query
+ */
+
+// Package whois implements a WHOIS (port 43) fallback for the gordap client,
+// for TLDs whose registry has no RDAP service. Responses are returned as raw
+// text along with a best-effort parse, and converted to RDAP domain objects
+// labeled as such when used as a fallback step.
+package whois
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+)
+
+const (
+	// DefaultIANAServer is the WHOIS server of IANA, which refers TLD queries
+	// to the WHOIS server of the registry
+	DefaultIANAServer = "whois.iana.org"
+	// defaultPort is the WHOIS port
+	defaultPort = "43"
+	// defaultTimeout bounds a WHOIS query
+	defaultTimeout = 10 * time.Second
+	// maxResponseSize bounds the size of a WHOIS response
+	maxResponseSize = 1 << 20
+)
+
+// ErrNoServer is returned when no WHOIS server is known for a TLD
+var ErrNoServer = errors.New("no WHOIS server for TLD")
+
+// DialFunc dials a WHOIS server
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Client queries WHOIS servers, discovering the server of each TLD from IANA
+type Client struct {
+	ianaServer string
+	timeout    time.Duration
+	dial       DialFunc
+
+	mu      sync.Mutex
+	servers map[string]string
+}
+
+// New returns a WHOIS client discovering TLD servers from whois.iana.org
+func New() *Client {
+	dialer := &net.Dialer{}
+	return &Client{
+		ianaServer: DefaultIANAServer,
+		timeout:    defaultTimeout,
+		dial:       dialer.DialContext,
+		servers:    make(map[string]string),
+	}
+}
+
+// SetServer sets the WHOIS server of a TLD instead of asking IANA, as a host
+// name or a host:port address. An empty server removes it.
+func (c *Client) SetServer(tld, server string) *Client {
+	tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if server == "" {
+		delete(c.servers, tld)
+		return c
+	}
+	c.servers[tld] = server
+	return c
+}
+
+// SetIANAServer sets the WHOIS server asked for the server of a TLD
+func (c *Client) SetIANAServer(server string) *Client {
+	c.ianaServer = server
+	return c
+}
+
+// SetTimeout sets how long a WHOIS query may take (default 10 seconds)
+func (c *Client) SetTimeout(timeout time.Duration) *Client {
+	c.timeout = timeout
+	return c
+}
+
+// SetDialer sets the function dialing WHOIS servers, for example through a proxy
+func (c *Client) SetDialer(dial DialFunc) *Client {
+	c.dial = dial
+	return c
+}
+
+// Query sends a WHOIS query for the given domain to the server of its TLD
+// and returns the raw response with its best-effort parse
+func (c *Client) Query(ctx context.Context, domain string) (*Record, error) {
+	// Registries index IDNs by their A-label
+	if ascii, err := rdap.ToASCII(domain); err == nil {
+		domain = ascii
+	} else {
+		domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	}
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+	if tld == "" {
+		return nil, fmt.Errorf("invalid domain: %s", domain)
+	}
+
+	server, err := c.Server(ctx, tld)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.query(ctx, server, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query WHOIS server %s: %w", server, err)
+	}
+
+	record := Parse(domain, raw)
+	record.Server = server
+	return record, nil
+}
+
+// Server returns the WHOIS server of a TLD, asking IANA for it the first time
+func (c *Client) Server(ctx context.Context, tld string) (string, error) {
+	tld = strings.ToLower(strings.Trim(tld, "."))
+	c.mu.Lock()
+	server, ok := c.servers[tld]
+	c.mu.Unlock()
+	if ok {
+		return server, nil
+	}
+
+	raw, err := c.query(ctx, c.ianaServer, tld)
+	if err != nil {
+		return "", fmt.Errorf("failed to query WHOIS server %s: %w", c.ianaServer, err)
+	}
+	server = referral(raw)
+	if server == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoServer, tld)
+	}
+
+	c.mu.Lock()
+	c.servers[tld] = server
+	c.mu.Unlock()
+	return server, nil
+}
+
+// query sends a query to a WHOIS server and returns the raw response
+func (c *Client) query(ctx context.Context, server, query string) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	addr := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		addr = net.JoinHostPort(server, defaultPort)
+	}
+	conn, err := c.dial(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// Unblock the exchange when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if _, err := io.WriteString(conn, query+"\r\n"); err != nil {
+		return "", contextError(ctx, err)
+	}
+	body, err := io.ReadAll(io.LimitReader(conn, maxResponseSize))
+	if err != nil {
+		return "", contextError(ctx, err)
+	}
+	return string(body), nil
+}
+
+// contextError returns the error of a done context instead of the I/O error it caused
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// referral returns the WHOIS server an IANA response refers to
+func referral(raw string) string {
+	for line := range strings.Lines(raw) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "refer", "whois":
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// Fallback returns a fallback step answering domain queries over WHOIS, to
+// be set after the registry step with rdap.Client.SetFallbackChain. The
+// response body is the RDAP domain object converted from the WHOIS record,
+// and Response.Source is "whois". A WHOIS response reporting the domain as
+// not registered is returned as a 404 answer.
+func (c *Client) Fallback() rdap.FallbackStep {
+	return rdap.FallbackFunc(Source, c.lookup)
+}
+
+// lookup answers a domain query of a fallback chain
+func (c *Client) lookup(ctx context.Context, domain string) (*rdap.Response, error) {
+	start := time.Now()
+	record, err := c.Query(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := record.MarshalRDAP()
+	if err != nil {
+		return nil, err
+	}
+	resp := &rdap.Response{
+		Body:       body,
+		Server:     record.Server,
+		URL:        "whois://" + record.Server + "/" + record.Domain,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/rdap+json"}},
+		Duration:   time.Since(start),
+		Source:     Source,
+	}
+	if !record.Registered() {
+		resp.StatusCode = http.StatusNotFound
+		return resp, &rdap.StatusError{StatusCode: resp.StatusCode, Body: record.Raw}
+	}
+	return resp, nil
+}
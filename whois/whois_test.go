@@ -0,0 +1,155 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rdap "github.com/ducksify/gordap"
+)
+
+// newWhoisServer returns the address of a WHOIS server answering each query with the given responses
+func newWhoisServer(t *testing.T, responses map[string]string) (string, *atomic.Int32) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var queries atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				query, _ := bufio.NewReader(conn).ReadString('\n')
+				queries.Add(1)
+				conn.Write([]byte(responses[strings.TrimSpace(query)]))
+			}()
+		}
+	}()
+	return listener.Addr().String(), &queries
+}
+
+const denicResponse = `% Restricted rights.
+Domain: example.de
+Nserver: ns1.example.net
+Nserver: ns2.example.net
+Status: connect
+Changed: 2023-04-05T10:11:12+02:00
+`
+
+func TestQueryIANAReferral(t *testing.T) {
+	registry, _ := newWhoisServer(t, map[string]string{"example.de": denicResponse, "free.de": "Domain: free.de\nStatus: free\n"})
+	iana, ianaQueries := newWhoisServer(t, map[string]string{"de": "domain: DE\n\nwhois: " + registry + "\n"})
+	client := New().SetIANAServer(iana)
+
+	record, err := client.Query(context.Background(), "Example.DE.")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if record.Server != registry || record.Domain != "example.de" || record.Raw != denicResponse {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if len(record.Nameservers) != 2 || record.Status[0] != "connect" || record.Updated.IsZero() {
+		t.Errorf("Unexpected parse: %+v", record)
+	}
+
+	// The referral is remembered
+	record, err = client.Query(context.Background(), "free.de")
+	if err != nil || record.Registered() {
+		t.Errorf("Expected unregistered record, got %+v, %v", record, err)
+	}
+	if ianaQueries.Load() != 1 {
+		t.Errorf("Expected 1 IANA query, got %d", ianaQueries.Load())
+	}
+}
+
+func TestQueryNoServer(t *testing.T) {
+	iana, _ := newWhoisServer(t, map[string]string{"example": "domain: EXAMPLE\n"})
+	client := New().SetIANAServer(iana)
+
+	if _, err := client.Query(context.Background(), "test.example"); !errors.Is(err, ErrNoServer) {
+		t.Errorf("Expected ErrNoServer, got %v", err)
+	}
+}
+
+func TestQueryCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		// Accept without ever answering
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := New().SetServer("de", listener.Addr().String())
+	if _, err := client.Query(ctx, "example.de"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	registry, queries := newWhoisServer(t, map[string]string{"example.de": denicResponse, "free.de": "Status: free\n"})
+	whois := New().SetServer("de", registry)
+	client := rdap.NewClient().
+		SetBootstrapData([]byte(`{"services": [[["com"], ["https://rdap.example/"]]]}`)).
+		SetFallbackChain("", rdap.FallbackRegistry(), whois.Fallback())
+
+	resp, err := client.RDAPResponse("example.de")
+	if err != nil {
+		t.Fatalf("Expected WHOIS fallback, got: %v", err)
+	}
+	if resp.Source != Source || resp.Server != registry {
+		t.Errorf("Unexpected response from %q at %s", resp.Source, resp.Server)
+	}
+
+	domain, err := client.Domain("example.de")
+	if err != nil {
+		t.Fatalf("Failed to parse the converted domain: %v", err)
+	}
+	if names := domain.NameserverNames(); len(names) != 2 || names[0] != "ns1.example.net" {
+		t.Errorf("Unexpected nameservers: %v", names)
+	}
+	if queries.Load() != 1 {
+		t.Errorf("Expected the converted response to be cached, got %d queries", queries.Load())
+	}
+
+	if _, err := client.RDAP("free.de"); !errors.Is(err, rdap.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueryIDN(t *testing.T) {
+	registry, _ := newWhoisServer(t, map[string]string{"xn--bcher-kva.de": denicResponse})
+	client := New().SetServer("de", registry)
+
+	record, err := client.Query(context.Background(), "Bücher.de")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if record.Domain != "xn--bcher-kva.de" || record.Raw != denicResponse {
+		t.Errorf("Expected the A-label to be queried, got %+v", record)
+	}
+	if domain := record.RDAP(); domain.UnicodeName != "bücher.de" {
+		t.Errorf("Expected the U-label in unicodeName, got %q", domain.UnicodeName)
+	}
+}